@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// APIVersion is the version prefix of every agent route
+const APIVersion = "v1"
+
+type api struct {
+	fw     Firewall
+	secret string
+	mu     sync.Mutex // serializes changes to the firewall
+}
+
+func newAPI(fw Firewall, secret string) *api {
+	return &api{fw: fw, secret: secret}
+}
+
+func (a *api) routes() http.Handler {
+	mux := http.NewServeMux()
+	prefix := "/" + APIVersion
+	mux.HandleFunc("GET "+prefix+"/health", a.health)
+	mux.HandleFunc("GET "+prefix+"/teams", a.listTeams)
+	mux.HandleFunc("GET "+prefix+"/teams/{id}/firewall", a.getTeamFirewall)
+	mux.HandleFunc("PUT "+prefix+"/teams/{id}/firewall", a.applyTeamFirewall)
+	mux.HandleFunc("POST "+prefix+"/teams/{id}/firewall/diff", a.diffTeamFirewall)
+	mux.HandleFunc("DELETE "+prefix+"/teams/{id}/firewall", a.removeTeamFirewall)
+	return a.authenticate(mux)
+}
+
+// authenticate checks the shared secret; with mTLS only, the TLS handshake already verified the client
+func (a *api) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.secret != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(a.secret)) != 1 {
+				writeError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func teamID(r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func decodePolicy(r *http.Request) (TeamPolicy, bool) {
+	var policy TeamPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		return policy, false
+	}
+	for _, port := range policy.Ports {
+		if port < 1 || port > 65535 {
+			return policy, false
+		}
+	}
+	return policy, true
+}
+
+func (a *api) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": APIVersion})
+}
+
+func (a *api) listTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := a.fw.Teams()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"teams": teams})
+}
+
+func (a *api) getTeamFirewall(w http.ResponseWriter, r *http.Request) {
+	id, ok := teamID(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_team_id")
+		return
+	}
+	rules, err := a.fw.Rules(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if rules == nil {
+		writeError(w, http.StatusNotFound, "team_chain_not_found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team_id": id, "rules": rules})
+}
+
+func (a *api) diffTeamFirewall(w http.ResponseWriter, r *http.Request) {
+	id, ok := teamID(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_team_id")
+		return
+	}
+	policy, ok := decodePolicy(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_policy")
+		return
+	}
+	current, err := a.fw.Rules(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, DiffRules(current, BuildRules(policy)))
+}
+
+// applyTeamFirewall replaces the team rules; applying the same policy twice changes nothing
+func (a *api) applyTeamFirewall(w http.ResponseWriter, r *http.Request) {
+	id, ok := teamID(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_team_id")
+		return
+	}
+	policy, ok := decodePolicy(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_policy")
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	current, err := a.fw.Rules(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	desired := BuildRules(policy)
+	diff := DiffRules(current, desired)
+	if current != nil && len(diff.Add) == 0 && len(diff.Remove) == 0 {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"team_id": id, "changed": false, "rules": desired})
+		return
+	}
+
+	if err := a.fw.Apply(id, desired); err != nil {
+		log.Printf("[AGENT] Firewall ERROR: %v", err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.Printf("Firewall rules applied for team %d on ports %v (+%d -%d)", id, policy.Ports, len(diff.Add), len(diff.Remove))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team_id": id, "changed": true, "rules": desired})
+}
+
+func (a *api) removeTeamFirewall(w http.ResponseWriter, r *http.Request) {
+	id, ok := teamID(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_team_id")
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.fw.Remove(id); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.Printf("Firewall rules removed for team %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func request(t *testing.T, handler http.Handler, method, path, secret, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if secret != "" {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIRequiresSecret(t *testing.T) {
+	handler := newAPI(&iptablesFirewall{ipt: newFakeIptables()}, "s3cret").routes()
+
+	if rec := request(t, handler, http.MethodGet, "/v1/teams", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without secret: status %d, want 401", rec.Code)
+	}
+	if rec := request(t, handler, http.MethodGet, "/v1/teams", "wrong", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: status %d, want 401", rec.Code)
+	}
+	if rec := request(t, handler, http.MethodGet, "/v1/teams", "s3cret", ""); rec.Code != http.StatusOK {
+		t.Errorf("valid secret: status %d, want 200", rec.Code)
+	}
+}
+
+func TestAPIApplyDiffRemove(t *testing.T) {
+	handler := newAPI(&iptablesFirewall{ipt: newFakeIptables()}, "s3cret").routes()
+	policy := `{"ports": [31337], "allowed_ips": ["10.0.0.1"]}`
+
+	var applied struct {
+		Changed bool   `json:"changed"`
+		Rules   []Rule `json:"rules"`
+	}
+	for i, wantChanged := range []bool{true, false} {
+		rec := request(t, handler, http.MethodPut, "/v1/teams/3/firewall", "s3cret", policy)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("apply %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+		json.NewDecoder(rec.Body).Decode(&applied)
+		if applied.Changed != wantChanged {
+			t.Errorf("apply %d: changed = %v, want %v", i, applied.Changed, wantChanged)
+		}
+	}
+	if len(applied.Rules) != 2 {
+		t.Errorf("got %d rules, want an accept and a drop", len(applied.Rules))
+	}
+
+	rec := request(t, handler, http.MethodPost, "/v1/teams/3/firewall/diff", "s3cret", `{"ports": [31338], "allowed_ips": ["10.0.0.1"]}`)
+	var diff Diff
+	json.NewDecoder(rec.Body).Decode(&diff)
+	if len(diff.Add) != 2 || len(diff.Remove) != 2 {
+		t.Errorf("diff = %+v, want the two rules of each port", diff)
+	}
+
+	if rec := request(t, handler, http.MethodDelete, "/v1/teams/3/firewall", "s3cret", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("remove: status %d, want 204", rec.Code)
+	}
+	if rec := request(t, handler, http.MethodGet, "/v1/teams/3/firewall", "s3cret", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get after remove: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	teamChainPrefix = "PTA-TEAM-"
+	parentChain     = "INPUT"
+)
+
+// Rule is a single filter rule of a team chain
+type Rule struct {
+	Source string `json:"source,omitempty"`
+	Port   int    `json:"port"`
+	Action string `json:"action"`
+}
+
+// String renders the rule as iptables arguments, also used as its identity in diffs
+func (r Rule) String() string {
+	return strings.Join(r.Args(), " ")
+}
+
+// Args returns the iptables arguments of the rule
+func (r Rule) Args() []string {
+	args := []string{}
+	if r.Source != "" {
+		args = append(args, "-s", r.Source)
+	}
+	return append(args, "-p", "tcp", "--dport", strconv.Itoa(r.Port), "-j", r.Action)
+}
+
+// TeamPolicy is the desired firewall state of a team
+type TeamPolicy struct {
+	Ports      []int    `json:"ports"`
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+// Diff lists the rules to add and remove to reach a policy
+type Diff struct {
+	Add    []Rule `json:"add"`
+	Remove []Rule `json:"remove"`
+}
+
+// Firewall manages the chains owned by the agent, one per team
+type Firewall interface {
+	// Apply replaces the rules of the team chain, creating it and its jump if needed
+	Apply(teamID uint, rules []Rule) error
+	// Rules returns the current rules of the team chain, nil if the chain does not exist
+	Rules(teamID uint) ([]Rule, error)
+	// Remove deletes the team chain and its jump
+	Remove(teamID uint) error
+	// Teams returns the teams having a chain
+	Teams() ([]uint, error)
+}
+
+// teamChain returns the chain owned by the agent for a team
+func teamChain(teamID uint) string {
+	return fmt.Sprintf("%s%d", teamChainPrefix, teamID)
+}
+
+// BuildRules returns the ordered rules enforcing a policy: allowed sources first, then a drop per port
+func BuildRules(policy TeamPolicy) []Rule {
+	ports := append([]int(nil), policy.Ports...)
+	sort.Ints(ports)
+	ips := append([]string(nil), policy.AllowedIPs...)
+	sort.Strings(ips)
+
+	var rules []Rule
+	seen := make(map[string]bool)
+	for _, port := range ports {
+		for _, ip := range ips {
+			r := Rule{Source: normalizeSource(ip), Port: port, Action: "ACCEPT"}
+			if !seen[r.String()] {
+				seen[r.String()] = true
+				rules = append(rules, r)
+			}
+		}
+		r := Rule{Port: port, Action: "DROP"}
+		if !seen[r.String()] {
+			seen[r.String()] = true
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// DiffRules compares the current rules with the desired ones
+func DiffRules(current, desired []Rule) Diff {
+	diff := Diff{Add: []Rule{}, Remove: []Rule{}}
+	have := make(map[string]bool, len(current))
+	for _, r := range current {
+		have[r.String()] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, r := range desired {
+		want[r.String()] = true
+		if !have[r.String()] {
+			diff.Add = append(diff.Add, r)
+		}
+	}
+	for _, r := range current {
+		if !want[r.String()] {
+			diff.Remove = append(diff.Remove, r)
+		}
+	}
+	return diff
+}
+
+// normalizeSource writes single addresses the way iptables lists them
+func normalizeSource(ip string) string {
+	if ip == "" || strings.Contains(ip, "/") {
+		return ip
+	}
+	if strings.Contains(ip, ":") {
+		return ip + "/128"
+	}
+	return ip + "/32"
+}
+
+// parseRule reads a rule from an iptables -S line, ignoring lines that are not port rules
+func parseRule(line string) (Rule, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "-A" {
+		return Rule{}, false
+	}
+
+	var r Rule
+	for i := 2; i < len(fields)-1; i++ {
+		switch fields[i] {
+		case "-s":
+			r.Source = fields[i+1]
+		case "--dport":
+			port, err := strconv.Atoi(fields[i+1])
+			if err != nil {
+				return Rule{}, false
+			}
+			r.Port = port
+		case "-j":
+			r.Action = fields[i+1]
+		}
+	}
+	if r.Port == 0 || r.Action == "" {
+		return Rule{}, false
+	}
+	return r, true
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-iptables/iptables"
+)
+
+// stagingSuffix names the chain where the new rules of a team are built before being swapped in
+const stagingSuffix = "-NEW"
+
+// iptablesRunner is the part of go-iptables used by the agent, faked in tests
+type iptablesRunner interface {
+	List(table, chain string) ([]string, error)
+	ListChains(table string) ([]string, error)
+	ChainExists(table, chain string) (bool, error)
+	ClearChain(table, chain string) error
+	ClearAndDeleteChain(table, chain string) error
+	RenameChain(table, oldChain, newChain string) error
+	Append(table, chain string, rulespec ...string) error
+	Insert(table, chain string, pos int, rulespec ...string) error
+	Replace(table, chain string, pos int, rulespec ...string) error
+	DeleteById(table, chain string, id int) error
+}
+
+// iptablesFirewall is the Firewall backed by the host iptables
+type iptablesFirewall struct {
+	ipt iptablesRunner
+}
+
+func newIptablesFirewall() (*iptablesFirewall, error) {
+	ipt, err := iptables.New()
+	if err != nil {
+		return nil, err
+	}
+	return &iptablesFirewall{ipt: ipt}, nil
+}
+
+// jumpRule sends the traffic to a chain of the team; the comment marks it as owned by the agent
+func jumpRule(teamID uint, chain string) []string {
+	return []string{"-m", "comment", "--comment", "pta-agent team " + strconv.FormatUint(uint64(teamID), 10), "-j", chain}
+}
+
+// jumpPosition returns the position of the first parent chain rule jumping to the team chain or its staging
+// chain, 0 when there is none
+func (f *iptablesFirewall) jumpPosition(teamID uint) (int, error) {
+	chain := teamChain(teamID)
+	lines, err := f.ipt.List("filter", parentChain)
+	if err != nil {
+		return 0, err
+	}
+
+	pos := 0
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "-A" {
+			continue
+		}
+		pos++
+		for i := 2; i < len(fields)-1; i++ {
+			if fields[i] == "-j" && (fields[i+1] == chain || fields[i+1] == chain+stagingSuffix) {
+				return pos, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// Apply builds the rules in a staging chain, points the team jump to it in a single rule replacement,
+// then renames it over the old chain, so packets never see a partially written chain
+func (f *iptablesFirewall) Apply(teamID uint, rules []Rule) error {
+	chain := teamChain(teamID)
+	staging := chain + stagingSuffix
+
+	// ClearChain creates the chain when missing and flushes the leftovers of an interrupted apply otherwise
+	if err := f.ipt.ClearChain("filter", staging); err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if err := f.ipt.Append("filter", staging, r.Args()...); err != nil {
+			return err
+		}
+	}
+
+	pos, err := f.jumpPosition(teamID)
+	if err != nil {
+		return err
+	}
+	if pos == 0 {
+		err = f.ipt.Insert("filter", parentChain, 1, jumpRule(teamID, staging)...)
+	} else {
+		err = f.ipt.Replace("filter", parentChain, pos, jumpRule(teamID, staging)...)
+	}
+	if err != nil {
+		return err
+	}
+
+	exists, err := f.ipt.ChainExists("filter", chain)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := f.ipt.ClearAndDeleteChain("filter", chain); err != nil {
+			return err
+		}
+	}
+	// Renaming keeps the jump pointing to the chain
+	return f.ipt.RenameChain("filter", staging, chain)
+}
+
+func (f *iptablesFirewall) Rules(teamID uint) ([]Rule, error) {
+	chain := teamChain(teamID)
+	exists, err := f.ipt.ChainExists("filter", chain)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	lines, err := f.ipt.List("filter", chain)
+	if err != nil {
+		return nil, err
+	}
+	rules := []Rule{}
+	for _, line := range lines {
+		if r, ok := parseRule(line); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+func (f *iptablesFirewall) Remove(teamID uint) error {
+	for {
+		pos, err := f.jumpPosition(teamID)
+		if err != nil {
+			return err
+		}
+		if pos == 0 {
+			break
+		}
+		if err := f.ipt.DeleteById("filter", parentChain, pos); err != nil {
+			return err
+		}
+	}
+
+	chain := teamChain(teamID)
+	for _, name := range []string{chain, chain + stagingSuffix} {
+		exists, err := f.ipt.ChainExists("filter", name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err := f.ipt.ClearAndDeleteChain("filter", name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *iptablesFirewall) Teams() ([]uint, error) {
+	chains, err := f.ipt.ListChains("filter")
+	if err != nil {
+		return nil, err
+	}
+	var teams []uint
+	for _, chain := range chains {
+		if !strings.HasPrefix(chain, teamChainPrefix) {
+			continue
+		}
+		// Staging chains do not parse as a team ID
+		id, err := strconv.ParseUint(strings.TrimPrefix(chain, teamChainPrefix), 10, 32)
+		if err == nil {
+			teams = append(teams, uint(id))
+		}
+	}
+	return teams, nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fakeIptables keeps the filter table in memory and enforces the iptables checks the agent relies on
+type fakeIptables struct {
+	chains map[string][]string
+}
+
+func newFakeIptables() *fakeIptables {
+	return &fakeIptables{chains: map[string][]string{parentChain: {}}}
+}
+
+func (f *fakeIptables) chain(name string) ([]string, error) {
+	rules, ok := f.chains[name]
+	if !ok {
+		return nil, fmt.Errorf("chain %s does not exist", name)
+	}
+	return rules, nil
+}
+
+func (f *fakeIptables) referenced(name string) bool {
+	for _, rules := range f.chains {
+		for _, rule := range rules {
+			if strings.HasSuffix(rule, "-j "+name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *fakeIptables) List(table, chain string) ([]string, error) {
+	rules, err := f.chain(chain)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{"-N " + chain}
+	if chain == parentChain {
+		lines = []string{"-P INPUT ACCEPT"}
+	}
+	for _, rule := range rules {
+		lines = append(lines, "-A "+chain+" "+rule)
+	}
+	return lines, nil
+}
+
+func (f *fakeIptables) ListChains(table string) ([]string, error) {
+	var names []string
+	for name := range f.chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *fakeIptables) ChainExists(table, chain string) (bool, error) {
+	_, ok := f.chains[chain]
+	return ok, nil
+}
+
+func (f *fakeIptables) ClearChain(table, chain string) error {
+	f.chains[chain] = []string{}
+	return nil
+}
+
+func (f *fakeIptables) ClearAndDeleteChain(table, chain string) error {
+	if _, err := f.chain(chain); err != nil {
+		return err
+	}
+	if f.referenced(chain) {
+		return fmt.Errorf("chain %s is still referenced", chain)
+	}
+	delete(f.chains, chain)
+	return nil
+}
+
+func (f *fakeIptables) RenameChain(table, oldChain, newChain string) error {
+	rules, err := f.chain(oldChain)
+	if err != nil {
+		return err
+	}
+	if _, exists := f.chains[newChain]; exists {
+		return fmt.Errorf("chain %s already exists", newChain)
+	}
+	delete(f.chains, oldChain)
+	f.chains[newChain] = rules
+	// The kernel keeps jumps pointing to a renamed chain
+	for name, chainRules := range f.chains {
+		for i, rule := range chainRules {
+			if strings.HasSuffix(rule, "-j "+oldChain) {
+				f.chains[name][i] = strings.TrimSuffix(rule, oldChain) + newChain
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeIptables) Append(table, chain string, rulespec ...string) error {
+	rules, err := f.chain(chain)
+	if err != nil {
+		return err
+	}
+	f.chains[chain] = append(rules, strings.Join(rulespec, " "))
+	return nil
+}
+
+func (f *fakeIptables) Insert(table, chain string, pos int, rulespec ...string) error {
+	rules, err := f.chain(chain)
+	if err != nil {
+		return err
+	}
+	if pos < 1 || pos > len(rules)+1 {
+		return fmt.Errorf("index of insertion too big")
+	}
+	rules = append(rules[:pos-1], append([]string{strings.Join(rulespec, " ")}, rules[pos-1:]...)...)
+	f.chains[chain] = rules
+	return nil
+}
+
+func (f *fakeIptables) Replace(table, chain string, pos int, rulespec ...string) error {
+	rules, err := f.chain(chain)
+	if err != nil {
+		return err
+	}
+	if pos < 1 || pos > len(rules) {
+		return fmt.Errorf("index of replacement too big")
+	}
+	rules[pos-1] = strings.Join(rulespec, " ")
+	return nil
+}
+
+func (f *fakeIptables) DeleteById(table, chain string, id int) error {
+	rules, err := f.chain(chain)
+	if err != nil {
+		return err
+	}
+	if id < 1 || id > len(rules) {
+		return fmt.Errorf("index of deletion too big")
+	}
+	f.chains[chain] = append(rules[:id-1], rules[id:]...)
+	return nil
+}
+
+// jumpsTo returns the positions of the parent chain rules jumping to a chain
+func (f *fakeIptables) jumpsTo(chain string) []int {
+	var positions []int
+	for i, rule := range f.chains[parentChain] {
+		if strings.HasSuffix(rule, "-j "+chain) {
+			positions = append(positions, i+1)
+		}
+	}
+	return positions
+}
+
+func TestApplyCreatesChainAndJump(t *testing.T) {
+	ipt := newFakeIptables()
+	ipt.chains[parentChain] = []string{"-i lo -j ACCEPT"}
+	fw := &iptablesFirewall{ipt: ipt}
+
+	rules := BuildRules(TeamPolicy{Ports: []int{31337}, AllowedIPs: []string{"10.0.0.1"}})
+	if err := fw.Apply(3, rules); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	got, err := fw.Rules(3)
+	if err != nil {
+		t.Fatalf("Rules: %v", err)
+	}
+	if !reflect.DeepEqual(got, rules) {
+		t.Errorf("rules = %v, want %v", got, rules)
+	}
+	if jumps := ipt.jumpsTo("PTA-TEAM-3"); !reflect.DeepEqual(jumps, []int{1}) {
+		t.Errorf("jumps at %v, want a single one at position 1", jumps)
+	}
+	if _, ok := ipt.chains["PTA-TEAM-3"+stagingSuffix]; ok {
+		t.Error("staging chain left behind")
+	}
+}
+
+func TestApplyReplacesRules(t *testing.T) {
+	ipt := newFakeIptables()
+	fw := &iptablesFirewall{ipt: ipt}
+	ips := []string{"10.0.0.1"}
+
+	if err := fw.Apply(3, BuildRules(TeamPolicy{Ports: []int{31337, 31338}, AllowedIPs: ips})); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if err := fw.Apply(4, BuildRules(TeamPolicy{Ports: []int{31400}, AllowedIPs: ips})); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	teamPosition := ipt.jumpsTo("PTA-TEAM-3")
+
+	// Port 31337 was freed: its drop rule must go so another team can use it
+	want := BuildRules(TeamPolicy{Ports: []int{31338}, AllowedIPs: ips})
+	if err := fw.Apply(3, want); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	got, err := fw.Rules(3)
+	if err != nil {
+		t.Fatalf("Rules: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rules = %v, want %v", got, want)
+	}
+	if jumps := ipt.jumpsTo("PTA-TEAM-3"); !reflect.DeepEqual(jumps, teamPosition) {
+		t.Errorf("jumps at %v, want them unchanged at %v", jumps, teamPosition)
+	}
+	if len(ipt.chains[parentChain]) != 2 {
+		t.Errorf("parent chain has %d rules, want one jump per team", len(ipt.chains[parentChain]))
+	}
+}
+
+func TestApplyRecoversFromStagingChain(t *testing.T) {
+	ipt := newFakeIptables()
+	fw := &iptablesFirewall{ipt: ipt}
+
+	// An apply interrupted after the jump replacement leaves the jump on the staging chain
+	ipt.chains["PTA-TEAM-3"+stagingSuffix] = []string{"-p tcp --dport 1 -j DROP"}
+	ipt.chains[parentChain] = []string{strings.Join(jumpRule(3, "PTA-TEAM-3"+stagingSuffix), " ")}
+
+	want := BuildRules(TeamPolicy{Ports: []int{31337}})
+	if err := fw.Apply(3, want); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	got, _ := fw.Rules(3)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rules = %v, want %v", got, want)
+	}
+	if jumps := ipt.jumpsTo("PTA-TEAM-3"); !reflect.DeepEqual(jumps, []int{1}) {
+		t.Errorf("jumps at %v, want a single one at position 1", jumps)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	ipt := newFakeIptables()
+	fw := &iptablesFirewall{ipt: ipt}
+
+	for _, team := range []uint{3, 4} {
+		if err := fw.Apply(team, BuildRules(TeamPolicy{Ports: []int{31337}})); err != nil {
+			t.Fatalf("Apply: %v", err)
+		}
+	}
+	if err := fw.Remove(3); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	if rules, err := fw.Rules(3); err != nil || rules != nil {
+		t.Errorf("Rules after Remove = %v, %v, want no chain", rules, err)
+	}
+	if jumps := ipt.jumpsTo("PTA-TEAM-3"); len(jumps) != 0 {
+		t.Errorf("jumps left at %v", jumps)
+	}
+	teams, err := fw.Teams()
+	if err != nil {
+		t.Fatalf("Teams: %v", err)
+	}
+	if !reflect.DeepEqual(teams, []uint{4}) {
+		t.Errorf("teams = %v, want [4]", teams)
+	}
+
+	// Removing a team without a chain is a no-op
+	if err := fw.Remove(3); err != nil {
+		t.Errorf("second Remove: %v", err)
+	}
+}
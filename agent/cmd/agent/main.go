@@ -1,86 +1,84 @@
 package main
 
 import (
-	"encoding/json"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
-
-	"github.com/coreos/go-iptables/iptables"
 )
 
-type FirewallRequest struct {
-	TeamID     uint     `json:"team_id"`
-	Ports      []int    `json:"ports"`
-	AllowedIPs []string `json:"allowed_ips"`
-}
+const defaultListenAddr = ":8383"
+
+// exampleSecret is the PTA_AGENT_SECRET of .env.example, which anyone can read
+const exampleSecret = "changeme_agent_secret"
 
 func main() {
-	http.HandleFunc("/team/firewall", teamFirewallHandler)
-	go func() {
-		log.Println("[pta-agent] Listening on :8383")
-		if err := http.ListenAndServe(":8383", nil); err != nil {
-			log.Fatalf("FIREWALL AGENT FATAL: %v", err)
-		}
-	}()
-	waitForSig()
-}
+	fw, err := newIptablesFirewall()
+	if err != nil {
+		log.Fatalf("FIREWALL AGENT FATAL: %v", err)
+	}
 
-func teamFirewallHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
-		return
+	secret := os.Getenv("PTA_AGENT_SECRET")
+	if secret == exampleSecret {
+		log.Fatalf("FIREWALL AGENT FATAL: PTA_AGENT_SECRET is still the example value, set a random secret")
 	}
-	var req FirewallRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Bad JSON", 400)
-		return
+	tlsConfig, err := loadServerTLS()
+	if err != nil {
+		log.Fatalf("FIREWALL AGENT FATAL: %v", err)
 	}
-	log.Printf("Team %d: protect ports %v for IPs: %v", req.TeamID, req.Ports, req.AllowedIPs)
-	if err := ApplyTeamFirewall(req.TeamID, req.Ports, req.AllowedIPs); err != nil {
-		log.Printf("[AGENT] Firewall ERROR: %v", err)
-		http.Error(w, err.Error(), 500)
-		return
+	if secret == "" && (tlsConfig == nil || tlsConfig.ClientCAs == nil) {
+		log.Fatalf("FIREWALL AGENT FATAL: set PTA_AGENT_SECRET or PTA_AGENT_TLS_CA to authenticate the backend")
 	}
-	w.WriteHeader(200)
-	w.Write([]byte("ok\n"))
-}
 
-func ApplyTeamFirewall(teamID uint, ports []int, allowedIPs []string) error {
-	ipt, err := iptables.New()
-	if err != nil {
-		return err
+	addr := os.Getenv("PTA_AGENT_LISTEN")
+	if addr == "" {
+		addr = defaultListenAddr
 	}
-	chain := "INPUT"
-	rules, err := ipt.List("filter", chain)
-	if err != nil {
-		return err
+
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   newAPI(fw, secret).routes(),
+		TLSConfig: tlsConfig,
 	}
 
-	for _, rule := range rules {
-		if rule == "" {
-			continue
+	go func() {
+		log.Printf("[pta-agent] Listening on %s", addr)
+		if tlsConfig != nil {
+			err = server.ListenAndServeTLS(os.Getenv("PTA_AGENT_TLS_CERT"), os.Getenv("PTA_AGENT_TLS_KEY"))
+		} else {
+			err = server.ListenAndServe()
 		}
-		for _, port := range ports {
-			if strings.Contains(rule, fmt.Sprintf("--dport %d", port)) {
-				args := strings.Fields(rule)[2:]
-				_ = ipt.Delete("filter", chain, args...)
-			}
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatalf("FIREWALL AGENT FATAL: %v", err)
 		}
+	}()
+	waitForSig()
+}
+
+// loadServerTLS enables TLS when PTA_AGENT_TLS_CERT is set, and client certificate checks (mTLS) when PTA_AGENT_TLS_CA is set
+func loadServerTLS() (*tls.Config, error) {
+	if os.Getenv("PTA_AGENT_TLS_CERT") == "" {
+		return nil, nil
 	}
-	for _, port := range ports {
-		for _, ip := range allowedIPs {
-			rule := []string{"-s", ip, "-p", "tcp", "--dport", fmt.Sprintf("%d", port), "-j", "ACCEPT"}
-			_ = ipt.AppendUnique("filter", chain, rule...)
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile := os.Getenv("PTA_AGENT_TLS_CA"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("invalid client CA %s", caFile)
 		}
-		_ = ipt.AppendUnique("filter", chain, []string{"-p", "tcp", "--dport", fmt.Sprintf("%d", port), "-j", "DROP"}...)
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
 	}
-	log.Printf("Firewall rules applied for team %d on ports %v", teamID, ports)
-	return nil
+	return cfg, nil
 }
 
 func waitForSig() {
@@ -19,14 +19,14 @@ func ConnectDB() *gorm.DB {
 	}
 
 	err = db.AutoMigrate(
-		&models.Config{}, &models.DockerConfig{},
-		&models.Team{}, &models.Solve{},
+		&models.Config{}, &models.DockerConfig{}, &models.Worker{},
+		&models.Bracket{}, &models.Team{}, &models.Solve{},
 		&models.User{}, &models.ChallengeCategory{},
 		&models.ChallengeType{}, &models.ChallengeDifficulty{},
 		&models.DecayFormula{}, &models.Challenge{}, &models.Flag{},
 		&models.Hint{}, &models.HintPurchase{}, &models.FirstBlood{},
-		&models.Submission{}, &models.Instance{}, &models.InstanceCooldown{}, &models.DynamicFlag{}, &models.GeoSpec{},
-		&models.Notification{},
+		&models.Submission{}, &models.Instance{}, &models.InstanceCooldown{}, &models.DynamicFlag{}, &models.GeoSpec{}, &models.VMSpec{},
+		&models.Notification{}, &models.SuspiciousActivity{}, &models.SubnetLease{}, &models.ScoreboardEntry{}, &models.Award{}, &models.APIToken{}, &models.RecoveryCode{}, &models.Session{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
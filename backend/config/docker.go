@@ -2,6 +2,8 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"log"
@@ -32,52 +34,81 @@ func ConnectDocker() error {
 		return errors.New("DockerConfig.Host is empty in DB")
 	}
 
+	cl, err := NewDockerClient(dockerCfg.Host, nil)
+	if err != nil {
+		return err
+	}
+
+	ver, err := cl.ServerVersion(context.Background())
+	if err != nil {
+		log.Println("Unable to connect to docker daemon:", err)
+		return fmt.Errorf("unable to connect to docker daemon: %s", err.Error())
+	}
+	log.Printf("Connected to %s | Docker Version: %s", dockerCfg.Host, ver.Version)
+
+	DockerClient = cl
+	return nil
+}
+
+// DockerTLS holds the PEM encoded material used to reach a TLS protected docker daemon
+type DockerTLS struct {
+	CACert string
+	Cert   string
+	Key    string
+}
+
+// NewDockerClient creates a docker client for a unix socket, ssh or tcp host
+func NewDockerClient(host string, tlsMaterial *DockerTLS) (*client.Client, error) {
 	var cl *client.Client
 	var err error
 
+	if tlsMaterial != nil && !strings.HasPrefix(host, "ssh://") && !strings.HasPrefix(host, "/") && !strings.HasPrefix(host, "unix://") {
+		return newTLSDockerClient(host, tlsMaterial)
+	}
+
 	// Handle Unix socket directly (local Docker daemon)
-	if dockerCfg.Host == "/var/run/docker.sock" || strings.HasPrefix(dockerCfg.Host, "unix://") || strings.HasPrefix(dockerCfg.Host, "/") {
-		log.Printf("DEBUG: Using local Unix socket: %s", dockerCfg.Host)
+	if host == "/var/run/docker.sock" || strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "/") {
+		log.Printf("DEBUG: Using local Unix socket: %s", host)
 
 		// For Unix sockets, use a simple client configuration
 		clientOpts := []client.Opt{
-			client.WithHost("unix://" + strings.TrimPrefix(dockerCfg.Host, "unix://")),
+			client.WithHost("unix://" + strings.TrimPrefix(host, "unix://")),
 			client.WithAPIVersionNegotiation(),
 		}
 
 		cl, err = client.NewClientWithOpts(clientOpts...)
 		if err != nil {
 			log.Println("Unable to create docker client for Unix socket:", err)
-			return fmt.Errorf("unable to create docker client for Unix socket: %w", err)
+			return nil, fmt.Errorf("unable to create docker client for Unix socket: %w", err)
 		}
 	} else {
 		// Handle remote Docker daemon (SSH, TCP, etc.)
-		log.Printf("DEBUG: Using remote Docker daemon: %s", dockerCfg.Host)
+		log.Printf("DEBUG: Using remote Docker daemon: %s", host)
 		var helper *connhelper.ConnectionHelper
-		if strings.HasPrefix(dockerCfg.Host, "ssh://") {
+		if strings.HasPrefix(host, "ssh://") {
 			sshOpts := []string{
 				"-o", "StrictHostKeyChecking=no",
 			}
 
-			helper, err = connhelper.GetConnectionHelperWithSSHOpts(dockerCfg.Host, sshOpts)
+			helper, err = connhelper.GetConnectionHelperWithSSHOpts(host, sshOpts)
 			if err != nil {
 				log.Println("Failed to create connection helper:", err)
-				return err
+				return nil, err
 			}
 			if helper == nil {
 				log.Println("Unable to create connection helper (nil)")
-				return errors.New("unable to create connection helper")
+				return nil, errors.New("unable to create connection helper")
 			}
 
 		} else {
-			helper, err = connhelper.GetConnectionHelper(dockerCfg.Host)
+			helper, err = connhelper.GetConnectionHelper(host)
 			if err != nil {
 				log.Println("Failed to create connection helper:", err)
-				return err
+				return nil, err
 			}
 			if helper == nil {
 				log.Println("Unable to create connection helper (nil)")
-				return errors.New("unable to create connection helper")
+				return nil, errors.New("unable to create connection helper")
 			}
 		}
 
@@ -97,22 +128,48 @@ func ConnectDocker() error {
 		cl, err = client.NewClientWithOpts(clientOpts...)
 		if err != nil {
 			log.Println("Unable to create docker client:", err)
-			return errors.New("unable to create docker client")
+			return nil, errors.New("unable to create docker client")
 		}
 	}
 
 	if cl == nil {
 		log.Println("Unable to create docker client")
-		return errors.New("unable to create docker client")
+		return nil, errors.New("unable to create docker client")
 	}
+	return cl, nil
 
-	ver, err := cl.ServerVersion(context.Background())
-	if err != nil {
-		log.Println("Unable to connect to docker daemon:", err)
-		return fmt.Errorf("unable to connect to docker daemon: %s", err.Error())
+}
+
+// newTLSDockerClient creates a docker client for a tcp host secured with TLS
+func newTLSDockerClient(host string, tlsMaterial *DockerTLS) (*client.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if tlsMaterial.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(tlsMaterial.CACert)) {
+			return nil, errors.New("invalid docker TLS CA certificate")
+		}
+		tlsConfig.RootCAs = pool
 	}
-	log.Printf("Connected to %s | Docker Version: %s", dockerCfg.Host, ver.Version)
 
-	DockerClient = cl
-	return nil
+	if tlsMaterial.Cert != "" && tlsMaterial.Key != "" {
+		cert, err := tls.X509KeyPair([]byte(tlsMaterial.Cert), []byte(tlsMaterial.Key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid docker TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	return client.NewClientWithOpts(
+		client.WithHTTPClient(httpClient),
+		client.WithHost(strings.Replace(host, "https://", "tcp://", 1)),
+		client.WithScheme("https"),
+		client.WithAPIVersionNegotiation(),
+	)
 }
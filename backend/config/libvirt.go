@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultLibvirtURI = "qemu+ssh://libvirt-user@libvirt-worker/system"
+
+// LibvirtConnection is the subset of libvirt operations used to run VM instances
+type LibvirtConnection interface {
+	Version() (string, error)
+	VolumeExists(pool, name string) bool
+	UploadVolume(pool, name, path string) error
+	CreateOverlayVolume(pool, name, backing string) (string, error)
+	DeleteVolume(pool, name string) error
+	CreateDomain(domainXML string) error
+	DestroyDomain(name string) error
+	DomainState(name string) (string, error)
+}
+
+var (
+	Libvirt   LibvirtConnection
+	libvirtMu sync.Mutex
+)
+
+// ErrDomainNotFound is returned by DomainState when the domain does not exist (transient domains vanish once shut off)
+var ErrDomainNotFound = errors.New("domain not found")
+
+// LibvirtURI returns the libvirt connection URI from PTA_LIBVIRT_URL
+func LibvirtURI() string {
+	if uri := os.Getenv("PTA_LIBVIRT_URL"); uri != "" {
+		return uri
+	}
+	return defaultLibvirtURI
+}
+
+// ConnectLibvirt checks the libvirt daemon is reachable and sets the global connection
+func ConnectLibvirt() error {
+	libvirtMu.Lock()
+	defer libvirtMu.Unlock()
+
+	conn := &virshConnection{uri: LibvirtURI()}
+	ver, err := conn.Version()
+	if err != nil {
+		log.Println("Unable to connect to libvirt daemon:", err)
+		return fmt.Errorf("unable to connect to libvirt daemon: %s", err.Error())
+	}
+	log.Printf("Connected to %s | %s", conn.uri, ver)
+
+	Libvirt = conn
+	return nil
+}
+
+// SetLibvirtConnection replaces the global libvirt connection, e.g. with a fake one
+func SetLibvirtConnection(conn LibvirtConnection) {
+	libvirtMu.Lock()
+	defer libvirtMu.Unlock()
+	Libvirt = conn
+}
+
+// virshConnection talks to libvirt through the virsh client
+type virshConnection struct {
+	uri string
+}
+
+func (v *virshConnection) run(args ...string) (string, error) {
+	cmd := exec.Command("virsh", append([]string{"-q", "-c", v.uri}, args...)...)
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output != "" {
+			return "", fmt.Errorf("virsh %s: %s", args[0], output)
+		}
+		return "", fmt.Errorf("virsh %s: %w", args[0], err)
+	}
+	return output, nil
+}
+
+func (v *virshConnection) Version() (string, error) {
+	return v.run("version", "--daemon")
+}
+
+func (v *virshConnection) VolumeExists(pool, name string) bool {
+	_, err := v.run("vol-info", "--pool", pool, name)
+	return err == nil
+}
+
+func (v *virshConnection) UploadVolume(pool, name, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if _, err := v.run("vol-create-as", pool, name, strconv.FormatInt(info.Size(), 10), "--format", "qcow2"); err != nil {
+		return err
+	}
+	if _, err := v.run("vol-upload", "--pool", pool, name, path); err != nil {
+		v.DeleteVolume(pool, name)
+		return err
+	}
+	return nil
+}
+
+// volumeCapacity returns the virtual size of a volume in bytes
+func (v *virshConnection) volumeCapacity(pool, name string) (string, error) {
+	out, err := v.run("vol-info", "--pool", pool, name, "--bytes")
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "Capacity:" {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("capacity of volume %s not found", name)
+}
+
+func (v *virshConnection) CreateOverlayVolume(pool, name, backing string) (string, error) {
+	capacity, err := v.volumeCapacity(pool, backing)
+	if err != nil {
+		return "", err
+	}
+	if _, err := v.run("vol-create-as", pool, name, capacity, "--format", "qcow2",
+		"--backing-vol", backing, "--backing-vol-format", "qcow2"); err != nil {
+		return "", err
+	}
+	return v.run("vol-path", "--pool", pool, name)
+}
+
+func (v *virshConnection) DeleteVolume(pool, name string) error {
+	_, err := v.run("vol-delete", "--pool", pool, name)
+	return err
+}
+
+func (v *virshConnection) CreateDomain(domainXML string) error {
+	f, err := os.CreateTemp("", "pta-domain-*.xml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(domainXML); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	_, err = v.run("create", f.Name())
+	return err
+}
+
+func (v *virshConnection) DestroyDomain(name string) error {
+	_, err := v.run("destroy", name)
+	return err
+}
+
+func (v *virshConnection) DomainState(name string) (string, error) {
+	state, err := v.run("domstate", name)
+	if err != nil && strings.Contains(err.Error(), "failed to get domain") {
+		return "", ErrDomainNotFound
+	}
+	return state, err
+}
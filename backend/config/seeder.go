@@ -124,12 +124,71 @@ func IsCTFStarted() bool {
 	return status == CTFActive || status == CTFEnded || status == CTFNoTiming
 }
 
+// GetCTFStartTime returns the configured start of the CTF, false when it is not set
+func GetCTFStartTime() (time.Time, bool) {
+	value := GetConfigValue("CTF_START_TIME", "")
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	startTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Printf("Failed to parse CTF start time: %v", err)
+		return time.Time{}, false
+	}
+	return startTime, true
+}
+
+// GetScoreboardFreezeTime returns the freeze time when the public scoreboard is currently frozen
+func GetScoreboardFreezeTime() (time.Time, bool) {
+	value := GetConfigValue("SCOREBOARD_FREEZE_TIME", "")
+	if value == "" || GetConfigBool("SCOREBOARD_REVEALED", false) {
+		return time.Time{}, false
+	}
+
+	freezeTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Printf("Failed to parse scoreboard freeze time: %v", err)
+		return time.Time{}, false
+	}
+	if time.Now().Before(freezeTime) {
+		return time.Time{}, false
+	}
+	return freezeTime, true
+}
+
 func seedConfig() {
 	config := []models.Config{
 		{Key: "SITE_NAME", Value: os.Getenv("PTA_SITE_NAME"), Public: true},
 		{Key: "REGISTRATION_ENABLED", Value: getEnvWithDefault("PTA_REGISTRATION_ENABLED", "false"), Public: true},
 		{Key: "CTF_START_TIME", Value: getEnvWithDefault("PTA_CTF_START_TIME", ""), Public: true},
 		{Key: "CTF_END_TIME", Value: getEnvWithDefault("PTA_CTF_END_TIME", ""), Public: true},
+		{Key: "SCOREBOARD_FREEZE_TIME", Value: getEnvWithDefault("PTA_SCOREBOARD_FREEZE_TIME", ""), Public: true},
+		{Key: "SCOREBOARD_REVEALED", Value: "false", Public: true},
+		{Key: "FIRST_BLOOD_PER_BRACKET", Value: getEnvWithDefault("PTA_FIRST_BLOOD_PER_BRACKET", "false"), Public: true},
+		{Key: "FLAG_SHARING_AUTO_BAN", Value: getEnvWithDefault("PTA_FLAG_SHARING_AUTO_BAN", "false"), Public: false},
+		{Key: "RATE_LIMIT_SUBMIT", Value: getEnvWithDefault("PTA_RATE_LIMIT_SUBMIT", "10/1m"), Public: false},
+		{Key: "RATE_LIMIT_JOIN_TEAM", Value: getEnvWithDefault("PTA_RATE_LIMIT_JOIN_TEAM", "5/1m"), Public: false},
+		{Key: "RATE_LIMIT_ALGORITHM", Value: getEnvWithDefault("PTA_RATE_LIMIT_ALGORITHM", "sliding_window"), Public: false},
+		{Key: "RATE_LIMIT_MAIL", Value: getEnvWithDefault("PTA_RATE_LIMIT_MAIL", "3/10m"), Public: false},
+		{Key: "LOCAL_LOGIN_ENABLED", Value: getEnvWithDefault("PTA_LOCAL_LOGIN_ENABLED", "true"), Public: true},
+		{Key: "OIDC_ENABLED", Value: getEnvWithDefault("PTA_OIDC_ENABLED", "false"), Public: true},
+		{Key: "OIDC_PROVIDER_NAME", Value: getEnvWithDefault("PTA_OIDC_PROVIDER_NAME", "SSO"), Public: true},
+		{Key: "OIDC_ISSUER", Value: getEnvWithDefault("PTA_OIDC_ISSUER", ""), Public: false},
+		{Key: "OIDC_CLIENT_ID", Value: getEnvWithDefault("PTA_OIDC_CLIENT_ID", ""), Public: false},
+		{Key: "OIDC_REDIRECT_URL", Value: getEnvWithDefault("PTA_OIDC_REDIRECT_URL", ""), Public: false},
+		{Key: "OIDC_SCOPES", Value: getEnvWithDefault("PTA_OIDC_SCOPES", "openid profile email"), Public: false},
+		{Key: "OIDC_ADMIN_CLAIM", Value: getEnvWithDefault("PTA_OIDC_ADMIN_CLAIM", ""), Public: false},
+		{Key: "OIDC_ADMIN_VALUES", Value: getEnvWithDefault("PTA_OIDC_ADMIN_VALUES", ""), Public: false},
+		{Key: "PUBLIC_URL", Value: getEnvWithDefault("PTA_PUBLIC_URL", ""), Public: false},
+		{Key: "SMTP_HOST", Value: getEnvWithDefault("PTA_SMTP_HOST", ""), Public: false},
+		{Key: "SMTP_PORT", Value: getEnvWithDefault("PTA_SMTP_PORT", "587"), Public: false},
+		{Key: "SMTP_TLS", Value: getEnvWithDefault("PTA_SMTP_TLS", "starttls"), Public: false},
+		{Key: "SMTP_USERNAME", Value: getEnvWithDefault("PTA_SMTP_USERNAME", ""), Public: false},
+		{Key: "SMTP_FROM", Value: getEnvWithDefault("PTA_SMTP_FROM", ""), Public: false},
+		{Key: "REQUIRE_EMAIL_VERIFICATION", Value: getEnvWithDefault("PTA_REQUIRE_EMAIL_VERIFICATION", "false"), Public: false},
+		{Key: "REQUIRE_ADMIN_2FA", Value: getEnvWithDefault("PTA_REQUIRE_ADMIN_2FA", "false"), Public: false},
+		{Key: "RATE_LIMIT_2FA", Value: getEnvWithDefault("PTA_RATE_LIMIT_2FA", "5/1m"), Public: false},
 	}
 
 	for _, item := range config {
@@ -188,15 +247,32 @@ func seedDockerConfig() {
 		cooldownSeconds = 0 // Disabled by default
 	}
 
+	// Lifetime extensions: step in minutes, max count and total lifetime cap (0 = disabled/unlimited)
+	extensionMinutes, err := strconv.Atoi(getEnvWithDefault("PTA_DOCKER_INSTANCE_EXTENSION_MINUTES", "0"))
+	if err != nil {
+		extensionMinutes = 0
+	}
+	maxExtensions, err := strconv.Atoi(getEnvWithDefault("PTA_DOCKER_INSTANCE_MAX_EXTENSIONS", "0"))
+	if err != nil {
+		maxExtensions = 0
+	}
+	maxLifetime, err := strconv.Atoi(getEnvWithDefault("PTA_DOCKER_INSTANCE_MAX_LIFETIME", "0"))
+	if err != nil {
+		maxLifetime = 0
+	}
+
 	config := models.DockerConfig{
-		Host:                    os.Getenv("PTA_DOCKER_WORKER_URL"),
-		ImagePrefix:             os.Getenv("PTA_DOCKER_IMAGE_PREFIX"),
-		MaxMemByInstance:        maxMem,
-		MaxCpuByInstance:        maxCpu,
-		InstancesByTeam:         iByTeam,
-		InstancesByUser:         iByUser,
-		InstanceTimeout:         instanceTimeout,
-		InstanceCooldownSeconds: cooldownSeconds,
+		Host:                     os.Getenv("PTA_DOCKER_WORKER_URL"),
+		ImagePrefix:              os.Getenv("PTA_DOCKER_IMAGE_PREFIX"),
+		MaxMemByInstance:         maxMem,
+		MaxCpuByInstance:         maxCpu,
+		InstancesByTeam:          iByTeam,
+		InstancesByUser:          iByUser,
+		InstanceTimeout:          instanceTimeout,
+		InstanceCooldownSeconds:  cooldownSeconds,
+		InstanceExtensionMinutes: extensionMinutes,
+		InstanceMaxExtensions:    maxExtensions,
+		InstanceMaxLifetime:      maxLifetime,
 	}
 
 	if err := DB.Create(&config).Error; err != nil {
@@ -234,6 +310,7 @@ func seedChallengeType() {
 		{Name: "docker"},
 		{Name: "compose"},
 		{Name: "geo"},
+		{Name: "vm"},
 	}
 	for _, challengeType := range challengeTypes {
 		var existing models.ChallengeType
@@ -315,6 +392,25 @@ func seedDecayFormulas() {
 			Step:      10,
 			MinPoints: 10,
 		},
+		{
+			Name:      "Dynamic - Fast",
+			Type:      "dynamic",
+			Step:      10,
+			MinPoints: 50,
+		},
+		{
+			Name:      "Dynamic - Slow",
+			Type:      "dynamic",
+			Step:      30,
+			MinPoints: 100,
+		},
+		{
+			Name:      "Time - Hourly",
+			Type:      "time",
+			Step:      10,
+			MinPoints: 50,
+			Interval:  60,
+		},
 	}
 
 	for _, formula := range decayFormulas {
@@ -633,6 +729,11 @@ func CleanDemoData() error {
 		log.Printf("Failed to delete demo hint purchases: %v\n", err)
 	}
 
+	// Delete awards for demo teams
+	if err := DB.Where(queryTeamIDIn, teamIDs).Delete(&models.Award{}).Error; err != nil {
+		log.Printf("Failed to delete demo awards: %v\n", err)
+	}
+
 	// Delete demo users
 	if err := DB.Where("username LIKE ?", queryDemoUserPattern).Delete(&models.User{}).Error; err != nil {
 		log.Printf("Failed to delete demo users: %v\n", err)
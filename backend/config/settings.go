@@ -0,0 +1,50 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+)
+
+// GetConfigValue returns a config value, creating it from the PTA_ env variable if missing
+func GetConfigValue(key string, defaultValue string) string {
+	var cfg models.Config
+	if err := DB.Where("key = ?", key).First(&cfg).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Database error getting %s: %v", key, err)
+			return getEnvWithDefault("PTA_"+key, defaultValue)
+		}
+		cfg = models.Config{
+			Key:   key,
+			Value: getEnvWithDefault("PTA_"+key, defaultValue),
+		}
+		if createErr := DB.Create(&cfg).Error; createErr != nil {
+			log.Printf("Failed to create %s config: %v", key, createErr)
+		}
+	}
+	return cfg.Value
+}
+
+// GetConfigBool returns a config value parsed as a boolean
+func GetConfigBool(key string, defaultValue bool) bool {
+	value := strings.ToLower(strings.TrimSpace(GetConfigValue(key, strconv.FormatBool(defaultValue))))
+	switch value {
+	case "true", "1", "yes", "on":
+		return true
+	case "false", "0", "no", "off":
+		return false
+	}
+	return defaultValue
+}
+
+// GetConfigInt returns a config value parsed as an integer
+func GetConfigInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(GetConfigValue(key, strconv.Itoa(defaultValue))))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
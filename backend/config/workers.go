@@ -0,0 +1,76 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/docker/docker/client"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+var (
+	workerClients   = map[uint]*client.Client{}
+	workerClientsMu sync.Mutex
+)
+
+// GetWorkerClient returns a cached docker client for a worker, connecting if needed.
+// The mutex only guards the cache, pings run without it so a slow worker does not block the others.
+func GetWorkerClient(worker *models.Worker) (*client.Client, error) {
+	workerClientsMu.Lock()
+	cached, ok := workerClients[worker.ID]
+	workerClientsMu.Unlock()
+
+	if ok {
+		if _, err := cached.Ping(context.Background()); err == nil {
+			return cached, nil
+		}
+		dropWorkerClient(worker.ID, cached)
+	}
+
+	var tlsMaterial *DockerTLS
+	if worker.HasTLS() {
+		tlsMaterial = &DockerTLS{CACert: worker.TLSCACert, Cert: worker.TLSCert, Key: worker.TLSKey}
+	}
+
+	cl, err := NewDockerClient(worker.Host, tlsMaterial)
+	if err != nil {
+		return nil, fmt.Errorf("worker %s: %w", worker.Name, err)
+	}
+	if _, err := cl.Ping(context.Background()); err != nil {
+		cl.Close()
+		return nil, fmt.Errorf("worker %s unreachable: %w", worker.Name, err)
+	}
+
+	workerClientsMu.Lock()
+	defer workerClientsMu.Unlock()
+	// Another request may have connected meanwhile, keep a single client per worker
+	if existing, ok := workerClients[worker.ID]; ok {
+		cl.Close()
+		return existing, nil
+	}
+	workerClients[worker.ID] = cl
+	return cl, nil
+}
+
+// dropWorkerClient removes a dead client from the cache unless it was already replaced
+func dropWorkerClient(workerID uint, cl *client.Client) {
+	workerClientsMu.Lock()
+	defer workerClientsMu.Unlock()
+
+	if workerClients[workerID] == cl {
+		delete(workerClients, workerID)
+		cl.Close()
+	}
+}
+
+// ResetWorkerClient drops the cached client of a worker, e.g. after its settings changed
+func ResetWorkerClient(workerID uint) {
+	workerClientsMu.Lock()
+	defer workerClientsMu.Unlock()
+
+	if cl, ok := workerClients[workerID]; ok {
+		cl.Close()
+		delete(workerClients, workerID)
+	}
+}
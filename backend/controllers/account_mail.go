@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// sendAccountMail signs a token for the user and mails the link to the frontend page handling it.
+// It runs in the background so the response time does not tell whether the account exists.
+func sendAccountMail(user models.User, purpose string, page string, template string) {
+	ttl := utils.VerifyEmailTokenTTL
+	expiresIn := "24 hours"
+	if purpose == utils.MailTokenResetPassword {
+		ttl = utils.ResetPasswordTokenTTL
+		expiresIn = "1 hour"
+	}
+	baseLink := utils.PublicBaseURL() + page
+
+	go func() {
+		token, err := utils.GenerateMailToken(&user, purpose, ttl)
+		if err != nil {
+			log.Printf("Failed to sign %s token for user %d: %v", purpose, user.ID, err)
+			return
+		}
+		err = utils.SendTemplateMail(user.Email, template, map[string]interface{}{
+			"Username":  user.Username,
+			"Link":      baseLink + "?token=" + url.QueryEscape(token),
+			"ExpiresIn": expiresIn,
+		})
+		if err != nil {
+			log.Printf("Failed to send %s mail to user %d: %v", template, user.ID, err)
+		}
+	}()
+}
+
+// sendVerificationEmail mails the email verification link to a user
+func sendVerificationEmail(user models.User) {
+	sendAccountMail(user, utils.MailTokenVerifyEmail, "/verify-email", "verify_email")
+}
+
+// findUserByEmail looks a user up by email, ignoring case
+func findUserByEmail(email string) (*models.User, bool) {
+	var user models.User
+	if err := config.DB.Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).First(&user).Error; err != nil {
+		return nil, false
+	}
+	return &user, true
+}
+
+// VerifyEmail marks the email of the token owner as verified
+func VerifyEmail(c *gin.Context) {
+	var input dto.MailTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, ErrInvalidInput)
+		return
+	}
+
+	user, err := utils.ParseMailToken(input.Token, utils.MailTokenVerifyEmail)
+	if err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+	if err := config.DB.Model(user).Update("email_verified", true).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_verify_email")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "email_verified"})
+}
+
+// ResendVerificationEmail sends the verification link again. The answer is the same whether the email exists or not.
+func ResendVerificationEmail(c *gin.Context) {
+	var input dto.EmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, ErrInvalidInput)
+		return
+	}
+	if !utils.MailEnabled() {
+		utils.ServiceUnavailableError(c, "mail_disabled")
+		return
+	}
+
+	if user, ok := findUserByEmail(input.Email); ok && !user.EmailVerified && !user.Banned {
+		sendVerificationEmail(*user)
+	}
+	utils.OKResponse(c, gin.H{"message": "verification_email_sent"})
+}
+
+// ForgotPassword mails a password reset link. The answer is the same whether the email exists or not.
+func ForgotPassword(c *gin.Context) {
+	if !utils.LocalLoginEnabled() {
+		utils.ForbiddenError(c, "local_login_disabled")
+		return
+	}
+
+	var input dto.EmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, ErrInvalidInput)
+		return
+	}
+	if !utils.MailEnabled() {
+		utils.ServiceUnavailableError(c, "mail_disabled")
+		return
+	}
+
+	if user, ok := findUserByEmail(input.Email); ok && !user.Banned {
+		sendAccountMail(*user, utils.MailTokenResetPassword, "/reset-password", "reset_password")
+	}
+	utils.OKResponse(c, gin.H{"message": "password_reset_sent"})
+}
+
+// ResetPassword sets a new password with a reset token. Receiving the token also proves the email.
+func ResetPassword(c *gin.Context) {
+	if !utils.LocalLoginEnabled() {
+		utils.ForbiddenError(c, "local_login_disabled")
+		return
+	}
+
+	var input dto.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "password_length")
+		return
+	}
+
+	user, err := utils.ParseMailToken(input.Token, utils.MailTokenResetPassword)
+	if err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to hash new password")
+		return
+	}
+	if err := config.DB.Model(user).Updates(map[string]interface{}{
+		"password":       string(hashedPassword),
+		"email_verified": true,
+	}).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_reset_password")
+		return
+	}
+	// Whoever knew the old password is logged out
+	if _, err := utils.RevokeUserSessions(user.ID, 0); err != nil {
+		log.Printf("Failed to revoke the sessions of user %d: %v", user.ID, err)
+	}
+	utils.OKResponse(c, gin.H{"message": "password_reset"})
+}
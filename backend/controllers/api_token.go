@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// maxAPITokensPerUser caps the tokens a user can hold at once
+const maxAPITokensPerUser = 20
+
+// GetAPITokens lists the API tokens of the current user
+func GetAPITokens(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var tokens []models.APIToken
+	if err := config.DB.Where(QueryUserID, user.ID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_api_tokens")
+		return
+	}
+	utils.OKResponse(c, tokens)
+}
+
+// CreateAPIToken creates an API token for the current user. The token is only returned in this response.
+func CreateAPIToken(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var input dto.APITokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, ErrInvalidInput)
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		utils.BadRequestError(c, "api_token_name_required")
+		return
+	}
+	if input.Scope == models.APITokenScopeAdmin && user.Role != "admin" {
+		utils.ForbiddenError(c, "api_token_scope_forbidden")
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		utils.BadRequestError(c, "api_token_expiry_in_past")
+		return
+	}
+
+	var count int64
+	if err := config.DB.Model(&models.APIToken{}).Where(QueryUserID, user.ID).Count(&count).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_create_api_token")
+		return
+	}
+	if count >= maxAPITokensPerUser {
+		utils.BadRequestError(c, "api_token_limit_reached")
+		return
+	}
+
+	token, hash, prefix, err := utils.GenerateAPIToken()
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_create_api_token")
+		return
+	}
+	apiToken := models.APIToken{
+		UserID:    user.ID,
+		Name:      input.Name,
+		TokenHash: hash,
+		Prefix:    prefix,
+		Scope:     input.Scope,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := config.DB.Create(&apiToken).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_create_api_token")
+		return
+	}
+
+	utils.CreatedResponse(c, dto.CreatedAPIToken{APIToken: apiToken, Token: token})
+}
+
+// DeleteAPIToken revokes an API token of the current user
+func DeleteAPIToken(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	result := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).Delete(&models.APIToken{})
+	if result.Error != nil {
+		utils.InternalServerError(c, "failed_to_delete_api_token")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.NotFoundError(c, "api_token_not_found")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "api_token_revoked"})
+}
@@ -1,14 +1,15 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
@@ -19,6 +20,11 @@ import (
 
 // Register creates a new user account
 func Register(c *gin.Context) {
+	if !utils.LocalLoginEnabled() {
+		utils.ForbiddenError(c, "local_login_disabled")
+		return
+	}
+
 	// Check if registration is enabled
 	var registrationConfig models.Config
 	if err := config.DB.Where("key = ?", "REGISTRATION_ENABLED").First(&registrationConfig).Error; err != nil {
@@ -90,10 +96,23 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// Without a mailer the address cannot be checked, so the account stays verified
+	verificationSent := false
+	if utils.MailEnabled() {
+		if err := config.DB.Model(&user).Update("email_verified", false).Error; err != nil {
+			utils.InternalServerError(c, "Failed to create user")
+			return
+		}
+		user.EmailVerified = false
+		sendVerificationEmail(user)
+		verificationSent = true
+	}
+
 	utils.CreatedResponse(c, gin.H{
-		"id":       user.ID,
-		"username": user.Username,
-		"email":    user.Email,
+		"id":                    user.ID,
+		"username":              user.Username,
+		"email":                 user.Email,
+		"emailVerificationSent": verificationSent,
 	})
 }
 
@@ -127,25 +146,73 @@ func authenticateUser(usernameOrEmail, password string) (*models.User, error) {
 	return &user, nil
 }
 
-// generateAndSetTokens creates tokens and sets them as cookies
+// getUserFromContext returns the user set by AuthRequired, answering the request when it is missing
+func getUserFromContext(c *gin.Context) (*models.User, bool) {
+	userI, exists := c.Get("user")
+	if !exists {
+		utils.UnauthorizedError(c, "unauthorized")
+		return nil, false
+	}
+	user, ok := userI.(*models.User)
+	if !ok {
+		utils.InternalServerError(c, "user_wrong_type")
+		return nil, false
+	}
+	return user, true
+}
+
+// publicURL returns the public address of a path, from PUBLIC_URL or else the host the request went through.
+// Links sent by mail must use utils.PublicBaseURL instead, as the request headers are not trusted.
+func publicURL(c *gin.Context, path string) string {
+	if base := utils.PublicBaseURL(); base != "" {
+		return base + path
+	}
+	scheme := c.GetHeader("X-Forwarded-Proto")
+	if scheme == "" {
+		scheme = "https"
+	}
+	host := c.GetHeader("X-Forwarded-Host")
+	if host == "" {
+		host = c.Request.Host
+	}
+	return scheme + "://" + host + path
+}
+
+// generateAndSetTokens starts a session for the user and sets its tokens as cookies
 func generateAndSetTokens(c *gin.Context, userID uint, role string) error {
-	accessToken, err := utils.GenerateAccessToken(userID, role)
+	session, refreshToken, err := utils.CreateSession(c, userID)
 	if err != nil {
-		return fmt.Errorf("could not create access token")
+		return fmt.Errorf("could not create refresh token")
 	}
-	
-	refreshToken, err := utils.GenerateRefreshToken(userID)
+
+	accessToken, err := utils.GenerateAccessToken(userID, role, session.ID)
 	if err != nil {
-		return fmt.Errorf("could not create refresh token")
+		return fmt.Errorf("could not create access token")
 	}
-	
+
 	// Set both tokens as secure HTTP-only cookies
-	c.SetCookie("access_token", accessToken, 3600, "/", "", true, true)        // 1 hour, secure, httpOnly
-	c.SetCookie("refresh_token", refreshToken, 7*24*3600, "/", "", true, true) // 7 days, secure, httpOnly
-	
+	c.SetCookie("access_token", accessToken, 3600, "/", "", true, true) // 1 hour, secure, httpOnly
+	setRefreshCookie(c, refreshToken, session)
+
 	return nil
 }
 
+// setRefreshCookie stores a refresh token in a cookie lasting as long as its session
+func setRefreshCookie(c *gin.Context, refreshToken string, session *models.Session) {
+	c.SetCookie("refresh_token", refreshToken, int(time.Until(session.ExpiresAt).Seconds()), "/", "", true, true)
+}
+
+// clearAuthCookies removes the tokens and the cookie session of the browser
+func clearAuthCookies(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Clear()
+	session.Options(sessions.Options{Path: "/", MaxAge: -1})
+	session.Save()
+
+	c.SetCookie("access_token", "", -1, "/", "", true, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
+}
+
 func Login(c *gin.Context) {
 	var input dto.LoginInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -171,6 +238,27 @@ func Login(c *gin.Context) {
 		return
 	}
 	
+	// Admins keep their password so a broken identity provider cannot lock everyone out
+	if !utils.LocalLoginEnabled() && user.Role != "admin" {
+		utils.ForbiddenError(c, "local_login_disabled")
+		return
+	}
+
+	if !user.EmailVerified && config.GetConfigBool("REQUIRE_EMAIL_VERIFICATION", false) {
+		utils.ForbiddenError(c, "email_not_verified")
+		return
+	}
+
+	// Cookies are only issued once the second factor is checked by LoginTwoFactor
+	if needsSecondFactor(user) {
+		if err := utils.SetPendingTwoFactor(c, user.ID); err != nil {
+			utils.InternalServerError(c, "could not start the second login step")
+			return
+		}
+		utils.OKResponse(c, gin.H{"twoFactorRequired": true, "twoFactorSetup": !user.TOTPEnabled})
+		return
+	}
+
 	// Generate and set tokens
 	if err := generateAndSetTokens(c, user.ID, user.Role); err != nil {
 		utils.InternalServerError(c, err.Error())
@@ -180,6 +268,7 @@ func Login(c *gin.Context) {
 	utils.OKResponse(c, gin.H{"message": "Login successful"})
 }
 
+// Refresh rotates the refresh token of the session and issues a new access token
 func Refresh(c *gin.Context) {
 	tokenStr, err := c.Cookie("refresh_token")
 	if err != nil {
@@ -187,33 +276,37 @@ func Refresh(c *gin.Context) {
 		return
 	}
 
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return utils.RefreshSecret, nil
-	})
-	if err != nil || !token.Valid {
-		utils.UnauthorizedError(c, "invalid refresh token")
-		return
-	}
-
-	claims := token.Claims.(*jwt.RegisteredClaims)
-	userID, err := strconv.Atoi(claims.Subject)
+	session, refreshToken, err := utils.RotateSession(c, tokenStr)
 	if err != nil {
-		utils.InternalServerError(c, "can't read subject")
+		if errors.Is(err, utils.ErrRefreshTokenReused) || errors.Is(err, utils.ErrInvalidRefreshToken) {
+			clearAuthCookies(c)
+			utils.UnauthorizedError(c, err.Error())
+			return
+		}
+		utils.InternalServerError(c, "failed to refresh session")
 		return
 	}
 
 	var user models.User
-	if err := config.DB.First(&user, userID).Error; err != nil {
+	if err := config.DB.First(&user, session.UserID).Error; err != nil {
 		utils.UnauthorizedError(c, "user not found")
 		return
 	}
 
 	if user.Banned {
+		utils.RevokeSession(user.ID, session.ID)
 		utils.UnauthorizedError(c, "banned")
 		return
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.ID, user.Role)
+	// Admins who never enrolled log in again to go through the enrolment once REQUIRE_ADMIN_2FA is on
+	if utils.TwoFactorRequired(&user) && !user.TOTPEnabled {
+		utils.RevokeSession(user.ID, session.ID)
+		utils.UnauthorizedError(c, "2fa_setup_required")
+		return
+	}
+
+	accessToken, err := utils.GenerateAccessToken(user.ID, user.Role, session.ID)
 	if err != nil {
 		utils.InternalServerError(c, "failed to generate access token")
 		return
@@ -221,21 +314,20 @@ func Refresh(c *gin.Context) {
 
 	// Set the new access token as a secure HTTP-only cookie
 	c.SetCookie("access_token", accessToken, 3600, "/", "", true, true) // 1 hour, secure, httpOnly
+	// A concurrent refresh already rotated the token, the browser has the new one
+	if refreshToken != "" {
+		setRefreshCookie(c, refreshToken, session)
+	}
 
 	utils.OKResponse(c, gin.H{"message": "Token refreshed"})
 }
 
-// Logout clears the user session
+// Logout ends the session of the browser and clears its cookies
 func Logout(c *gin.Context) {
-	// Clear the session
-	session := sessions.Default(c)
-	session.Clear()
-	session.Options(sessions.Options{Path: "/", MaxAge: -1})
-	session.Save()
-
-	// Clear both JWT cookies
-	c.SetCookie("access_token", "", -1, "/", "", true, true)
-	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
+	if tokenStr, err := c.Cookie("refresh_token"); err == nil {
+		utils.RevokeRefreshTokenSession(tokenStr)
+	}
+	clearAuthCookies(c)
 
 	utils.OKResponse(c, gin.H{"message": "logged out"})
 }
@@ -301,6 +393,12 @@ func UpdateCurrentUserPassword(c *gin.Context) {
 		return
 	}
 
+	// Admins can still log in with their password, so they may change it
+	if !utils.LocalLoginEnabled() && user.Role != "admin" {
+		utils.ForbiddenError(c, "local_login_disabled")
+		return
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Current)); err != nil {
 		utils.UnauthorizedError(c, "Current password is incorrect")
 		return
@@ -317,6 +415,10 @@ func UpdateCurrentUserPassword(c *gin.Context) {
 		utils.InternalServerError(c, err.Error())
 		return
 	}
+	// Other devices must log in again with the new password
+	if _, err := utils.RevokeUserSessions(user.ID, currentSessionID(c)); err != nil {
+		log.Printf("Failed to revoke the other sessions of user %d: %v", user.ID, err)
+	}
 	utils.OKResponse(c, gin.H{"message": "Password updated"})
 }
 
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// GetAwards returns every award, optionally for a single team with ?teamId=
+func GetAwards(c *gin.Context) {
+	query := config.DB.Preload("Team").Preload("User").Order("awarded_at DESC")
+	if teamID := c.Query("teamId"); teamID != "" {
+		query = query.Where("team_id = ?", teamID)
+	}
+
+	var awards []models.Award
+	if err := query.Find(&awards).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_awards")
+		return
+	}
+	utils.OKResponse(c, awards)
+}
+
+// GetAward returns a single award
+func GetAward(c *gin.Context) {
+	var award models.Award
+	if err := config.DB.Preload("Team").Preload("User").First(&award, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "award_not_found")
+		return
+	}
+	utils.OKResponse(c, award)
+}
+
+// applyAwardInput validates an award input and copies it to the award
+func applyAwardInput(award *models.Award, input dto.AwardInput) error {
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Value == 0 {
+		return fmt.Errorf("invalid_award_value")
+	}
+	if input.Reason == "" {
+		return fmt.Errorf("award_reason_required")
+	}
+
+	var team models.Team
+	if err := config.DB.First(&team, input.TeamID).Error; err != nil {
+		return fmt.Errorf("team_not_found")
+	}
+	if input.UserID != nil {
+		var user models.User
+		if err := config.DB.First(&user, *input.UserID).Error; err != nil {
+			return fmt.Errorf("user_not_found")
+		}
+		if user.TeamID == nil || *user.TeamID != team.ID {
+			return fmt.Errorf("user_not_in_team")
+		}
+	}
+
+	award.TeamID = team.ID
+	award.UserID = input.UserID
+	award.Value = input.Value
+	award.Reason = input.Reason
+	award.Category = strings.TrimSpace(input.Category)
+	if input.AwardedAt != nil {
+		award.AwardedAt = *input.AwardedAt
+	} else if award.AwardedAt.IsZero() {
+		award.AwardedAt = time.Now()
+	}
+	return nil
+}
+
+// notifyAward tells the team about a new award or penalty
+func notifyAward(award *models.Award) {
+	title := "Award"
+	notificationType := "success"
+	if award.Value < 0 {
+		title = "Penalty"
+		notificationType = "warning"
+	}
+
+	teamID := award.TeamID
+	notification := models.Notification{
+		Title:   title,
+		Message: fmt.Sprintf("%+d points: %s", award.Value, award.Reason),
+		Type:    notificationType,
+		TeamID:  &teamID,
+	}
+	if err := utils.SendNotification(&notification); err != nil {
+		log.Printf("Failed to notify team %d of award %d: %v", award.TeamID, award.ID, err)
+	}
+}
+
+// CreateAward grants a bonus or a penalty to a team
+func CreateAward(c *gin.Context) {
+	var input dto.AwardInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	var award models.Award
+	if err := applyAwardInput(&award, input); err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+	if err := config.DB.Create(&award).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_create_award")
+		return
+	}
+
+	if err := utils.RefreshTeamScores(award.TeamID); err != nil {
+		log.Printf("Failed to refresh scoreboard for team %d: %v", award.TeamID, err)
+	}
+	notifyAward(&award)
+	log.Printf("Award %d: %+d points to team %d (%s)", award.ID, award.Value, award.TeamID, award.Reason)
+
+	utils.CreatedResponse(c, award)
+}
+
+// UpdateAward changes an award, possibly moving it to another team
+func UpdateAward(c *gin.Context) {
+	var award models.Award
+	if err := config.DB.First(&award, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "award_not_found")
+		return
+	}
+
+	var input dto.AwardInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	previousTeamID := award.TeamID
+	if err := applyAwardInput(&award, input); err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+	if err := config.DB.Save(&award).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_update_award")
+		return
+	}
+
+	if err := utils.RefreshTeamScores(previousTeamID, award.TeamID); err != nil {
+		log.Printf("Failed to refresh scoreboard for award %d: %v", award.ID, err)
+	}
+
+	utils.OKResponse(c, award)
+}
+
+// DeleteAward removes an award from the team score
+func DeleteAward(c *gin.Context) {
+	var award models.Award
+	if err := config.DB.First(&award, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "award_not_found")
+		return
+	}
+
+	if err := config.DB.Delete(&award).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_delete_award")
+		return
+	}
+	if err := utils.RefreshTeamScores(award.TeamID); err != nil {
+		log.Printf("Failed to refresh scoreboard for team %d: %v", award.TeamID, err)
+	}
+
+	utils.OKResponse(c, gin.H{"message": "award_deleted"})
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// GetBrackets returns every bracket
+func GetBrackets(c *gin.Context) {
+	var brackets []models.Bracket
+	if err := config.DB.Order("name ASC").Find(&brackets).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_brackets")
+		return
+	}
+	utils.OKResponse(c, brackets)
+}
+
+// CreateBracket creates a new bracket
+func CreateBracket(c *gin.Context) {
+	var input dto.BracketInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	bracket := models.Bracket{
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+	}
+	if bracket.Name == "" {
+		utils.BadRequestError(c, "bracket_name_cannot_be_empty")
+		return
+	}
+	if err := config.DB.Create(&bracket).Error; err != nil {
+		utils.ConflictError(c, "bracket_name_already_exists")
+		return
+	}
+
+	utils.CreatedResponse(c, bracket)
+}
+
+// UpdateBracket renames or describes a bracket
+func UpdateBracket(c *gin.Context) {
+	var bracket models.Bracket
+	if err := config.DB.First(&bracket, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "bracket_not_found")
+		return
+	}
+
+	var input dto.BracketInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+
+	bracket.Name = strings.TrimSpace(input.Name)
+	bracket.Description = input.Description
+	if bracket.Name == "" {
+		utils.BadRequestError(c, "bracket_name_cannot_be_empty")
+		return
+	}
+	if err := config.DB.Save(&bracket).Error; err != nil {
+		utils.ConflictError(c, "bracket_name_already_exists")
+		return
+	}
+
+	utils.OKResponse(c, bracket)
+}
+
+// DeleteBracket deletes a bracket, its teams are left without one
+func DeleteBracket(c *gin.Context) {
+	var bracket models.Bracket
+	if err := config.DB.First(&bracket, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "bracket_not_found")
+		return
+	}
+
+	if err := config.DB.Model(&models.Team{}).Unscoped().Where("bracket_id = ?", bracket.ID).
+		Update("bracket_id", nil).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_update_teams")
+		return
+	}
+	if err := config.DB.Delete(&bracket).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_delete_bracket")
+		return
+	}
+	refreshBracketFirstBloods()
+
+	utils.OKResponse(c, gin.H{"message": "bracket_deleted"})
+}
+
+// SetTeamBracket assigns a team to a bracket, or removes it from its bracket
+func SetTeamBracket(c *gin.Context) {
+	var team models.Team
+	if err := config.DB.First(&team, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "team_not_found")
+		return
+	}
+
+	var input dto.TeamBracketInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, "invalid_input")
+		return
+	}
+	if input.BracketID != nil && !bracketExists(*input.BracketID) {
+		utils.BadRequestError(c, "bracket_not_found")
+		return
+	}
+
+	if err := config.DB.Model(&team).Update("bracket_id", input.BracketID).Error; err != nil {
+		utils.InternalServerError(c, "team_update_failed")
+		return
+	}
+	team.BracketID = input.BracketID
+	refreshBracketFirstBloods()
+
+	utils.OKResponse(c, team)
+}
+
+// bracketExists tells if a bracket exists
+func bracketExists(id uint) bool {
+	var count int64
+	config.DB.Model(&models.Bracket{}).Where("id = ?", id).Count(&count)
+	return count > 0
+}
+
+// refreshBracketFirstBloods rebuilds the scoreboard when first blood bonuses depend on brackets
+func refreshBracketFirstBloods() {
+	if utils.FirstBloodPerBracket() {
+		refreshBracketScoreboard()
+	}
+}
+
+// refreshBracketScoreboard rebuilds the scoreboard after first blood ranks changed
+func refreshBracketScoreboard() {
+	if _, err := utils.RebuildScoreboard(); err != nil {
+		log.Printf("Failed to rebuild scoreboard after bracket change: %v", err)
+	}
+}
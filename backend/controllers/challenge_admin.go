@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"log"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
@@ -36,18 +37,6 @@ func updateChallengeFields(challenge *models.Challenge, req *dto.ChallengeAdminU
 	}
 }
 
-// broadcastChallengeUpdate sends WebSocket notification for challenge update
-func broadcastChallengeUpdate() {
-	if utils.UpdatesHub != nil {
-		if payload, err := json.Marshal(gin.H{
-			"event":  "challenge-category",
-			"action": "challenge_update",
-		}); err == nil {
-			utils.UpdatesHub.SendToAll(payload)
-		}
-	}
-}
-
 // processHintsFromRequest creates or updates hints based on the request
 func processHintsFromRequest(challengeID uint, hints *[]models.Hint) {
 	if hints == nil {
@@ -122,7 +111,7 @@ func UpdateChallengeAdmin(c *gin.Context) {
 	}
 	
 	// Broadcast update
-	broadcastChallengeUpdate()
+	utils.BroadcastChallengeUpdate()
 	
 	// Recalculate points
 	recalculateChallengePoints(challenge.ID)
@@ -237,6 +226,73 @@ func GetAllChallengesAdmin(c *gin.Context) {
 	utils.OKResponse(c, challenges)
 }
 
+// GetArchivedChallengesAdmin lists the challenges whose chall.yml disappeared, with their solve count
+func GetArchivedChallengesAdmin(c *gin.Context) {
+	var challenges []models.Challenge
+	if err := config.DB.Preload("ChallengeCategory").Preload("ChallengeType").
+		Where("archived_at IS NOT NULL").Order("archived_at DESC").Find(&challenges).Error; err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	archived := make([]dto.ArchivedChallenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		var solveCount int64
+		config.DB.Model(&models.Solve{}).Where(queryChallengeID, challenge.ID).Count(&solveCount)
+		archived = append(archived, dto.ArchivedChallenge{Challenge: challenge, SolveCount: solveCount})
+	}
+
+	utils.OKResponse(c, archived)
+}
+
+// PurgeChallengeAdmin deletes an archived challenge along with its solves, first bloods and instances
+func PurgeChallengeAdmin(c *gin.Context) {
+	var challenge models.Challenge
+	if err := config.DB.Preload("ChallengeType").First(&challenge, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "challenge_not_found")
+		return
+	}
+	if challenge.ArchivedAt == nil {
+		utils.ConflictError(c, "challenge_not_archived")
+		return
+	}
+
+	var teamIDs []uint
+	config.DB.Model(&models.Solve{}).Where(queryChallengeID, challenge.ID).Distinct().Pluck("team_id", &teamIDs)
+
+	var instances []models.Instance
+	config.DB.Where(queryChallengeID, challenge.ID).Find(&instances)
+
+	if err := config.DB.Delete(&challenge).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_purge_challenge")
+		return
+	}
+
+	// Instance rows went with the challenge, their containers or VMs still have to be stopped
+	typeName := ""
+	if challenge.ChallengeType != nil {
+		typeName = challenge.ChallengeType.Name
+	}
+	for _, instance := range instances {
+		if instance.Container == "" {
+			continue
+		}
+		go func(instance models.Instance) {
+			if err := stopInstanceResources(typeName, &instance); err != nil {
+				debug.Log("Warning: Error stopping instance %s of purged challenge: %v", instance.Container, err)
+			}
+		}(instance)
+	}
+
+	if err := utils.RefreshTeamScores(teamIDs...); err != nil {
+		log.Printf("Failed to refresh scoreboard after purging challenge %d: %v", challenge.ID, err)
+	}
+	utils.BroadcastChallengeUpdate()
+	log.Printf("Purged archived challenge %d (%s), %d team(s) lost a solve", challenge.ID, challenge.Slug, len(teamIDs))
+
+	utils.OKResponse(c, gin.H{"message": "challenge_purged"})
+}
+
 func DeleteHint(c *gin.Context) {
 	hintID := c.Param("hintId")
 
@@ -274,7 +330,7 @@ func recalculateChallengePoints(challengeID uint) {
 	// Recalculate points for each solve based on its position
 	for i, solve := range solves {
 		position := i
-		newPoints := decayService.CalculateDecayedPoints(&challenge, position)
+		newPoints := decayService.CalculateDecayedPoints(&challenge, position, solve.CreatedAt)
 
 		// Add FirstBlood bonus if applicable
 		firstBloodBonus := 0
@@ -314,6 +370,10 @@ func recalculateChallengePoints(challengeID uint) {
 			}
 		}
 	}
+
+	if err := utils.RefreshChallengeScores(challengeID); err != nil {
+		debug.Log("Failed to refresh scoreboard for challenge %d: %v", challengeID, err)
+	}
 }
 
 // CheckAndActivateHints endpoint to manually activate ALL hints for admin
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// ExportChallengesArchive streams every challenge and the event-wide data as a tar.gz archive
+func ExportChallengesArchive(c *gin.Context) {
+	filename := fmt.Sprintf("pwnthemall-challenges-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Header("Content-Type", "application/gzip")
+
+	if err := utils.ExportChallengesArchive(c.Request.Context(), c.Writer); err != nil {
+		// Headers are already sent, the client gets a truncated archive
+		log.Printf("Challenge export failed: %v", err)
+		c.Abort()
+	}
+}
+
+// ImportChallengesArchive imports an archive produced by ExportChallengesArchive
+func ImportChallengesArchive(c *gin.Context) {
+	fileHeader, err := c.FormFile("archive")
+	if err != nil {
+		utils.BadRequestError(c, "archive_required")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequestError(c, "archive_unreadable")
+		return
+	}
+	defer file.Close()
+
+	result, err := utils.ImportChallengesArchive(c.Request.Context(), file, utils.UpdatesHub)
+	if err != nil {
+		log.Printf("Challenge import failed: %v", err)
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
+	utils.OKResponse(c, result)
+}
@@ -139,7 +139,19 @@ func buildChallengeWithSolved(challenge models.Challenge, solvedChallengeIds []u
 	return item
 }
 
-// GetChallenges returns all visible challenges
+// lockChallenge hides the content of a challenge whose prerequisites are not met
+func lockChallenge(item *dto.ChallengeWithSolved, missing []models.Prerequisite) {
+	item.Locked = true
+	item.MissingPrerequisites = missing
+	item.Description = ""
+	item.Files = nil
+	item.ConnectionInfo = nil
+	item.Hints = nil
+	item.GeoRadiusKm = nil
+}
+
+// GetChallenges returns all visible challenges, the ones still locked for the team showing only their card
+// like in the category view
 func GetChallenges(c *gin.Context) {
 	var challenges []models.Challenge
 	result := config.DB.Preload("DecayFormula").Where("hidden = false").Find(&challenges)
@@ -148,13 +160,31 @@ func GetChallenges(c *gin.Context) {
 		return
 	}
 	
+	var progress *utils.PrerequisiteProgress
+	if user, ok := c.Get("user"); ok {
+		if u, ok := user.(*models.User); ok && u.Role != "admin" && u.Team != nil {
+			var err error
+			if progress, err = utils.LoadPrerequisiteProgress(u.Team.ID); err != nil {
+				utils.InternalServerError(c, err.Error())
+				return
+			}
+		}
+	}
+	
 	// Calculate current points with decay for each challenge
 	decayService := utils.NewDecay()
+	items := make([]dto.ChallengeWithSolved, len(challenges))
 	for i := range challenges {
 		challenges[i].CurrentPoints = decayService.CalculateCurrentPoints(&challenges[i])
+		items[i] = dto.ChallengeWithSolved{Challenge: challenges[i]}
+		if progress != nil {
+			if missing := progress.Missing(&challenges[i]); len(missing) > 0 {
+				lockChallenge(&items[i], missing)
+			}
+		}
 	}
 	
-	utils.OKResponse(c, challenges)
+	utils.OKResponse(c, items)
 }
 
 // GetChallenge returns a single challenge by ID
@@ -231,17 +261,13 @@ func GetChallengesByCategoryName(c *gin.Context) {
 	// Check and activate scheduled hints
 	utils.CheckAndActivateHintsForChallenges(challenges)
 
-	// Build a map of solved challenge names for dependency checking
-	solvedNamesMap := make(map[string]bool)
-	if user.Team != nil {
-		var solvedChallenges []models.Challenge
-		config.DB.Table("challenges").
-			Joins("JOIN solves ON solves.challenge_id = challenges.id").
-			Where("solves.team_id = ?", user.Team.ID).
-			Select("challenges.name").
-			Find(&solvedChallenges)
-		for _, ch := range solvedChallenges {
-			solvedNamesMap[ch.Name] = true
+	// Load the team progress for prerequisite checking
+	var progress *utils.PrerequisiteProgress
+	if user.Team != nil && user.Role != "admin" {
+		var err error
+		if progress, err = utils.LoadPrerequisiteProgress(user.Team.ID); err != nil {
+			utils.InternalServerError(c, err.Error())
+			return
 		}
 	}
 
@@ -251,8 +277,10 @@ func GetChallengesByCategoryName(c *gin.Context) {
 	
 	// Build a map of challenge names to their data for dependency ordering
 	challengeMap := make(map[string]models.Challenge)
+	slugToName := make(map[string]string)
 	for _, challenge := range challenges {
 		challengeMap[challenge.Name] = challenge
+		slugToName[challenge.Slug] = challenge.Name
 	}
 	
 	// Sort challenges respecting dependency chains
@@ -271,10 +299,15 @@ func GetChallengesByCategoryName(c *gin.Context) {
 			return
 		}
 		
-		// First, add the dependency if it exists
+		// First, add the dependencies if they exist
 		if challenge.DependsOn != "" {
 			addChallengeWithDeps(challenge.DependsOn)
 		}
+		for _, slug := range utils.PrerequisiteSlugs(challenge.Prerequisites) {
+			if dependencyName, ok := slugToName[slug]; ok {
+				addChallengeWithDeps(dependencyName)
+			}
+		}
 		
 		// Then add this challenge
 		orderedChallenges = append(orderedChallenges, challenge)
@@ -288,16 +321,14 @@ func GetChallengesByCategoryName(c *gin.Context) {
 	
 	// Build response from ordered challenges
 	for _, challenge := range orderedChallenges {
-		// Check if challenge has a dependency and if user is not admin
-		if challenge.DependsOn != "" && user.Role != "admin" {
-			// Check if the required challenge has been solved
-			if !solvedNamesMap[challenge.DependsOn] {
-				// Skip locked challenges - don't show them until dependency is solved
-				continue
+		item := buildChallengeWithSolved(challenge, solvedChallengeIds, purchasedHintIds, failedAttemptsMap, user.Role, decayService)
+		
+		// Locked challenges only show their card and what is missing to unlock them
+		if progress != nil {
+			if missing := progress.Missing(&challenge); len(missing) > 0 {
+				lockChallenge(&item, missing)
 			}
 		}
-		
-		item := buildChallengeWithSolved(challenge, solvedChallengeIds, purchasedHintIds, failedAttemptsMap, user.Role, decayService)
 		challengesWithSolved = append(challengesWithSolved, item)
 	}
 
@@ -466,6 +497,7 @@ func GetChallengeFirstBloods(c *gin.Context) {
 	err := config.DB.Preload("Challenge").
 		Preload("Team").
 		Preload("User").
+		Scopes(visibleFirstBloods(c)).
 		Where("challenge_id = ?", challengeIDStr).
 		Order("created_at ASC").
 		Find(&firstBloods).Error
@@ -1,10 +1,14 @@
 package controllers
 
 import (
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PurchaseHint allows a team to purchase a hint for points
@@ -44,6 +48,12 @@ func PurchaseHint(c *gin.Context) {
 		return
 	}
 
+	// Make sure the team has a scoreboard row to lock
+	if _, err := utils.GetTeamScoreEntry(*user.TeamID); err != nil {
+		utils.InternalServerError(c, "failed_to_calculate_score")
+		return
+	}
+
 	// Start transaction
 	tx := config.DB.Begin()
 	defer func() {
@@ -59,31 +69,24 @@ func PurchaseHint(c *gin.Context) {
 		return
 	}
 
-	// Check if team already purchased this hint
-	var existingPurchase models.HintPurchase
-	if err := tx.Where("team_id = ? AND hint_id = ?", *user.TeamID, hint.ID).First(&existingPurchase).Error; err == nil {
+	// Lock the team scoreboard row so concurrent purchases are checked one after the other
+	var entry models.ScoreboardEntry
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("team_id = ?", *user.TeamID).First(&entry).Error; err != nil {
 		tx.Rollback()
-		utils.BadRequestError(c, "hint_already_purchased")
+		utils.InternalServerError(c, "failed_to_calculate_score")
 		return
 	}
 
-	// Calculate team score with decay
-	decayService := utils.NewDecay()
-	totalScore, err := calculateTeamScore(*user.TeamID, decayService)
-	if err != nil {
+	// Check if team already purchased this hint
+	var existingPurchase models.HintPurchase
+	if err := tx.Where("team_id = ? AND hint_id = ?", *user.TeamID, hint.ID).First(&existingPurchase).Error; err == nil {
 		tx.Rollback()
-		utils.InternalServerError(c, "failed_to_calculate_score")
+		utils.BadRequestError(c, "hint_already_purchased")
 		return
 	}
 
-	// Get total spent on hints
-	var totalSpent int64
-	tx.Model(&models.HintPurchase{}).
-		Where("team_id = ?", *user.TeamID).
-		Select("COALESCE(SUM(cost), 0)").
-		Scan(&totalSpent)
-
-	availableScore := totalScore - int(totalSpent)
+	// The scoreboard total already has the previous hint costs deducted
+	availableScore := entry.TotalScore
 
 	// Check if team has enough points
 	if availableScore < hint.Cost {
@@ -110,12 +113,26 @@ func PurchaseHint(c *gin.Context) {
 		return
 	}
 
+	// Deduct the cost before releasing the lock, the next purchase must see it even before the refresh below
+	if err := tx.Model(&models.ScoreboardEntry{}).Where("team_id = ?", *user.TeamID).Updates(map[string]interface{}{
+		"hints_cost":  gorm.Expr("hints_cost + ?", hint.Cost),
+		"total_score": gorm.Expr("total_score - ?", hint.Cost),
+	}).Error; err != nil {
+		tx.Rollback()
+		utils.InternalServerError(c, "failed_to_purchase_hint")
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		utils.InternalServerError(c, "failed_to_commit_transaction")
 		return
 	}
 
+	if err := utils.RefreshTeamScores(*user.TeamID); err != nil {
+		log.Printf("Failed to refresh score of team %d: %v", *user.TeamID, err)
+	}
+
 	utils.OKResponse(c, gin.H{
 		"message": "hint_purchased",
 		"hint":    hint,
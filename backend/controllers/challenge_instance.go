@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/client"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	"github.com/pwnthemall/pwnthemall/backend/config"
@@ -26,17 +28,36 @@ const (
 	msgDockerUnavailable    = "Docker service is currently unavailable. Please try again later or contact an administrator."
 )
 
-// ensureImageBuiltOrBuild checks if Docker image exists, builds if necessary
-func ensureImageBuiltOrBuild(challenge models.Challenge) (string, error) {
-	imageName, exists := utils.IsImageBuilt(challenge.Slug)
-	if exists {
-		return imageName, nil
+// selectInstanceWorker picks the docker host of a new instance; workerID is nil for the default host
+func selectInstanceWorker(challenge models.Challenge) (*uint, *client.Client, error) {
+	worker, err := utils.SelectWorker(challenge.WorkerLabels)
+	if err != nil {
+		debug.Log("Worker selection failed: %v", err)
+		return nil, nil, fmt.Errorf("no_worker_available")
 	}
 
-	// Check Docker connection
-	if err := utils.EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker connection failed for challenge %s: %v", challenge.Slug, err)
-		return "", fmt.Errorf(errDockerUnavailable)
+	if worker == nil {
+		if err := utils.EnsureDockerClientConnected(); err != nil {
+			debug.Log("Docker connection failed: %v", err)
+			return nil, nil, fmt.Errorf(errDockerUnavailable)
+		}
+		return nil, config.DockerClient, nil
+	}
+
+	cli, err := config.GetWorkerClient(worker)
+	if err != nil {
+		debug.Log("Worker %s unreachable: %v", worker.Name, err)
+		utils.CheckWorkerHealth(worker)
+		return nil, nil, fmt.Errorf(errDockerUnavailable)
+	}
+	return &worker.ID, cli, nil
+}
+
+// ensureImageBuiltOrBuild checks if Docker image exists on the given host, builds if necessary
+func ensureImageBuiltOrBuild(cli *client.Client, challenge models.Challenge) (string, error) {
+	imageName, exists := utils.IsImageBuilt(cli, challenge.Slug)
+	if exists {
+		return imageName, nil
 	}
 
 	// Download challenge context
@@ -49,7 +70,7 @@ func ensureImageBuiltOrBuild(challenge models.Challenge) (string, error) {
 	defer os.RemoveAll(tmpDir)
 
 	// Build image
-	imageName, err := utils.BuildDockerImage(challenge.Slug, tmpDir)
+	imageName, err := utils.BuildDockerImage(cli, challenge.Slug, tmpDir)
 	if err != nil {
 		debug.Log("Docker build failed for challenge %s: %v", challenge.Slug, err)
 		return "", fmt.Errorf("docker_build_failed")
@@ -136,8 +157,21 @@ func calculateInstanceExpiration(dockerConfig models.DockerConfig) time.Time {
 	return time.Now().Add(24 * time.Hour)
 }
 
-// createInstanceRecord creates database record for new instance
-func createInstanceRecord(containerName string, user models.User, challenge models.Challenge, ports []int, expiresAt time.Time) (*models.Instance, error) {
+// buildInstanceEnv returns the environment injected into a team instance (dynamic flag)
+func buildInstanceEnv(challenge models.Challenge, teamID uint) (map[string]string, error) {
+	if !challenge.DynamicFlag {
+		return nil, nil
+	}
+	flag, err := utils.GetOrCreateDynamicFlag(teamID, challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	return utils.DynamicFlagEnv(flag), nil
+}
+
+// createInstanceRecord creates database record for new instance, failing with utils.ErrWorkerFull when its worker
+// filled up since it was selected
+func createInstanceRecord(containerName string, user models.User, challenge models.Challenge, ports []int, expiresAt time.Time, workerID *uint, status string) (*models.Instance, error) {
 	ports64 := make(pq.Int64Array, len(ports))
 	for i, p := range ports {
 		ports64[i] = int64(p)
@@ -151,23 +185,51 @@ func createInstanceRecord(containerName string, user models.User, challenge mode
 		Ports:       ports64,
 		CreatedAt:   time.Now(),
 		ExpiresAt:   expiresAt,
-		Status:      "running",
+		Status:      status,
+		WorkerID:    workerID,
 	}
 
-	if err := config.DB.Create(&instance).Error; err != nil {
+	if err := utils.CreateInstanceOnWorker(&instance); err != nil {
 		return nil, err
 	}
 
 	return &instance, nil
 }
 
+// respondInstanceRecordError reports a failed instance record creation, a full worker is a temporary unavailability
+// and a duplicate means the team already has the instance
+func respondInstanceRecordError(c *gin.Context, err error) {
+	if errors.Is(err, utils.ErrWorkerFull) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "no_worker_available",
+			"message": msgDockerUnavailable,
+		})
+		return
+	}
+	// The unique team/challenge index rejects a start racing another one
+	if strings.Contains(err.Error(), "duplicate key") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "instance_already_running"})
+		return
+	}
+	debug.Log("Failed to create instance record: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "instance_create_failed"})
+}
+
+// instancePublicIP returns the address players use to reach an instance
+func instancePublicIP(challenge models.Challenge, workerID *uint) string {
+	if challenge.ChallengeType != nil && challenge.ChallengeType.Name == "vm" {
+		return utils.LibvirtPublicIP()
+	}
+	return utils.WorkerPublicIP(workerID)
+}
+
 // formatConnectionInfo formats connection strings with IP and ports
-func formatConnectionInfo(challenge models.Challenge, ports []int) []string {
+func formatConnectionInfo(challenge models.Challenge, ports []int, workerID *uint) []string {
 	if len(challenge.ConnectionInfo) == 0 {
 		return nil
 	}
 
-	ip := os.Getenv("PTA_DOCKER_WORKER_IP")
+	ip := instancePublicIP(challenge, workerID)
 	if ip == "" {
 		ip = "worker-ip"
 	}
@@ -196,7 +258,7 @@ func broadcastInstanceStart(instance *models.Instance, user models.User, challen
 		return
 	}
 
-	connectionInfo := formatConnectionInfo(challenge, ports)
+	connectionInfo := formatConnectionInfo(challenge, ports, instance.WorkerID)
 
 	type InstanceEvent struct {
 		Event          string    `json:"event"`
@@ -255,12 +317,18 @@ func BuildChallengeImage(c *gin.Context) {
 		return
 	}
 
-	// Build the Docker image using the temporary directory as the source
-	_, err := utils.BuildDockerImage(challenge.Slug, tmpDir)
-	if err != nil {
-		utils.InternalServerError(c, err.Error())
+	// Build the Docker image on every docker host using the temporary directory as the source
+	targets := utils.ListDockerTargets()
+	if len(targets) == 0 {
+		utils.ServiceUnavailableError(c, errDockerUnavailable)
 		return
 	}
+	for _, target := range targets {
+		if _, err := utils.BuildDockerImage(target.Client, challenge.Slug, tmpDir); err != nil {
+			utils.InternalServerError(c, fmt.Sprintf("%s: %v", target.Name, err))
+			return
+		}
+	}
 
 	utils.OKResponse(c, gin.H{"message": fmt.Sprintf("Successfully built image for challenge %s", challenge.Slug)})
 }
@@ -359,7 +427,13 @@ func KillChallengeInstance(c *gin.Context) {
 
 	// Stop the Docker container
 	debug.Log("Stopping Docker container: %s", instance.Container)
-	if err := utils.StopDockerInstance(instance.Container); err != nil {
+	cli, err := utils.DockerClientFor(instance.WorkerID)
+	if err != nil {
+		debug.Log("Docker host unavailable for instance %s: %v", instance.Container, err)
+		utils.InternalServerError(c, "failed_to_stop_instance")
+		return
+	}
+	if err := utils.StopDockerInstance(cli, instance.Container); err != nil {
 		debug.Log("Error stopping Docker instance: %v", err)
 		utils.InternalServerError(c, "failed_to_stop_instance")
 		return
@@ -374,6 +448,7 @@ func KillChallengeInstance(c *gin.Context) {
 		utils.InternalServerError(c, "failed_to_update_instance")
 		return
 	}
+	syncTeamFirewall(instance.TeamID)
 	debug.Log("Instance status updated successfully")
 
 	// Record cooldown immediately before kill to prevent rapid restarts
@@ -459,20 +534,6 @@ func StartDockerChallengeInstance(c *gin.Context) {
 		return
 	}
 
-	// Ensure image is built
-	imageName, err := ensureImageBuiltOrBuild(challenge)
-	if err != nil {
-		if err.Error() == errDockerUnavailable {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error":   errDockerUnavailable,
-				"message": msgDockerUnavailable,
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-		return
-	}
-
 	// Get user and validate
 	userID, ok := c.Get("user_id")
 	if !ok {
@@ -507,27 +568,57 @@ func StartDockerChallengeInstance(c *gin.Context) {
 		return
 	}
 
-	// Ensure Docker connection
-	if err := utils.EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker connection failed: %v", err)
+	// Pick the docker host running the instance
+	workerID, cli, err := selectInstanceWorker(challenge)
+	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error":   errDockerUnavailable,
+			"error":   err.Error(),
 			"message": msgDockerUnavailable,
 		})
 		return
 	}
 
-	// Start container
-	containerName, err := utils.StartDockerInstance(imageName, int(*user.TeamID), int(user.ID), internalPorts, ports)
+	// Ensure image is built on that host
+	imageName, err := ensureImageBuiltOrBuild(cli, challenge)
 	if err != nil {
-		debug.Log("Error starting Docker instance: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Calculate expiration and create instance record
+	// Generate the team flag if the challenge uses dynamic flags
+	instanceEnv, err := buildInstanceEnv(challenge, *user.TeamID)
+	if err != nil {
+		debug.Log("Error preparing dynamic flag: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "dynamic_flag_failed"})
+		return
+	}
+
+	// Create the instance record first so it holds its worker slot while the container starts
 	expiresAt := calculateInstanceExpiration(dockerConfig)
-	instance, err := createInstanceRecord(containerName, user, challenge, ports, expiresAt)
+	instance, err := createInstanceRecord("", user, challenge, ports, expiresAt, workerID, "running")
+	if err != nil {
+		respondInstanceRecordError(c, err)
+		return
+	}
+
+	// Start container
+	containerName, err := utils.StartDockerInstance(cli, imageName, int(*user.TeamID), int(user.ID), internalPorts, ports, instanceEnv)
+	if err != nil {
+		debug.Log("Error starting Docker instance: %v", err)
+		deleteInstanceRecord(instance)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	instance.Container = containerName
+	if err := config.DB.Model(instance).Update("container", containerName).Error; err != nil {
+		debug.Log("Failed to record container %s: %v", containerName, err)
+		if stopErr := utils.StopWorkerDockerInstance(workerID, containerName); stopErr != nil {
+			debug.Log("Failed to stop unrecorded container %s: %v", containerName, stopErr)
+		}
+		deleteInstanceRecord(instance)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "instance_create_failed"})
+		return
+	}
 	// subnet, _, err := utils.GetTeamSubnet(int(*user.TeamID))
 	teamIPs, ipErr := utils.GetTeamIPs(*user.TeamID)
 	// teamPorts, portsErr := utils.GetTeamMappedPorts(*user.TeamID)
@@ -538,10 +629,6 @@ func StartDockerChallengeInstance(c *gin.Context) {
 			debug.Log("Could not push team firewall config: %v", err)
 		}
 	}
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "instance_create_failed"})
-		return
-	}
 
 	// Broadcast to team
 	broadcastInstanceStart(instance, user, challenge, ports)
@@ -602,13 +689,13 @@ func validateComposeInstancePreconditions(c *gin.Context, userID interface{}, ch
 }
 
 // prepareComposeProject creates and configures the compose project
-func prepareComposeProject(challengeSlug string, teamID, userID int) (interface{}, error) {
+func prepareComposeProject(cli *client.Client, challengeSlug string, teamID, userID int, env map[string]string) (interface{}, error) {
 	compose, err := utils.GetComposeFile(challengeSlug)
 	if err != nil {
 		return nil, fmt.Errorf("get_compose_failed: %w", err)
 	}
 
-	project, err := utils.CreateComposeProject(challengeSlug, teamID, userID, compose)
+	project, err := utils.CreateComposeProject(cli, challengeSlug, teamID, userID, compose, env)
 	if err != nil {
 		return nil, fmt.Errorf("create_compose_failed: %w", err)
 	}
@@ -665,18 +752,26 @@ func StartComposeChallengeInstance(c *gin.Context) {
 		return
 	}
 
-	// Ensure Docker is available
-	if err := utils.EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker connection failed: %v", err)
+	// Pick the docker host running the instance
+	workerID, cli, err := selectInstanceWorker(challenge)
+	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error":   "docker_unavailable",
+			"error":   err.Error(),
 			"message": "Docker service unavailable.",
 		})
 		return
 	}
 
+	// Generate the team flag if the challenge uses dynamic flags
+	instanceEnv, err := buildInstanceEnv(challenge, *user.TeamID)
+	if err != nil {
+		debug.Log("Error preparing dynamic flag: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "dynamic_flag_failed"})
+		return
+	}
+
 	// Prepare compose project
-	projectInterface, err := prepareComposeProject(challenge.Slug, int(*user.TeamID), int(user.ID))
+	projectInterface, err := prepareComposeProject(cli, challenge.Slug, int(*user.TeamID), int(user.ID), instanceEnv)
 	if err != nil {
 		debug.Log("Compose preparation failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -694,9 +789,9 @@ func StartComposeChallengeInstance(c *gin.Context) {
 	// Calculate expiration and create instance record first
 	expiresAt := calculateInstanceExpiration(dockerConfig)
 	projectName := fmt.Sprintf("%s_%d_%d", challenge.Slug, *user.TeamID, user.ID)
-	instance, err := createInstanceRecord(projectName, *user, challenge, ports, expiresAt)
+	instance, err := createInstanceRecord(projectName, *user, challenge, ports, expiresAt, workerID, "running")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInstanceRecordError(c, err)
 		return
 	}
 
@@ -710,10 +805,10 @@ func StartComposeChallengeInstance(c *gin.Context) {
 
 	// Start the compose instance asynchronously (takes 10+ seconds)
 	go func() {
-		if err := utils.StartComposeInstance(projectInterface.(*types.Project), int(*user.TeamID)); err != nil {
+		if err := utils.StartComposeInstance(cli, projectInterface.(*types.Project), int(*user.TeamID)); err != nil {
 			debug.Log("StartComposeInstance failed: %v", err)
 			// Clean up the instance record on failure
-			config.DB.Delete(&instance)
+			deleteInstanceRecord(instance)
 			return
 		}
 		teamIPs, ipErr := utils.GetTeamIPs(*user.TeamID)
@@ -781,31 +876,59 @@ func recordInstanceCooldown(instance *models.Instance) {
 	}
 }
 
+// deleteInstanceRecord removes the record of a stopped instance, then re-applies the team firewall
+// so that the ports it frees are no longer filtered
+func deleteInstanceRecord(instance *models.Instance) error {
+	if err := config.DB.Delete(instance).Error; err != nil {
+		return err
+	}
+	syncTeamFirewall(instance.TeamID)
+	return nil
+}
+
+// syncTeamFirewall re-applies the team firewall after one of its instances stopped
+func syncTeamFirewall(teamID uint) {
+	if err := utils.SyncTeamFirewall(teamID); err != nil {
+		debug.Log("Could not sync team %d firewall config: %v", teamID, err)
+	}
+}
+
 // stopInstanceByType stops the instance based on challenge type
 func stopInstanceByType(challenge *models.Challenge, instance *models.Instance) error {
 	switch challenge.ChallengeType.Name {
 	case "docker":
 		go func() {
-			if err := utils.StopDockerInstance(instance.Container); err != nil {
+			if err := utils.StopWorkerDockerInstance(instance.WorkerID, instance.Container); err != nil {
 				debug.Log("Failed to stop Docker instance: %v", err)
 			}
-			if err := config.DB.Delete(instance).Error; err != nil {
+			if err := deleteInstanceRecord(instance); err != nil {
 				debug.Log("Failed to delete instance from DB: %v", err)
 			}
 		}()
 		return nil
 
 	case "compose":
-		if err := utils.StopComposeInstance(instance.Container); err != nil {
+		if err := utils.StopWorkerComposeInstance(instance.WorkerID, instance.Container); err != nil {
 			debug.Log("Failed to stop Compose instance: %v", err)
 			return fmt.Errorf("compose_stop_failed")
 		}
-		if err := config.DB.Delete(instance).Error; err != nil {
+		if err := deleteInstanceRecord(instance); err != nil {
 			debug.Log("Failed to delete Compose instance from DB: %v", err)
 			return fmt.Errorf("db_delete_failed")
 		}
 		return nil
 
+	case "vm":
+		if err := utils.StopVMInstance(instance.Container); err != nil {
+			debug.Log("Failed to stop VM instance: %v", err)
+			return fmt.Errorf("vm_stop_failed")
+		}
+		if err := deleteInstanceRecord(instance); err != nil {
+			debug.Log("Failed to delete VM instance from DB: %v", err)
+			return fmt.Errorf("db_delete_failed")
+		}
+		return nil
+
 	default:
 		debug.Log("Unknown challenge type: %s", challenge.ChallengeType.Name)
 		return fmt.Errorf("unknown_challenge_type")
@@ -816,10 +939,10 @@ func stopInstanceByTypeAsync(challenge *models.Challenge, instance *models.Insta
 	switch challenge.ChallengeType.Name {
 	case "docker":
 		go func() {
-			if err := utils.StopDockerInstance(instance.Container); err != nil {
+			if err := utils.StopWorkerDockerInstance(instance.WorkerID, instance.Container); err != nil {
 				debug.Log("Failed to stop Docker instance: %v", err)
 			}
-			if err := config.DB.Delete(instance).Error; err != nil {
+			if err := deleteInstanceRecord(instance); err != nil {
 				debug.Log("Failed to delete instance from DB: %v", err)
 			}
 			// Broadcast after actual stop
@@ -830,11 +953,11 @@ func stopInstanceByTypeAsync(challenge *models.Challenge, instance *models.Insta
 	case "compose":
 		// Stop compose asynchronously to avoid request timeout
 		go func() {
-			if err := utils.StopComposeInstance(instance.Container); err != nil {
+			if err := utils.StopWorkerComposeInstance(instance.WorkerID, instance.Container); err != nil {
 				debug.Log("Failed to stop Compose instance: %v", err)
 				return
 			}
-			if err := config.DB.Delete(instance).Error; err != nil {
+			if err := deleteInstanceRecord(instance); err != nil {
 				debug.Log("Failed to delete Compose instance from DB: %v", err)
 				return
 			}
@@ -844,12 +967,39 @@ func stopInstanceByTypeAsync(challenge *models.Challenge, instance *models.Insta
 		}()
 		return nil
 
+	case "vm":
+		go func() {
+			if err := utils.StopVMInstance(instance.Container); err != nil {
+				debug.Log("Failed to stop VM instance: %v", err)
+				return
+			}
+			if err := deleteInstanceRecord(instance); err != nil {
+				debug.Log("Failed to delete VM instance from DB: %v", err)
+				return
+			}
+			broadcastInstanceStop(userID, instance)
+			debug.Log("VM instance stopped and broadcast sent: %s", instance.Container)
+		}()
+		return nil
+
 	default:
 		debug.Log("Unknown challenge type: %s", challenge.ChallengeType.Name)
 		return fmt.Errorf("unknown_challenge_type")
 	}
 }
 
+// stopInstanceResources stops the container, compose project or VM backing an instance
+func stopInstanceResources(typeName string, instance *models.Instance) error {
+	switch typeName {
+	case "compose":
+		return utils.StopWorkerComposeInstance(instance.WorkerID, instance.Container)
+	case "vm":
+		return utils.StopVMInstance(instance.Container)
+	default:
+		return utils.StopWorkerDockerInstance(instance.WorkerID, instance.Container)
+	}
+}
+
 // broadcastInstanceStop sends WebSocket notification about instance stop
 func broadcastInstanceStop(userID interface{}, instance *models.Instance) {
 	if utils.WebSocketHub == nil {
@@ -974,6 +1124,7 @@ func checkAndUpdateExpiredInstance(instance *models.Instance) bool {
 	if isExpired && instance.Status == "running" {
 		instance.Status = "expired"
 		config.DB.Save(instance)
+		syncTeamFirewall(instance.TeamID)
 	}
 	return isExpired
 }
@@ -986,7 +1137,7 @@ func buildConnectionInfoForInstance(challenge *models.Challenge, instance *model
 		return connectionInfo
 	}
 
-	ip := os.Getenv("PTA_DOCKER_WORKER_IP")
+	ip := instancePublicIP(*challenge, instance.WorkerID)
 	if ip == "" {
 		ip = "instance-ip"
 	}
@@ -1060,24 +1211,37 @@ func GetInstanceStatus(c *gin.Context) {
 
 	// Load challenge
 	var challenge models.Challenge
-	if err := config.DB.First(&challenge, challengeID).Error; err != nil {
+	if err := config.DB.Preload("ChallengeType").First(&challenge, challengeID).Error; err != nil {
 		utils.InternalServerError(c, "challenge_not_found")
 		return
 	}
 
+	// The domain of a VM can stop on its own
+	vmState := refreshVMInstanceState(&challenge, instance)
+
 	// Build connection info
 	connectionInfo := buildConnectionInfoForInstance(&challenge, instance)
 
-	utils.OKResponse(c, gin.H{
+	response := gin.H{
 		"has_instance":    true,
 		"status":          instance.Status,
 		"created_at":      instance.CreatedAt,
 		"expires_at":      instance.ExpiresAt,
 		"is_expired":      isExpired,
+		"extensions":      instance.Extensions,
 		"container":       instance.Container,
 		"ports":           instance.Ports,
 		"connection_info": connectionInfo,
-	})
+	}
+	if vmState != "" {
+		response["vm_state"] = vmState
+	}
+	var dockerConfig models.DockerConfig
+	if instance.Status == "running" && config.DB.First(&dockerConfig).Error == nil {
+		_, err := computeExtendedExpiration(instance, dockerConfig)
+		response["can_extend"] = err == nil
+	}
+	utils.OKResponse(c, response)
 }
 
 func StopDockerChallengeInstance(c *gin.Context) {
@@ -1126,10 +1290,10 @@ func StopDockerChallengeInstance(c *gin.Context) {
 	}
 
 	go func() {
-		if err := utils.StopDockerInstance(instance.Container); err != nil {
+		if err := utils.StopWorkerDockerInstance(instance.WorkerID, instance.Container); err != nil {
 			debug.Log("Failed to stop Docker instance: %v", err)
 		}
-		if err := config.DB.Delete(&instance).Error; err != nil {
+		if err := deleteInstanceRecord(&instance); err != nil {
 			debug.Log("Failed to delete instance from DB: %v", err)
 		}
 		// Broadcast after actual stop - same pattern as Compose challenges
@@ -1189,11 +1353,11 @@ func StopComposeChallengeInstance(c *gin.Context) {
 	}
 
 	go func() {
-		if err := utils.StopComposeInstance(instance.Container); err != nil {
+		if err := utils.StopWorkerComposeInstance(instance.WorkerID, instance.Container); err != nil {
 			debug.Log("Failed to stop Compose instance: %v", err)
 			return
 		}
-		if err := config.DB.Delete(instance).Error; err != nil {
+		if err := deleteInstanceRecord(instance); err != nil {
 			debug.Log("Failed to delete Compose instance from DB: %v", err)
 			return
 		}
@@ -1206,3 +1370,151 @@ func StopComposeChallengeInstance(c *gin.Context) {
 		"container": instance.Container,
 	})
 }
+
+// computeExtendedExpiration returns the new expiry of an instance or an error code when it cannot be extended
+func computeExtendedExpiration(instance *models.Instance, dockerConfig models.DockerConfig) (time.Time, error) {
+	if dockerConfig.InstanceExtensionMinutes <= 0 {
+		return time.Time{}, fmt.Errorf("extension_disabled")
+	}
+	if dockerConfig.InstanceMaxExtensions > 0 && instance.Extensions >= dockerConfig.InstanceMaxExtensions {
+		return time.Time{}, fmt.Errorf("max_extensions_reached")
+	}
+
+	newExpiresAt := instance.ExpiresAt.Add(time.Duration(dockerConfig.InstanceExtensionMinutes) * time.Minute)
+	if dockerConfig.InstanceMaxLifetime > 0 {
+		maxExpiresAt := instance.CreatedAt.Add(time.Duration(dockerConfig.InstanceMaxLifetime) * time.Minute)
+		if newExpiresAt.After(maxExpiresAt) {
+			newExpiresAt = maxExpiresAt
+		}
+		if !newExpiresAt.After(instance.ExpiresAt) {
+			return time.Time{}, fmt.Errorf("max_lifetime_reached")
+		}
+	}
+	return newExpiresAt, nil
+}
+
+// broadcastInstanceExtended sends the new expiry of an instance to the whole team
+func broadcastInstanceExtended(instance *models.Instance, user *models.User) {
+	if utils.WebSocketHub == nil {
+		return
+	}
+
+	type InstanceEvent struct {
+		Event       string    `json:"event"`
+		TeamID      uint      `json:"teamId"`
+		UserID      uint      `json:"userId"`
+		Username    string    `json:"username"`
+		ChallengeID uint      `json:"challengeId"`
+		Status      string    `json:"status"`
+		CreatedAt   time.Time `json:"createdAt"`
+		ExpiresAt   time.Time `json:"expiresAt"`
+		Extensions  int       `json:"extensions"`
+		UpdatedAt   time.Time `json:"updatedAt"`
+	}
+
+	event := InstanceEvent{
+		Event:       "instance_update",
+		TeamID:      instance.TeamID,
+		UserID:      user.ID,
+		Username:    user.Username,
+		ChallengeID: instance.ChallengeID,
+		Status:      instance.Status,
+		CreatedAt:   instance.CreatedAt,
+		ExpiresAt:   instance.ExpiresAt,
+		Extensions:  instance.Extensions,
+		UpdatedAt:   time.Now().UTC(),
+	}
+
+	if payload, err := json.Marshal(event); err == nil {
+		utils.WebSocketHub.SendToTeam(instance.TeamID, payload)
+	}
+}
+
+// ExtendChallengeInstance pushes the expiry of the team instance forward by the configured step
+func ExtendChallengeInstance(c *gin.Context) {
+	challengeID := c.Param("id")
+	userID, ok := c.Get("user_id")
+	if !ok {
+		utils.UnauthorizedError(c, "unauthorized")
+		return
+	}
+
+	user, err := getUserAndTeamForStatus(c, userID)
+	if err != nil {
+		if err.Error() == "no_team" {
+			utils.ForbiddenError(c, "team_required")
+		} else {
+			utils.NotFoundError(c, "user_not_found")
+		}
+		return
+	}
+
+	instance, err := getInstanceForTeam(user.Team.ID, challengeID)
+	if err != nil {
+		if err.Error() == "no_instance" {
+			utils.NotFoundError(c, "instance_not_found")
+			return
+		}
+		debug.Log("Database error when extending instance: %v", err)
+		utils.InternalServerError(c, "database_error")
+		return
+	}
+
+	if checkAndUpdateExpiredInstance(instance) || instance.Status != "running" {
+		utils.BadRequestError(c, "instance_not_running")
+		return
+	}
+
+	var dockerConfig models.DockerConfig
+	if err := config.DB.First(&dockerConfig).Error; err != nil {
+		utils.InternalServerError(c, "docker_config_not_found")
+		return
+	}
+
+	newExpiresAt, err := computeExtendedExpiration(instance, dockerConfig)
+	if err != nil {
+		utils.ForbiddenError(c, err.Error())
+		return
+	}
+
+	// The counter guards the update, so concurrent extends cannot both pass the limit check
+	query := config.DB.Model(&models.Instance{}).Where("id = ? AND extensions = ?", instance.ID, instance.Extensions)
+	if dockerConfig.InstanceMaxExtensions > 0 {
+		query = query.Where("extensions < ?", dockerConfig.InstanceMaxExtensions)
+	}
+	result := query.Updates(map[string]interface{}{
+		"expires_at": newExpiresAt,
+		"extensions": gorm.Expr("extensions + 1"),
+	})
+	if result.Error != nil {
+		utils.InternalServerError(c, "instance_update_failed")
+		return
+	}
+	if result.RowsAffected == 0 {
+		var current models.Instance
+		if err := config.DB.Select("extensions").First(&current, instance.ID).Error; err == nil &&
+			dockerConfig.InstanceMaxExtensions > 0 && current.Extensions >= dockerConfig.InstanceMaxExtensions {
+			utils.ForbiddenError(c, "max_extensions_reached")
+			return
+		}
+		utils.ConflictError(c, "instance_extension_conflict")
+		return
+	}
+
+	instance.ExpiresAt = newExpiresAt
+	instance.Extensions++
+
+	broadcastInstanceExtended(instance, user)
+
+	remaining := -1
+	if dockerConfig.InstanceMaxExtensions > 0 {
+		remaining = dockerConfig.InstanceMaxExtensions - instance.Extensions
+	}
+
+	utils.OKResponse(c, gin.H{
+		"message":              "instance_extended",
+		"expires_at":           instance.ExpiresAt,
+		"extensions":           instance.Extensions,
+		"extensions_remaining": remaining,
+	})
+}
@@ -56,7 +56,25 @@ func (h *composeChallengeHandler) GetStatus(c *gin.Context, challenge *models.Ch
 	return nil
 }
 
+type vmChallengeHandler struct{}
+
+func (h *vmChallengeHandler) Start(c *gin.Context, challenge *models.Challenge) error {
+	StartVMChallengeInstance(c)
+	return nil
+}
+
+func (h *vmChallengeHandler) Stop(c *gin.Context, challenge *models.Challenge) error {
+	StopVMChallengeInstance(c)
+	return nil
+}
+
+func (h *vmChallengeHandler) GetStatus(c *gin.Context, challenge *models.Challenge) error {
+	GetInstanceStatus(c)
+	return nil
+}
+
 func init() {
 	RegisterChallengeHandler("docker", &dockerChallengeHandler{})
 	RegisterChallengeHandler("compose", &composeChallengeHandler{})
+	RegisterChallengeHandler("vm", &vmChallengeHandler{})
 }
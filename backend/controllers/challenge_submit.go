@@ -57,7 +57,7 @@ func validateStandardFlag(submittedValue string, flags []models.Flag) bool {
 	}
 
 	for _, flag := range flags {
-		if !utils.IsGeoFlag(flag.Value) && flag.Value == utils.HashFlag(submittedValue) {
+		if utils.MatchFlag(flag, submittedValue) {
 			return true
 		}
 	}
@@ -114,7 +114,14 @@ func validateGeoSpec(inputRaw map[string]interface{}, challengeID uint) bool {
 }
 
 // validateFlagSubmission performs all flag validation checks
-func validateFlagSubmission(inputRaw map[string]interface{}, challenge models.Challenge, submittedValue string) bool {
+func validateFlagSubmission(inputRaw map[string]interface{}, challenge models.Challenge, submittedValue string, teamID *uint) bool {
+	// Check the per-team dynamic flag, only accepted from the team it was generated for
+	if challenge.DynamicFlag && teamID != nil {
+		if utils.ValidateDynamicFlag(*teamID, challenge.ID, submittedValue) {
+			return true
+		}
+	}
+
 	// Check standard flags
 	if validateStandardFlag(submittedValue, challenge.Flags) {
 		return true
@@ -128,7 +135,7 @@ func validateFlagSubmission(inputRaw map[string]interface{}, challenge models.Ch
 	// Check alternative flag format from inputRaw
 	if v, ok := inputRaw["flag"].(string); ok {
 		for _, flag := range challenge.Flags {
-			if !utils.IsGeoFlag(flag.Value) && flag.Value == utils.HashFlag(v) {
+			if utils.MatchFlag(flag, v) {
 				return true
 			}
 		}
@@ -216,19 +223,23 @@ func broadcastTeamSolve(user *models.User, challenge models.Challenge, totalPoin
 // stopInstanceOnSolve stops the running instance for a team when challenge is solved
 func stopInstanceOnSolve(teamID uint, challengeID uint, actorID uint, actorName string) {
 	var instance models.Instance
-	if err := config.DB.Where(queryTeamAndChallengeID, teamID, challengeID).First(&instance).Error; err != nil {
+	if err := config.DB.Preload("Challenge.ChallengeType").Where(queryTeamAndChallengeID, teamID, challengeID).First(&instance).Error; err != nil {
 		return
 	}
 
-	// Try stopping the container
+	// Try stopping the container or VM
 	if instance.Container != "" {
-		if err := utils.StopDockerInstance(instance.Container); err != nil {
-			debug.Log("Failed to stop Docker instance on solve: %v", err)
+		typeName := ""
+		if instance.Challenge.ChallengeType != nil {
+			typeName = instance.Challenge.ChallengeType.Name
+		}
+		if err := stopInstanceResources(typeName, &instance); err != nil {
+			debug.Log("Failed to stop instance on solve: %v", err)
 		}
 	}
 
 	// Remove instance record to free the slot
-	if err := config.DB.Delete(&instance).Error; err != nil {
+	if err := deleteInstanceRecord(&instance); err != nil {
 		debug.Log("Failed to delete instance on solve: %v", err)
 	}
 
@@ -296,12 +307,19 @@ func handleCorrectSubmission(c *gin.Context, user *models.User, challenge models
 	var position int64
 	config.DB.Model(&models.Solve{}).Where(queryChallengeID, challenge.ID).Count(&position)
 
-	debug.Log("FirstBlood: Challenge %d, Position %d, EnableFirstBlood: %v, Bonuses count: %d",
-		challenge.ID, position, challenge.EnableFirstBlood, len(challenge.FirstBloodBonuses))
+	// First blood rank, counted inside the team bracket when enabled
+	bloodPosition, err := utils.FirstBloodPosition(challenge.ID, user.Team.ID)
+	if err != nil {
+		bloodPosition = position
+	}
+
+	debug.Log("FirstBlood: Challenge %d, Position %d, Bracket position %d, EnableFirstBlood: %v, Bonuses count: %d",
+		challenge.ID, position, bloodPosition, challenge.EnableFirstBlood, len(challenge.FirstBloodBonuses))
 
 	// Calculate first blood bonus
-	firstBloodBonus := calculateFirstBloodBonus(challenge, position)
-	totalPoints := challenge.Points + firstBloodBonus
+	firstBloodBonus := calculateFirstBloodBonus(challenge, bloodPosition)
+	decayedPoints := utils.NewDecay().CalculateDecayedPoints(&challenge, int(position), time.Now())
+	totalPoints := decayedPoints + firstBloodBonus
 
 	// Create solve record
 	var solve models.Solve
@@ -317,7 +335,12 @@ func handleCorrectSubmission(c *gin.Context, user *models.User, challenge models
 	}
 
 	// Create FirstBlood entry if applicable
-	createFirstBloodEntry(challenge, user, position, firstBloodBonus)
+	createFirstBloodEntry(challenge, user, bloodPosition, firstBloodBonus)
+
+	// The challenge value changed for every team that solved it
+	if err := utils.RefreshChallengeScores(challenge.ID); err != nil {
+		debug.Log("Failed to refresh scoreboard for challenge %d: %v", challenge.ID, err)
+	}
 
 	// Broadcast team solve event
 	broadcastTeamSolve(user, challenge, totalPoints)
@@ -432,7 +455,7 @@ func SubmitChallenge(c *gin.Context) {
 	}
 
 	// Validate flag
-	isCorrect := validateFlagSubmission(inputRaw, challenge, submittedValue)
+	isCorrect := validateFlagSubmission(inputRaw, challenge, submittedValue, user.TeamID)
 
 	if isCorrect {
 		submittedValue = utils.HashFlag(submittedValue)
@@ -454,6 +477,7 @@ func SubmitChallenge(c *gin.Context) {
 	if isCorrect {
 		handleCorrectSubmission(c, user, challenge)
 	} else {
+		detectFlagSharing(user, challenge, submission, submittedValue)
 		handleIncorrectSubmission(c, challenge)
 	}
 }
@@ -473,6 +497,7 @@ func GetChallengeSolves(c *gin.Context) {
 	var solves []models.Solve
 	result = config.DB.
 		Preload("Team").
+		Scopes(visibleSolves(c)).
 		Where("challenge_id = ?", challenge.ID).
 		Order("created_at ASC").
 		Find(&solves)
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+const errLibvirtUnavailable = "libvirt_unavailable"
+
+// StartVMChallengeInstance boots a per-team virtual machine for a vm challenge
+func StartVMChallengeInstance(c *gin.Context) {
+	id := c.Param("id")
+	debug.Log("Starting VM instance for challenge ID: %s", id)
+
+	var challenge models.Challenge
+	if result := config.DB.Preload("ChallengeType").First(&challenge, id); result.Error != nil {
+		debug.Log(errChallengeNotFoundLog, id, result.Error)
+		c.JSON(http.StatusNotFound, gin.H{"error": "challenge_not_found"})
+		return
+	}
+
+	var spec models.VMSpec
+	if err := config.DB.Where("challenge_id = ?", challenge.ID).First(&spec).Error; err != nil {
+		debug.Log("VM spec not found for challenge %d: %v", challenge.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "vm_spec_not_found"})
+		return
+	}
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var dockerConfig models.DockerConfig
+	if err := config.DB.First(&dockerConfig).Error; err != nil {
+		debug.Log("Docker config not found: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "docker_config_not_found"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Team").First(&user, userID).Error; err != nil {
+		debug.Log("User not found with ID %v: %v", userID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "user_not_found"})
+		return
+	}
+
+	// Cooldown, expiry and limits are shared with docker instances
+	if !validateInstanceStartPreconditions(c, user, challenge, dockerConfig) {
+		return
+	}
+
+	if len(challenge.Ports) == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "no_ports_defined_for_challenge"})
+		return
+	}
+	internalPorts := make([]int, len(challenge.Ports))
+	for i, p := range challenge.Ports {
+		internalPorts[i] = int(p)
+	}
+
+	// The ports are forwarded by the libvirt host, not by the backend one
+	ports, err := utils.ReserveVMPorts(len(internalPorts))
+	if err != nil {
+		debug.Log("No free VM port: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "no_free_ports"})
+		return
+	}
+	defer utils.ReleaseVMPorts(ports)
+
+	if err := utils.EnsureLibvirtConnected(); err != nil {
+		debug.Log("Libvirt connection failed: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   errLibvirtUnavailable,
+			"message": msgDockerUnavailable,
+		})
+		return
+	}
+
+	instanceEnv, err := buildInstanceEnv(challenge, *user.TeamID)
+	if err != nil {
+		debug.Log("Error preparing dynamic flag: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "dynamic_flag_failed"})
+		return
+	}
+
+	// Reserve the instance row before booting: the unique team/challenge index makes a concurrent start
+	// fail here instead of replacing the disk of the VM being started. It stays building until the
+	// domain exists, so the state refresh does not reap it meanwhile.
+	vmName := utils.VMInstanceName(challenge.Slug, int(*user.TeamID), int(user.ID))
+	expiresAt := calculateInstanceExpiration(dockerConfig)
+	instance, err := createInstanceRecord(vmName, user, challenge, ports, expiresAt, nil, "building")
+	if err != nil {
+		respondInstanceRecordError(c, err)
+		return
+	}
+
+	if _, err := utils.StartVMInstance(challenge.Slug, spec, int(*user.TeamID), int(user.ID), internalPorts, ports, instanceEnv); err != nil {
+		debug.Log("Error starting VM instance: %v", err)
+		if delErr := deleteInstanceRecord(instance); delErr != nil {
+			debug.Log("Failed to delete the record of VM %s: %v", vmName, delErr)
+		}
+		if errors.Is(err, utils.ErrVMStartInProgress) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "instance_already_running"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "vm_start_failed"})
+		return
+	}
+
+	instance.Status = "running"
+	if err := config.DB.Model(instance).Update("status", instance.Status).Error; err != nil {
+		debug.Log("Failed to mark VM %s as running: %v", vmName, err)
+		if stopErr := utils.StopVMInstance(vmName); stopErr != nil {
+			debug.Log("Failed to clean up VM %s: %v", vmName, stopErr)
+		}
+		deleteInstanceRecord(instance)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "instance_create_failed"})
+		return
+	}
+
+	broadcastInstanceStart(instance, user, challenge, ports)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "instance_started",
+		"vm_name":    vmName,
+		"expires_at": expiresAt,
+		"ports":      ports,
+	})
+}
+
+// StopVMChallengeInstance destroys the team virtual machine of a vm challenge
+func StopVMChallengeInstance(c *gin.Context) {
+	challengeID := c.Param("id")
+	userID, _ := c.Get("user_id")
+
+	_, instance, err := getUserAndInstance(c, challengeID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case "forbidden":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	var challenge models.Challenge
+	if err := config.DB.Preload("ChallengeType").First(&challenge, instance.ChallengeID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "challenge_not_found"})
+		return
+	}
+
+	recordInstanceCooldown(instance)
+
+	if err := stopInstanceByTypeAsync(&challenge, instance, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "instance_stopped",
+		"container": instance.Container,
+	})
+}
+
+// refreshVMInstanceState reports the libvirt state of a running VM instance and releases the
+// instance when its domain is gone (crash, shutdown from the guest)
+func refreshVMInstanceState(challenge *models.Challenge, instance *models.Instance) string {
+	if challenge.ChallengeType.Name != "vm" || instance.Status != "running" {
+		return ""
+	}
+
+	state, err := utils.VMInstanceState(instance.Container)
+	if err != nil && !errors.Is(err, config.ErrDomainNotFound) {
+		debug.Log("Could not get state of VM %s: %v", instance.Container, err)
+		return "unknown"
+	}
+	if err == nil && state == "running" {
+		return state
+	}
+
+	debug.Log("VM %s is no longer running (state: %q)", instance.Container, state)
+	instance.Status = "stopped"
+	// Deletes what is left of the domain and its disk, then frees the slot of the team
+	go func(instance models.Instance) {
+		if err := utils.StopVMInstance(instance.Container); err != nil {
+			debug.Log("Failed to clean up VM %s: %v", instance.Container, err)
+		}
+		if err := deleteInstanceRecord(&instance); err != nil {
+			debug.Log("Failed to delete instance from DB: %v", err)
+		}
+	}(*instance)
+
+	if state == "" {
+		state = "shut off"
+	}
+	return state
+}
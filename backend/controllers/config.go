@@ -10,6 +10,15 @@ import (
 	"github.com/pwnthemall/pwnthemall/backend/utils"
 )
 
+// isCTFTimingKey tells if a config key changes the CTF status or the scoreboard freeze
+func isCTFTimingKey(key string) bool {
+	switch key {
+	case "CTF_START_TIME", "CTF_END_TIME", "SCOREBOARD_FREEZE_TIME", "SCOREBOARD_REVEALED":
+		return true
+	}
+	return false
+}
+
 func GetConfigs(c *gin.Context) {
 	var configs []models.Config
 	result := config.DB.Find(&configs)
@@ -60,7 +69,7 @@ func CreateConfig(c *gin.Context) {
 	}
 
 	// Broadcast CTF status update if timing-related config changed
-	if cfg.Key == "CTF_START_TIME" || cfg.Key == "CTF_END_TIME" {
+	if isCTFTimingKey(cfg.Key) {
 		if utils.UpdatesHub != nil {
 			if payload, err := json.Marshal(gin.H{
 				"event":  "ctf-status",
@@ -108,8 +117,13 @@ func UpdateConfig(c *gin.Context) {
 		config.SynchronizeEnvWithDb()
 	}
 
+	// First blood bonuses move when they switch between global and per-bracket ranks
+	if key == "FIRST_BLOOD_PER_BRACKET" {
+		go refreshBracketScoreboard()
+	}
+
 	// Broadcast CTF status update if timing-related config changed
-	if key == "CTF_START_TIME" || key == "CTF_END_TIME" {
+	if isCTFTimingKey(key) {
 		if utils.UpdatesHub != nil {
 			if payload, err := json.Marshal(gin.H{
 				"event":  "ctf-status",
@@ -149,10 +163,12 @@ func DeleteConfig(c *gin.Context) {
 // GetCTFStatus returns the current CTF timing status
 func GetCTFStatus(c *gin.Context) {
 	status := config.GetCTFStatus()
+	_, frozen := config.GetScoreboardFreezeTime()
 	utils.OKResponse(c, gin.H{
-		"status":     string(status),
-		"is_active":  config.IsCTFActive(),
-		"is_started": config.IsCTFStarted(),
+		"status":            string(status),
+		"is_active":         config.IsCTFActive(),
+		"is_started":        config.IsCTFStarted(),
+		"scoreboard_frozen": frozen,
 	})
 }
 
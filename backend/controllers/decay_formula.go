@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/models"
@@ -39,6 +41,14 @@ func CreateDecayFormula(c *gin.Context) {
 		return
 	}
 
+	if decayFormula.Type == "" {
+		decayFormula.Type = utils.DecayTypeLogarithmic
+	}
+	if err := utils.ValidateDecayFormula(&decayFormula); err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
 	if err := config.DB.Create(&decayFormula).Error; err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
@@ -67,21 +77,37 @@ func UpdateDecayFormula(c *gin.Context) {
 		return
 	}
 
+	if err := utils.ValidateDecayFormula(&decayFormula); err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
 	if err := config.DB.Save(&decayFormula).Error; err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
 	}
 
+	if err := utils.RefreshDecayFormulaScores(decayFormula.ID); err != nil {
+		log.Printf("Failed to refresh scoreboard for decay formula %d: %v", decayFormula.ID, err)
+	}
+
 	utils.OKResponse(c, decayFormula)
 }
 
 func DeleteDecayFormula(c *gin.Context) {
 	id := c.Param("id")
 
+	var challengeIDs []uint
+	config.DB.Model(&models.Challenge{}).Where("decay_formula_id = ?", id).Pluck("id", &challengeIDs)
+
 	if err := config.DB.Delete(&models.DecayFormula{}, id).Error; err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
 	}
 
+	if err := utils.RefreshChallengeScores(challengeIDs...); err != nil {
+		log.Printf("Failed to refresh scoreboard after deleting decay formula %s: %v", id, err)
+	}
+
 	utils.OKResponse(c, gin.H{"message": "Decay formula deleted successfully"})
 }
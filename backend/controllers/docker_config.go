@@ -46,6 +46,9 @@ func UpdateDockerConfig(c *gin.Context) {
 	existingCfg.MaxCpuByInstance = newCfg.MaxCpuByInstance
 	existingCfg.InstanceTimeout = newCfg.InstanceTimeout
 	existingCfg.InstanceCooldownSeconds = newCfg.InstanceCooldownSeconds
+	existingCfg.InstanceExtensionMinutes = newCfg.InstanceExtensionMinutes
+	existingCfg.InstanceMaxExtensions = newCfg.InstanceMaxExtensions
+	existingCfg.InstanceMaxLifetime = newCfg.InstanceMaxLifetime
 
 	if err := config.DB.Save(&existingCfg).Error; err != nil {
 		utils.InternalServerError(c, "Failed to update Docker Configuration")
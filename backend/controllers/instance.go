@@ -51,6 +51,7 @@ func GetAllInstancesAdmin(c *gin.Context) {
 		Preload("Team").
 		Preload("Challenge").
 		Preload("Challenge.ChallengeCategory").
+		Preload("Worker").
 		Order("created_at DESC").
 		Find(&instances)
 
@@ -72,6 +73,9 @@ func GetAllInstancesAdmin(c *gin.Context) {
 		if instance.Challenge.ChallengeCategory != nil {
 			instanceDTO.Category = instance.Challenge.ChallengeCategory.Name
 		}
+		if instance.Worker != nil {
+			instanceDTO.WorkerName = instance.Worker.Name
+		}
 
 		instanceDTOs = append(instanceDTOs, instanceDTO)
 	}
@@ -91,27 +95,34 @@ func DeleteInstanceAdmin(c *gin.Context) {
 	}
 
 	// Delete the instance from database first
-	if err := config.DB.Delete(&instance).Error; err != nil {
+	if err := deleteInstanceRecord(&instance); err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
 	}
 
-	// Stop the Docker/Compose container asynchronously (can take time)
+	// Stop the Docker/Compose container or VM asynchronously (can take time)
 	if instance.Container != "" {
 		containerName := instance.Container
+		workerID := instance.WorkerID
 		isCompose := instance.Challenge.ChallengeType.Name == "compose"
+		isVM := instance.Challenge.ChallengeType.Name == "vm"
 
 		go func() {
-			if isCompose {
+			if isVM {
+				debug.Log("Admin stopping VM asynchronously: %s", containerName)
+				if err := utils.StopVMInstance(containerName); err != nil {
+					debug.Log("Warning: Error stopping VM instance (may already be stopped): %v", err)
+				}
+			} else if isCompose {
 				debug.Log("Admin stopping Compose project asynchronously: %s", containerName)
-				if err := utils.StopComposeInstance(containerName); err != nil {
+				if err := utils.StopWorkerComposeInstance(workerID, containerName); err != nil {
 					debug.Log("Warning: Error stopping Compose instance (may already be stopped): %v", err)
 				} else {
 					debug.Log("Compose project stopped successfully: %s", containerName)
 				}
 			} else {
 				debug.Log("Admin stopping Docker container asynchronously: %s", containerName)
-				if err := utils.StopDockerInstance(containerName); err != nil {
+				if err := utils.StopWorkerDockerInstance(workerID, containerName); err != nil {
 					debug.Log("Warning: Error stopping Docker instance (may already be stopped): %v", err)
 				} else {
 					debug.Log("Docker container stopped successfully: %s", containerName)
@@ -146,41 +157,57 @@ func StopAllInstancesAdmin(c *gin.Context) {
 		utils.InternalServerError(c, err.Error())
 		return
 	}
+	synced := make(map[uint]bool)
+	for _, instance := range instances {
+		if !synced[instance.TeamID] {
+			synced[instance.TeamID] = true
+			syncTeamFirewall(instance.TeamID)
+		}
+	}
 
 	count := len(instances)
 	debug.Log("Admin stopping all instances: %d total", count)
 
-	// Stop all Docker/Compose containers asynchronously with rate limiting (3 at a time)
+	// Stop all Docker/Compose containers and VMs asynchronously with rate limiting (3 at a time)
 	const maxConcurrent = 3
 	semaphore := make(chan struct{}, maxConcurrent)
 
 	for _, instance := range instances {
 		if instance.Container != "" {
 			containerName := instance.Container
-			isCompose := instance.Challenge.ChallengeType != nil && instance.Challenge.ChallengeType.Name == "compose"
+			workerID := instance.WorkerID
+			typeName := ""
+			if instance.Challenge.ChallengeType != nil {
+				typeName = instance.Challenge.ChallengeType.Name
+			}
 
 			// Acquire semaphore slot (blocks if 3 are already running)
 			semaphore <- struct{}{}
 
-			go func(name string, compose bool) {
+			go func(name string, workerID *uint, typeName string) {
 				defer func() { <-semaphore }() // Release semaphore slot when done
 
-				if compose {
+				if typeName == "vm" {
+					debug.Log("Admin stopping VM asynchronously: %s", name)
+					if err := utils.StopVMInstance(name); err != nil {
+						debug.Log("Warning: Error stopping VM instance (may already be stopped): %v", err)
+					}
+				} else if typeName == "compose" {
 					debug.Log("Admin stopping Compose project asynchronously: %s", name)
-					if err := utils.StopComposeInstance(name); err != nil {
+					if err := utils.StopWorkerComposeInstance(workerID, name); err != nil {
 						debug.Log("Warning: Error stopping Compose instance (may already be stopped): %v", err)
 					} else {
 						debug.Log("Compose project stopped successfully: %s", name)
 					}
 				} else {
 					debug.Log("Admin stopping Docker container asynchronously: %s", name)
-					if err := utils.StopDockerInstance(name); err != nil {
+					if err := utils.StopWorkerDockerInstance(workerID, name); err != nil {
 						debug.Log("Warning: Error stopping Docker instance (may already be stopped): %v", err)
 					} else {
 						debug.Log("Docker container stopped successfully: %s", name)
 					}
 				}
-			}(containerName, isCompose)
+			}(containerName, workerID, typeName)
 		}
 	}
 
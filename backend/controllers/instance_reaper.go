@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// orphanContainerGracePeriod leaves time for a starting instance to get its database record
+const orphanContainerGracePeriod = 5 * time.Minute
+
+// InstanceReaper stops expired instances and removes orphan containers
+type InstanceReaper struct {
+	ticker   *time.Ticker
+	stopChan chan bool
+	running  bool
+}
+
+// NewInstanceReaper creates a new instance reaper
+func NewInstanceReaper() *InstanceReaper {
+	return &InstanceReaper{
+		stopChan: make(chan bool),
+		running:  false,
+	}
+}
+
+// Start begins the instance reaper
+func (ir *InstanceReaper) Start() {
+	if ir.running {
+		log.Println("Instance reaper is already running")
+		return
+	}
+
+	ir.ticker = time.NewTicker(1 * time.Minute)
+	ir.running = true
+
+	log.Println("Instance reaper started, checking every minute")
+
+	go func() {
+		// Check immediately on startup
+		ReapInstances()
+
+		for {
+			select {
+			case <-ir.ticker.C:
+				ReapInstances()
+			case <-ir.stopChan:
+				log.Println("Instance reaper stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the instance reaper
+func (ir *InstanceReaper) Stop() {
+	if !ir.running {
+		return
+	}
+
+	ir.running = false
+	if ir.ticker != nil {
+		ir.ticker.Stop()
+	}
+	ir.stopChan <- true
+}
+
+// Global reaper instance
+var globalInstanceReaper *InstanceReaper
+
+// StartInstanceReaper starts the global instance reaper
+func StartInstanceReaper() {
+	if globalInstanceReaper == nil {
+		globalInstanceReaper = NewInstanceReaper()
+	}
+	globalInstanceReaper.Start()
+}
+
+// StopInstanceReaper stops the global instance reaper
+func StopInstanceReaper() {
+	if globalInstanceReaper != nil {
+		globalInstanceReaper.Stop()
+	}
+}
+
+// ReapInstances stops expired instances then cleans up containers without instance record
+func ReapInstances() {
+	utils.RefreshWorkersHealth()
+	reapExpiredInstances()
+	reapOrphanContainers()
+}
+
+// reapExpiredInstances stops every instance past its expiration date
+func reapExpiredInstances() {
+	var instances []models.Instance
+	if err := config.DB.
+		Preload("Challenge.ChallengeType").
+		Where("expires_at < ?", time.Now()).
+		Find(&instances).Error; err != nil {
+		log.Printf("Failed to fetch expired instances: %v", err)
+		return
+	}
+
+	for i := range instances {
+		instance := &instances[i]
+		debug.Log("Reaping expired instance %s (team %d, challenge %d)", instance.Container, instance.TeamID, instance.ChallengeID)
+
+		recordInstanceCooldown(instance)
+
+		if instance.Challenge.ChallengeType == nil {
+			// Challenge type is unknown, fall back to a plain container stop
+			if err := utils.StopWorkerDockerInstance(instance.WorkerID, instance.Container); err != nil {
+				debug.Log("Failed to stop instance %s: %v", instance.Container, err)
+			}
+			if err := deleteInstanceRecord(instance); err != nil {
+				log.Printf("Failed to delete expired instance %d: %v", instance.ID, err)
+				continue
+			}
+		} else if err := stopInstanceByType(&instance.Challenge, instance); err != nil {
+			log.Printf("Failed to stop expired instance %s: %v", instance.Container, err)
+			continue
+		}
+
+		broadcastInstanceStop(instance.UserID, instance)
+		broadcastInstanceExpired(instance)
+		log.Printf("Stopped expired instance %s for team %d", instance.Container, instance.TeamID)
+	}
+}
+
+// broadcastInstanceExpired notifies the team over the updates hub that its instance expired
+func broadcastInstanceExpired(instance *models.Instance) {
+	if utils.UpdatesHub == nil || instance.TeamID == 0 {
+		return
+	}
+
+	if payload, err := json.Marshal(gin.H{
+		"event":       "instance-expired",
+		"teamId":      instance.TeamID,
+		"challengeId": instance.ChallengeID,
+		"status":      "stopped",
+	}); err == nil {
+		utils.UpdatesHub.SendToTeam(instance.TeamID, payload)
+	}
+}
+
+// reapOrphanContainers removes managed containers that no longer have an instance record, on every docker host
+func reapOrphanContainers() {
+	for _, target := range utils.ListDockerTargets() {
+		reapOrphanContainersOn(target)
+	}
+}
+
+// reapOrphanContainersOn removes orphan containers of a single docker host
+func reapOrphanContainersOn(target utils.DockerTarget) {
+	containers, err := utils.ListManagedContainers(target.Client)
+	if err != nil {
+		debug.Log("Skipping orphan container cleanup on %s: %v", target.Name, err)
+		return
+	}
+	if len(containers) == 0 {
+		return
+	}
+
+	query := config.DB.Model(&models.Instance{})
+	if target.WorkerID != nil {
+		query = query.Where("worker_id = ?", *target.WorkerID)
+	} else {
+		query = query.Where("worker_id IS NULL")
+	}
+	var names []string
+	if err := query.Pluck("container", &names).Error; err != nil {
+		log.Printf("Failed to fetch instance containers: %v", err)
+		return
+	}
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	stoppedProjects := make(map[string]bool)
+	for _, c := range containers {
+		if time.Since(time.Unix(c.Created, 0)) < orphanContainerGracePeriod {
+			continue
+		}
+
+		name, isCompose := utils.ManagedContainerInstanceName(c)
+		if name == "" || known[name] || stoppedProjects[name] {
+			continue
+		}
+
+		if isCompose {
+			stoppedProjects[name] = true
+			if err := utils.StopComposeInstance(target.Client, name); err != nil {
+				debug.Log("Failed to stop orphan compose project %s: %v", name, err)
+				continue
+			}
+		} else if err := utils.StopDockerInstance(target.Client, name); err != nil {
+			debug.Log("Failed to stop orphan container %s: %v", name, err)
+			continue
+		}
+		log.Printf("Removed orphan instance %s from %s", name, target.Name)
+	}
+}
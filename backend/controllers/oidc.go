@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// Session keys holding the pending authorization request between login and callback
+const (
+	oidcStateKey    = "oidc_state"
+	oidcNonceKey    = "oidc_nonce"
+	oidcVerifierKey = "oidc_verifier"
+)
+
+var usernameInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// oidcRedirectURL returns the callback URL registered at the provider, derived from the request when unset
+func oidcRedirectURL(c *gin.Context) string {
+	if redirectURL := config.GetConfigValue("OIDC_REDIRECT_URL", ""); redirectURL != "" {
+		return redirectURL
+	}
+	return publicURL(c, "/api/oidc/callback")
+}
+
+// oidcLoginError sends the browser back to the login page with an error code
+func oidcLoginError(c *gin.Context, code string) {
+	if code == "banned" {
+		c.Redirect(http.StatusFound, "/login?banned=true")
+		return
+	}
+	c.Redirect(http.StatusFound, "/login?error="+url.QueryEscape(code))
+}
+
+// randomToken returns a random URL safe string
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// OIDCLogin starts the authorization code flow and redirects to the identity provider
+func OIDCLogin(c *gin.Context) {
+	if !utils.OIDCEnabled() {
+		oidcLoginError(c, "oidc_disabled")
+		return
+	}
+
+	provider, err := utils.GetOIDCProvider(c.Request.Context())
+	if err != nil {
+		debug.Log("OIDC provider unavailable: %v", err)
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	session := sessions.Default(c)
+	session.Set(oidcStateKey, state)
+	session.Set(oidcNonceKey, nonce)
+	session.Set(oidcVerifierKey, verifier)
+	if err := session.Save(); err != nil {
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+
+	authURL := provider.OAuth2Config(oidcRedirectURL(c)).AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback completes the authorization code flow, then logs in the matching user
+func OIDCCallback(c *gin.Context) {
+	if !utils.OIDCEnabled() {
+		oidcLoginError(c, "oidc_disabled")
+		return
+	}
+
+	session := sessions.Default(c)
+	state, _ := session.Get(oidcStateKey).(string)
+	nonce, _ := session.Get(oidcNonceKey).(string)
+	verifier, _ := session.Get(oidcVerifierKey).(string)
+	// The pending request is single use, whatever the outcome
+	session.Delete(oidcStateKey)
+	session.Delete(oidcNonceKey)
+	session.Delete(oidcVerifierKey)
+	session.Save()
+
+	if errCode := c.Query("error"); errCode != "" {
+		debug.Log("OIDC provider returned an error: %s %s", errCode, c.Query("error_description"))
+		oidcLoginError(c, "oidc_denied")
+		return
+	}
+	if state == "" || c.Query("state") != state {
+		oidcLoginError(c, "oidc_state_mismatch")
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), oauth2.HTTPClient, utils.OIDCHTTPClient)
+	provider, err := utils.GetOIDCProvider(ctx)
+	if err != nil {
+		debug.Log("OIDC provider unavailable: %v", err)
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+
+	token, err := provider.OAuth2Config(oidcRedirectURL(c)).Exchange(ctx, c.Query("code"), oauth2.VerifierOption(verifier))
+	if err != nil {
+		debug.Log("OIDC code exchange failed: %v", err)
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		debug.Log("OIDC token response has no id_token")
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		debug.Log("OIDC id token rejected: %v", err)
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+
+	// Userinfo may carry the profile and group claims the ID token lacks
+	if userInfo, err := provider.FetchUserInfo(ctx, token); err != nil {
+		debug.Log("OIDC userinfo failed: %v", err)
+	} else if userInfo != nil && userInfo["sub"] == claims["sub"] {
+		for key, value := range userInfo {
+			if _, ok := claims[key]; !ok {
+				claims[key] = value
+			}
+		}
+	}
+
+	user, err := findOrCreateOIDCUser(claims)
+	if err != nil {
+		debug.Log("OIDC login rejected: %v", err)
+		oidcLoginError(c, err.Error())
+		return
+	}
+
+	if needsSecondFactor(user) {
+		if err := utils.SetPendingTwoFactor(c, user.ID); err != nil {
+			oidcLoginError(c, "oidc_failed")
+			return
+		}
+		c.Redirect(http.StatusFound, "/login?2fa=required")
+		return
+	}
+
+	if err := generateAndSetTokens(c, user.ID, user.Role); err != nil {
+		oidcLoginError(c, "oidc_failed")
+		return
+	}
+	c.Redirect(http.StatusFound, "/pwn")
+}
+
+// findOrCreateOIDCUser returns the user bound to the provider subject. A user with the same verified
+// email is linked on first login, otherwise a new account is created. The role follows the claim mapping.
+func findOrCreateOIDCUser(claims map[string]interface{}) (*models.User, error) {
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	email = strings.TrimSpace(email)
+	emailVerified, _ := claims["email_verified"].(bool)
+
+	var user models.User
+	err := config.DB.Where("oidc_subject = ?", subject).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && email != "" && emailVerified {
+		err = config.DB.Where("LOWER(email) = LOWER(?) AND oidc_subject IS NULL", email).First(&user).Error
+		if err == nil {
+			user.OIDCSubject = &subject
+		}
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !config.GetConfigBool("REGISTRATION_ENABLED", false) {
+			return nil, fmt.Errorf("registration_disabled")
+		}
+		if email == "" {
+			return nil, fmt.Errorf("oidc_email_required")
+		}
+		username, err := uniqueUsername(oidcUsername(claims, email))
+		if err != nil {
+			return nil, fmt.Errorf("oidc_failed")
+		}
+		user = models.User{
+			Username:    username,
+			Email:       email,
+			Role:        "member",
+			OIDCSubject: &subject,
+		}
+	case err != nil:
+		return nil, fmt.Errorf("oidc_failed")
+	}
+
+	if user.Banned {
+		return nil, fmt.Errorf("banned")
+	}
+	if role := utils.OIDCRole(claims); role != "" {
+		user.Role = role
+	}
+
+	if user.ID == 0 {
+		if err := config.DB.Create(&user).Error; err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				return nil, fmt.Errorf("oidc_email_taken")
+			}
+			return nil, fmt.Errorf("oidc_failed")
+		}
+		return &user, nil
+	}
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"oidc_subject": user.OIDCSubject,
+		"role":         user.Role,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("oidc_failed")
+	}
+	return &user, nil
+}
+
+// oidcUsername picks a username from the profile claims, keeping the characters allowed in usernames
+func oidcUsername(claims map[string]interface{}, email string) string {
+	for _, claim := range []string{"preferred_username", "nickname", "name"} {
+		if value, ok := claims[claim].(string); ok {
+			if username := usernameInvalidChars.ReplaceAllString(strings.TrimSpace(value), "_"); strings.Trim(username, "_") != "" {
+				return username
+			}
+		}
+	}
+	if local := usernameInvalidChars.ReplaceAllString(strings.SplitN(email, "@", 2)[0], "_"); strings.Trim(local, "_") != "" {
+		return local
+	}
+	return "user"
+}
+
+// uniqueUsername shortens the name to 32 characters and adds a number when it is already taken
+func uniqueUsername(base string) (string, error) {
+	if len(base) > 32 {
+		base = base[:32]
+	}
+	candidate := base
+	for i := 2; i < 1000; i++ {
+		var count int64
+		if err := config.DB.Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		suffix := fmt.Sprintf("-%d", i)
+		if len(base)+len(suffix) > 32 {
+			candidate = base[:32-len(suffix)] + suffix
+		} else {
+			candidate = base + suffix
+		}
+	}
+	return "", fmt.Errorf("no free username for %s", base)
+}
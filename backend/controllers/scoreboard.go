@@ -0,0 +1,350 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// setScoreboardRevealed stores the reveal flag and tells clients to reload the scoreboard
+func setScoreboardRevealed(revealed bool) error {
+	value := "false"
+	if revealed {
+		value = "true"
+	}
+	cfg := models.Config{Key: "SCOREBOARD_REVEALED", Value: value, Public: true}
+	if err := config.DB.Save(&cfg).Error; err != nil {
+		return err
+	}
+
+	if utils.UpdatesHub != nil {
+		if payload, err := json.Marshal(gin.H{
+			"event":  "ctf-status",
+			"action": "scoreboard_update",
+		}); err == nil {
+			utils.UpdatesHub.SendToAll(payload)
+		}
+	}
+	return nil
+}
+
+// RevealScoreboard lifts the scoreboard freeze and publishes the real standings
+func RevealScoreboard(c *gin.Context) {
+	if err := setScoreboardRevealed(true); err != nil {
+		utils.InternalServerError(c, "failed_to_reveal_scoreboard")
+		return
+	}
+	log.Printf("Scoreboard revealed")
+	utils.OKResponse(c, gin.H{"message": "scoreboard_revealed"})
+}
+
+// FreezeScoreboard hides the solves made after SCOREBOARD_FREEZE_TIME again
+func FreezeScoreboard(c *gin.Context) {
+	if config.GetConfigValue("SCOREBOARD_FREEZE_TIME", "") == "" {
+		utils.BadRequestError(c, "scoreboard_freeze_time_not_set")
+		return
+	}
+	if err := setScoreboardRevealed(false); err != nil {
+		utils.InternalServerError(c, "failed_to_freeze_scoreboard")
+		return
+	}
+	log.Printf("Scoreboard frozen again")
+	utils.OKResponse(c, gin.H{"message": "scoreboard_frozen"})
+}
+
+// GetCTFtimeScoreboard returns the standings in the CTFtime scoreboard feed format
+func GetCTFtimeScoreboard(c *gin.Context) {
+	bracketID, ok := bracketParam(c)
+	if !ok {
+		return
+	}
+
+	entries, err := getScoreboardEntries(c, bracketID)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_scoreboard")
+		return
+	}
+	before, _, _ := scoreboardFreeze(c)
+
+	var teams []models.Team
+	if err := config.DB.Find(&teams).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_teams")
+		return
+	}
+	teamNames := make(map[uint]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+
+	var challenges []models.Challenge
+	if err := config.DB.Order("id ASC").Find(&challenges).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_challenges")
+		return
+	}
+	challengeNames := make(map[uint]string, len(challenges))
+	feed := dto.CTFtimeFeed{Tasks: []string{}, Standings: []dto.CTFtimeStanding{}}
+	for _, challenge := range challenges {
+		challengeNames[challenge.ID] = challenge.Name
+		if !challenge.Hidden {
+			feed.Tasks = append(feed.Tasks, challenge.Name)
+		}
+	}
+
+	var solves []models.Solve
+	if err := config.DB.Scopes(visibleSolves(c), bracketSolves(bracketID)).Order("created_at ASC").Find(&solves).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_solves")
+		return
+	}
+	points, err := utils.ScoreSolves(solves, before)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_calculate_score")
+		return
+	}
+	taskStats := make(map[uint]map[string]dto.CTFtimeTaskStat)
+	for _, solve := range solves {
+		if taskStats[solve.TeamID] == nil {
+			taskStats[solve.TeamID] = make(map[string]dto.CTFtimeTaskStat)
+		}
+		taskStats[solve.TeamID][challengeNames[solve.ChallengeID]] = dto.CTFtimeTaskStat{
+			Points: points[[2]uint{solve.ChallengeID, solve.TeamID}],
+			Time:   solve.CreatedAt.Unix(),
+		}
+	}
+
+	for _, entry := range entries {
+		name, ok := teamNames[entry.TeamID]
+		if !ok {
+			continue
+		}
+		standing := dto.CTFtimeStanding{
+			Pos:       len(feed.Standings) + 1,
+			Team:      name,
+			Score:     entry.TotalScore,
+			TaskStats: taskStats[entry.TeamID],
+		}
+		if entry.LastSolveAt != nil {
+			standing.LastAccept = entry.LastSolveAt.Unix()
+		}
+		feed.Standings = append(feed.Standings, standing)
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// exportFormat returns the format requested for an export, csv or json
+func exportFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		utils.BadRequestError(c, "invalid_export_format")
+		return "", false
+	}
+	return format, true
+}
+
+// writeExport sends an export as a CSV or JSON attachment
+func writeExport(c *gin.Context, name string, format string, header []string, rows [][]string, data interface{}) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	if format == "json" {
+		c.JSON(http.StatusOK, data)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.Write(header); err == nil {
+		err = writer.WriteAll(rows)
+		if err != nil {
+			log.Printf("Failed to write %s export: %v", name, err)
+		}
+	}
+}
+
+// formatExportTime formats an optional time for CSV exports
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// bracketName returns the name of an optional bracket for exports
+func bracketName(bracket *models.Bracket) string {
+	if bracket == nil {
+		return ""
+	}
+	return bracket.Name
+}
+
+// ExportStandings exports the live standings
+func ExportStandings(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	entries, err := utils.GetScoreboard()
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_scoreboard")
+		return
+	}
+	var teams []models.Team
+	if err := config.DB.Preload("Bracket").Find(&teams).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_teams")
+		return
+	}
+	teamsByID := make(map[uint]models.Team, len(teams))
+	for _, team := range teams {
+		teamsByID[team.ID] = team
+	}
+
+	standings := make([]dto.StandingExport, 0, len(entries))
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		team := teamsByID[entry.TeamID]
+		standing := dto.StandingExport{
+			Pos:         len(standings) + 1,
+			TeamID:      entry.TeamID,
+			Team:        team.Name,
+			Bracket:     bracketName(team.Bracket),
+			Score:       entry.TotalScore,
+			SolvePoints: entry.SolvePoints,
+			AwardPoints: entry.AwardPoints,
+			HintsCost:   entry.HintsCost,
+			SolveCount:  entry.SolveCount,
+			LastSolveAt: entry.LastSolveAt,
+		}
+		standings = append(standings, standing)
+		rows = append(rows, []string{
+			strconv.Itoa(standing.Pos), strconv.FormatUint(uint64(standing.TeamID), 10), standing.Team, standing.Bracket,
+			strconv.Itoa(standing.Score), strconv.Itoa(standing.SolvePoints), strconv.Itoa(standing.AwardPoints), strconv.Itoa(standing.HintsCost),
+			strconv.Itoa(standing.SolveCount), formatExportTime(standing.LastSolveAt),
+		})
+	}
+
+	header := []string{"pos", "team_id", "team", "bracket", "score", "solve_points", "award_points", "hints_cost", "solve_count", "last_solve_at"}
+	writeExport(c, "standings", format, header, rows, standings)
+}
+
+// ExportSolves exports every solve with its current and original value
+func ExportSolves(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	var solves []models.Solve
+	if err := config.DB.Preload("Team").Preload("User").Preload("Challenge.ChallengeCategory").
+		Order("challenge_id ASC, created_at ASC").Find(&solves).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_solves")
+		return
+	}
+	points, err := utils.ScoreSolves(solves, time.Time{})
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_calculate_score")
+		return
+	}
+
+	exports := make([]dto.SolveExport, 0, len(solves))
+	rows := make([][]string, 0, len(solves))
+	positions := make(map[uint]int)
+	for _, solve := range solves {
+		positions[solve.ChallengeID]++
+		export := dto.SolveExport{
+			ChallengeID:   solve.ChallengeID,
+			TeamID:        solve.TeamID,
+			UserID:        solve.UserID,
+			Position:      positions[solve.ChallengeID],
+			Points:        points[[2]uint{solve.ChallengeID, solve.TeamID}],
+			PointsAtSolve: solve.Points,
+			SolvedAt:      solve.CreatedAt,
+		}
+		if solve.Challenge != nil {
+			export.Challenge = solve.Challenge.Name
+			if solve.Challenge.ChallengeCategory != nil {
+				export.Category = solve.Challenge.ChallengeCategory.Name
+			}
+		}
+		if solve.Team != nil {
+			export.Team = solve.Team.Name
+		}
+		if solve.User != nil {
+			export.Username = solve.User.Username
+		}
+		exports = append(exports, export)
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(export.ChallengeID), 10), export.Challenge, export.Category,
+			strconv.FormatUint(uint64(export.TeamID), 10), export.Team,
+			strconv.FormatUint(uint64(export.UserID), 10), export.Username,
+			strconv.Itoa(export.Position), strconv.Itoa(export.Points), strconv.Itoa(export.PointsAtSolve),
+			formatExportTime(&export.SolvedAt),
+		})
+	}
+
+	header := []string{"challenge_id", "challenge", "category", "team_id", "team", "user_id", "username", "position", "points", "points_at_solve", "solved_at"}
+	writeExport(c, "solves", format, header, rows, exports)
+}
+
+// ExportFirstBloods exports the first blood bonuses awarded on every challenge
+func ExportFirstBloods(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	var firstBloods []models.FirstBlood
+	if err := config.DB.Preload("Challenge").Preload("Team.Bracket").Preload("User").
+		Order("challenge_id ASC, created_at ASC").Find(&firstBloods).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_first_bloods")
+		return
+	}
+
+	exports := make([]dto.FirstBloodExport, 0, len(firstBloods))
+	rows := make([][]string, 0, len(firstBloods))
+	for _, firstBlood := range firstBloods {
+		export := dto.FirstBloodExport{
+			ChallengeID: firstBlood.ChallengeID,
+			TeamID:      firstBlood.TeamID,
+			UserID:      firstBlood.UserID,
+			CreatedAt:   firstBlood.CreatedAt,
+		}
+		if len(firstBlood.Bonuses) > 0 {
+			export.Bonus = firstBlood.Bonuses[0]
+		}
+		if len(firstBlood.Badges) > 0 {
+			export.Badge = firstBlood.Badges[0]
+		}
+		if firstBlood.Challenge != nil {
+			export.Challenge = firstBlood.Challenge.Name
+		}
+		if firstBlood.Team != nil {
+			export.Team = firstBlood.Team.Name
+			export.Bracket = bracketName(firstBlood.Team.Bracket)
+		}
+		if firstBlood.User != nil {
+			export.Username = firstBlood.User.Username
+		}
+		exports = append(exports, export)
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(export.ChallengeID), 10), export.Challenge,
+			strconv.FormatUint(uint64(export.TeamID), 10), export.Team, export.Bracket,
+			strconv.FormatUint(uint64(export.UserID), 10), export.Username,
+			strconv.FormatInt(export.Bonus, 10), export.Badge, formatExportTime(&export.CreatedAt),
+		})
+	}
+
+	header := []string{"challenge_id", "challenge", "team_id", "team", "bracket", "user_id", "username", "bonus", "badge", "created_at"}
+	writeExport(c, "first-bloods", format, header, rows, exports)
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// currentSessionID returns the session of the access token of the request, 0 when it has none
+func currentSessionID(c *gin.Context) uint {
+	if sessionID, ok := c.Get("session_id"); ok {
+		if id, ok := sessionID.(uint); ok {
+			return id
+		}
+	}
+	return 0
+}
+
+// listSessions answers with the active sessions of a user, flagging the one of the request
+func listSessions(c *gin.Context, userID uint) {
+	sessions, err := utils.ActiveSessions(userID)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_sessions")
+		return
+	}
+
+	current := currentSessionID(c)
+	result := make([]dto.SessionInfo, len(sessions))
+	for i, session := range sessions {
+		result[i] = dto.SessionInfo{Session: session, Current: session.ID == current}
+	}
+	utils.OKResponse(c, result)
+}
+
+// revokeSessionParam revokes the session of the given route parameter for a user
+func revokeSessionParam(c *gin.Context, userID uint, param string) {
+	sessionID, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil {
+		utils.BadRequestError(c, "invalid_session_id")
+		return
+	}
+	revoked, err := utils.RevokeSession(userID, uint(sessionID))
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_revoke_session")
+		return
+	}
+	if !revoked {
+		utils.NotFoundError(c, "session_not_found")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "session_revoked"})
+}
+
+// GetMySessions lists the devices the current user is logged in on
+func GetMySessions(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+	listSessions(c, user.ID)
+}
+
+// DeleteMySession logs the current user out of one of their sessions
+func DeleteMySession(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+	revokeSessionParam(c, user.ID, "id")
+}
+
+// LogoutEverywhere ends every session of the current user, this one included
+func LogoutEverywhere(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	count, err := utils.RevokeUserSessions(user.ID, 0)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_revoke_sessions")
+		return
+	}
+	debug.Log("User %d logged out of %d sessions", user.ID, count)
+	clearAuthCookies(c)
+	utils.OKResponse(c, gin.H{"message": "logged_out_everywhere", "revoked": count})
+}
+
+// GetUserSessions lists the sessions of a user (admin only)
+func GetUserSessions(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "user_not_found")
+		return
+	}
+	listSessions(c, user.ID)
+}
+
+// DeleteUserSession kills one session of a user (admin only)
+func DeleteUserSession(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "user_not_found")
+		return
+	}
+	revokeSessionParam(c, user.ID, "sessionId")
+}
+
+// DeleteUserSessions kills every session of a user (admin only)
+func DeleteUserSessions(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "user_not_found")
+		return
+	}
+
+	count, err := utils.RevokeUserSessions(user.ID, 0)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_revoke_sessions")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "sessions_revoked", "revoked": count})
+}
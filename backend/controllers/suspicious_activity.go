@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+const (
+	suspiciousTypeFlagSharing = "flag_sharing"
+	suspiciousStatusPending   = "pending"
+	suspiciousStatusDismissed = "dismissed"
+)
+
+// detectFlagSharing records an alert when a team submits a dynamic flag generated for another team
+func detectFlagSharing(user *models.User, challenge models.Challenge, submission models.Submission, submittedValue string) {
+	if !challenge.DynamicFlag || user.TeamID == nil {
+		return
+	}
+
+	sourceTeamID, found := utils.FindDynamicFlagOwner(*user.TeamID, challenge.ID, submittedValue)
+	if !found {
+		return
+	}
+
+	activity := models.SuspiciousActivity{
+		Type:         suspiciousTypeFlagSharing,
+		Status:       suspiciousStatusPending,
+		SubmissionID: &submission.ID,
+		UserID:       user.ID,
+		TeamID:       *user.TeamID,
+		SourceTeamID: sourceTeamID,
+		ChallengeID:  challenge.ID,
+	}
+
+	if config.GetConfigBool("FLAG_SHARING_AUTO_BAN", false) && user.Role != "admin" {
+		if err := setUserBanned(user, true); err != nil {
+			log.Printf("Failed to auto-ban user %d for flag sharing: %v", user.ID, err)
+		} else {
+			activity.AutoBanned = true
+		}
+	}
+
+	if err := config.DB.Create(&activity).Error; err != nil {
+		log.Printf("Failed to record flag sharing for user %d on challenge %d: %v", user.ID, challenge.ID, err)
+		return
+	}
+	log.Printf("Flag sharing detected: team %d submitted the flag of team %d on challenge %d", *user.TeamID, sourceTeamID, challenge.ID)
+}
+
+// GetSuspiciousActivities returns flag sharing alerts, optionally filtered by status (admin only)
+func GetSuspiciousActivities(c *gin.Context) {
+	var activities []models.SuspiciousActivity
+
+	query := config.DB.
+		Preload("User").
+		Preload("Team").
+		Preload("SourceTeam").
+		Preload("Challenge").
+		Order("created_at DESC")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Find(&activities).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_suspicious_activities")
+		return
+	}
+
+	response := make([]dto.SuspiciousActivityResponse, len(activities))
+	for i, a := range activities {
+		resp := dto.SuspiciousActivityResponse{
+			ID:          a.ID,
+			Type:        a.Type,
+			Status:      a.Status,
+			ChallengeID: a.ChallengeID,
+			AutoBanned:  a.AutoBanned,
+			ReviewedAt:  a.ReviewedAt,
+			CreatedAt:   a.CreatedAt,
+		}
+		if a.Team != nil {
+			resp.Team = models.SafeTeam{ID: a.Team.ID, Name: a.Team.Name}
+		}
+		if a.SourceTeam != nil {
+			resp.SourceTeam = models.SafeTeam{ID: a.SourceTeam.ID, Name: a.SourceTeam.Name}
+		}
+		if a.User != nil {
+			resp.User = models.SafeUserWithTeam{
+				ID:       a.User.ID,
+				Username: a.User.Username,
+				Role:     a.User.Role,
+				Team:     resp.Team,
+			}
+		}
+		if a.Challenge != nil {
+			resp.Challenge = a.Challenge.Name
+		}
+		response[i] = resp
+	}
+
+	utils.OKResponse(c, response)
+}
+
+// DismissSuspiciousActivity marks an alert as reviewed and dismissed (admin only)
+func DismissSuspiciousActivity(c *gin.Context) {
+	var activity models.SuspiciousActivity
+	if err := config.DB.First(&activity, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "suspicious_activity_not_found")
+		return
+	}
+
+	now := time.Now()
+	reviewerID := c.GetUint("user_id")
+	activity.Status = suspiciousStatusDismissed
+	activity.ReviewedAt = &now
+	activity.ReviewedByID = &reviewerID
+
+	if err := config.DB.Save(&activity).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_update_suspicious_activity")
+		return
+	}
+
+	utils.OKResponse(c, gin.H{"message": "suspicious_activity_dismissed"})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
@@ -41,25 +42,37 @@ func GetTeam(c *gin.Context) {
 	// Compute per-member points by attributing each team solve to the user whose submission led to it
 	// IMPORTANT: JSON only supports string keys for maps; use string keys to avoid marshal errors
 	memberPoints := map[string]int{}
-	var totalPoints int
+	var totalPoints, totalAwards int
 
-	// Initialize decay service for current points calculation
-	decayService := utils.NewDecay()
+	// While the scoreboard is frozen, other teams only see the solves made before the freeze
+	var before time.Time
+	if freezeTime, viewerTeamID, frozen := scoreboardFreeze(c); frozen && viewerTeamID != team.ID {
+		before = freezeTime
+	}
+
+	solvePoints, err := utils.TeamSolvePoints(team.ID, before)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
 
 	// Fetch all solves for this team
+	solveQuery := config.DB.Where("team_id = ?", team.ID)
+	hintQuery := config.DB.Model(&models.HintPurchase{}).Where("team_id = ?", team.ID)
+	awardQuery := config.DB.Where("team_id = ?", team.ID)
+	if !before.IsZero() {
+		solveQuery = solveQuery.Where("created_at < ?", before)
+		hintQuery = hintQuery.Where("created_at < ?", before)
+		awardQuery = awardQuery.Where("awarded_at < ?", before)
+	}
 	var solves []models.Solve
-	if err := config.DB.Where("team_id = ?", team.ID).Order("created_at ASC").Find(&solves).Error; err == nil {
+	if err := solveQuery.Order("created_at ASC").Find(&solves).Error; err == nil {
 		for _, solve := range solves {
-			// Get challenge to calculate current decayed points
-			var challenge models.Challenge
-			if err := config.DB.Preload("DecayFormula").First(&challenge, solve.ChallengeID).Error; err != nil {
+			currentPoints, ok := solvePoints[solve.ChallengeID]
+			if !ok {
 				continue
 			}
 
-			// Calculate position and current points with decay
-			position := getSolvePosition(challenge.ID, solve.CreatedAt)
-			currentPoints := calculateSolvePointsWithDecay(&solve, &challenge, position, decayService)
-
 			// Find the submission that led to this solve: the latest submission for this challenge
 			// by a member of the team at or before the solve time
 			var submission models.Submission
@@ -76,10 +89,20 @@ func GetTeam(c *gin.Context) {
 		}
 	}
 
+	// Awards count for the team and for the member they credit
+	var awards []models.Award
+	if err := awardQuery.Find(&awards).Error; err == nil {
+		for _, award := range awards {
+			if award.UserID != nil {
+				memberPoints[fmt.Sprintf("%d", *award.UserID)] += award.Value
+			}
+			totalAwards += award.Value
+		}
+	}
+
 	// Get total spent on hints
 	var totalSpent int64
-	config.DB.Model(&models.HintPurchase{}).
-		Where("team_id = ?", team.ID).
+	hintQuery.
 		Select("COALESCE(SUM(cost), 0)").
 		Scan(&totalSpent)
 
@@ -87,7 +110,8 @@ func GetTeam(c *gin.Context) {
 		"team":         team,
 		"members":      members,
 		"memberPoints": memberPoints,
-		"totalPoints":  totalPoints - int(totalSpent),
+		"totalPoints":  totalPoints + totalAwards - int(totalSpent),
+		"awardPoints":  totalAwards,
 		"spentOnHints": int(totalSpent),
 	})
 }
@@ -95,8 +119,9 @@ func GetTeam(c *gin.Context) {
 // CreateTeam creates a new team and assigns the current user as creator
 func CreateTeam(c *gin.Context) {
 	var input struct {
-		Name     string `json:"name" binding:"required"`
-		Password string `json:"password" binding:"required"`
+		Name      string `json:"name" binding:"required"`
+		Password  string `json:"password" binding:"required"`
+		BracketID *uint  `json:"bracketId"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.BadRequestError(c, "invalid_input")
@@ -113,6 +138,11 @@ func CreateTeam(c *gin.Context) {
 		return
 	}
 
+	if input.BracketID != nil && !bracketExists(*input.BracketID) {
+		utils.BadRequestError(c, "bracket_not_found")
+		return
+	}
+
 	// dupe check
 	var existingTeam models.Team
 	if err := config.DB.Where("name = ?", input.Name).First(&existingTeam).Error; err == nil {
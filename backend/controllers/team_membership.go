@@ -293,7 +293,12 @@ func KickTeamMember(c *gin.Context) {
 
 // deleteTeamCompletely removes a team and all its related records
 func deleteTeamCompletely(teamID uint) error {
-	return config.DB.Transaction(func(tx *gorm.DB) error {
+	var instances []models.Instance
+	config.DB.Preload("Challenge.ChallengeType").Where("team_id = ?", teamID).Find(&instances)
+	var solvedChallengeIDs []uint
+	config.DB.Model(&models.Solve{}).Where("team_id = ?", teamID).Pluck("challenge_id", &solvedChallengeIDs)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
 		var userIds []uint
 		if err := tx.Model(&models.User{}).Where("team_id = ?", teamID).Pluck("id", &userIds).Error; err != nil {
 			log.Printf("Failed to get user IDs for team %d: %v", teamID, err)
@@ -327,6 +332,16 @@ func deleteTeamCompletely(teamID uint) error {
 			return err
 		}
 
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.Award{}).Error; err != nil {
+			log.Printf("Failed to delete awards for team %d: %v", teamID, err)
+			return err
+		}
+
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.ScoreboardEntry{}).Error; err != nil {
+			log.Printf("Failed to delete scoreboard entry for team %d: %v", teamID, err)
+			return err
+		}
+
 		if err := tx.Delete(&models.Team{}, teamID).Error; err != nil {
 			log.Printf("Failed to delete team %d: %v", teamID, err)
 			return err
@@ -335,4 +350,35 @@ func deleteTeamCompletely(teamID uint) error {
 		log.Printf("Successfully deleted team %d and all related records", teamID)
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Solve-based decay changes for the teams that solved the same challenges
+	if err := utils.RefreshChallengeScores(solvedChallengeIDs...); err != nil {
+		log.Printf("Failed to refresh scoreboard after deleting team %d: %v", teamID, err)
+	}
+
+	go releaseTeamResources(teamID, instances)
+	return nil
+}
+
+// releaseTeamResources stops the instances of a deleted team then frees its challenge subnet
+func releaseTeamResources(teamID uint, instances []models.Instance) {
+	for i := range instances {
+		typeName := ""
+		if instances[i].Challenge.ChallengeType != nil {
+			typeName = instances[i].Challenge.ChallengeType.Name
+		}
+		if err := stopInstanceResources(typeName, &instances[i]); err != nil {
+			log.Printf("Failed to stop instance %s of deleted team %d: %v", instances[i].Container, teamID, err)
+		}
+	}
+
+	if err := utils.ReleaseTeamSubnet(teamID); err != nil {
+		log.Printf("Failed to release subnet of team %d: %v", teamID, err)
+	}
+	if err := utils.RemoveFirewallFromAgent(teamID); err != nil {
+		log.Printf("Failed to remove firewall rules of team %d: %v", teamID, err)
+	}
 }
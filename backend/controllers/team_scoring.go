@@ -3,12 +3,15 @@ package controllers
 import (
 	"log"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/dto"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
 )
 
 // Constants for query strings
@@ -21,71 +24,123 @@ const (
 
 // Helper functions for score calculation
 
-// calculateSolvePointsWithDecay calculates points for a single solve including first blood bonus with decay
-// Uses CURRENT challenge value based on total solves, not value at solve time
-func calculateSolvePointsWithDecay(solve *models.Solve, challenge *models.Challenge, position int, decayService *utils.DecayService) int {
-	// Use CalculateCurrentPoints to get CURRENT challenge value (changes as more teams solve)
-	currentPoints := decayService.CalculateCurrentPoints(challenge)
-	
-	// Add FirstBlood bonus if applicable (based on position at solve time)
-	if challenge.EnableFirstBlood && len(challenge.FirstBloodBonuses) > 0 {
-		if position < len(challenge.FirstBloodBonuses) {
-			bonusValue := int(challenge.FirstBloodBonuses[position])
-			currentPoints += bonusValue
+// scoreboardFreeze returns the freeze time applying to the requester and the team whose solves stay visible.
+// Admins always see the live scoreboard.
+func scoreboardFreeze(c *gin.Context) (time.Time, uint, bool) {
+	freezeTime, frozen := config.GetScoreboardFreezeTime()
+	if !frozen {
+		return time.Time{}, 0, false
+	}
+
+	var teamID uint
+	if userI, exists := c.Get("user"); exists {
+		if user, ok := userI.(*models.User); ok {
+			if user.Role == "admin" {
+				return time.Time{}, 0, false
+			}
+			if user.TeamID != nil {
+				teamID = *user.TeamID
+			}
 		}
 	}
-	
-	return currentPoints
+	return freezeTime, teamID, true
 }
 
-// getSolvePosition gets the 0-based position of a solve for a challenge
-func getSolvePosition(challengeID uint, createdAt interface{}) int {
-	var position int64
-	config.DB.Model(&models.Solve{}).
-		Where(queryChallengeCreatedAt, challengeID, createdAt).
-		Count(&position)
-	return int(position)
+// visibleSolves restricts a solves query to what the requester may see while the scoreboard is frozen
+func visibleSolves(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	freezeTime, teamID, frozen := scoreboardFreeze(c)
+	return func(db *gorm.DB) *gorm.DB {
+		if !frozen {
+			return db
+		}
+		return db.Where("(solves.created_at < ? OR solves.team_id = ?)", freezeTime, teamID)
+	}
 }
 
-// getTeamHintsCost calculates total spent on hints for a team
-func getTeamHintsCost(teamID uint) int {
-	var totalSpent int64
-	config.DB.Model(&models.HintPurchase{}).
-		Where(queryTeamID, teamID).
-		Select(queryCoalesceSumCost).
-		Scan(&totalSpent)
-	return int(totalSpent)
+// visibleAwards restricts an awards query to what the requester may see while the scoreboard is frozen
+func visibleAwards(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	freezeTime, teamID, frozen := scoreboardFreeze(c)
+	return func(db *gorm.DB) *gorm.DB {
+		if !frozen {
+			return db
+		}
+		return db.Where("(awards.awarded_at < ? OR awards.team_id = ?)", freezeTime, teamID)
+	}
 }
 
-// calculateTeamScore calculates total score for a team's solves
-func calculateTeamScore(teamID uint, decayService *utils.DecayService) (int, error) {
-	var solves []models.Solve
-	if err := config.DB.Where(queryTeamID, teamID).Find(&solves).Error; err != nil {
-		return 0, err
+// visibleFirstBloods restricts a first bloods query to what the requester may see while the scoreboard is frozen
+func visibleFirstBloods(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	freezeTime, teamID, frozen := scoreboardFreeze(c)
+	return func(db *gorm.DB) *gorm.DB {
+		if !frozen {
+			return db
+		}
+		return db.Where("(first_bloods.created_at < ? OR first_bloods.team_id = ?)", freezeTime, teamID)
+	}
+}
+
+// bracketParam returns the bracket requested with ?bracket=, 0 for the global ranking
+func bracketParam(c *gin.Context) (uint, bool) {
+	value := c.Query("bracket")
+	if value == "" {
+		return 0, true
 	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || !bracketExists(uint(id)) {
+		utils.BadRequestError(c, "invalid_bracket")
+		return 0, false
+	}
+	return uint(id), true
+}
 
-	totalScore := 0
-	for _, solve := range solves {
-		var challenge models.Challenge
-		if err := config.DB.Preload("DecayFormula").First(&challenge, solve.ChallengeID).Error; err != nil {
-			continue
+// bracketSolves restricts a solves query to the teams of a bracket
+func bracketSolves(bracketID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if bracketID == 0 {
+			return db
 		}
-
-		position := getSolvePosition(challenge.ID, solve.CreatedAt)
-		points := calculateSolvePointsWithDecay(&solve, &challenge, position, decayService)
-		totalScore += points
-		log.Printf("[DEBUG] Team %d, Challenge %d (slug: %s), Position: %d, CurrentPoints: %d, DecayFormulaID: %d", 
-			teamID, challenge.ID, challenge.Slug, position, points, challenge.DecayFormulaID)
+		return db.Where("solves.team_id IN (SELECT id FROM teams WHERE bracket_id = ?)", bracketID)
 	}
+}
 
-	return totalScore, nil
+// bracketAwards restricts an awards query to the teams of a bracket
+func bracketAwards(bracketID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if bracketID == 0 {
+			return db
+		}
+		return db.Where("awards.team_id IN (SELECT id FROM teams WHERE bracket_id = ?)", bracketID)
+	}
 }
 
-// sortLeaderboard sorts leaderboard by total score in descending order
-func sortLeaderboard(leaderboard []dto.TeamScore) {
-	sort.Slice(leaderboard, func(i, j int) bool {
-		return leaderboard[i].TotalScore > leaderboard[j].TotalScore
-	})
+// getScoreboardEntries returns the scoreboard the requester may see, restricted to a bracket when set
+func getScoreboardEntries(c *gin.Context, bracketID uint) ([]models.ScoreboardEntry, error) {
+	var entries []models.ScoreboardEntry
+	var err error
+	if freezeTime, teamID, frozen := scoreboardFreeze(c); frozen {
+		entries, err = utils.GetFrozenScoreboard(freezeTime, teamID)
+	} else {
+		entries, err = utils.GetScoreboard()
+	}
+	if err != nil || bracketID == 0 {
+		return entries, err
+	}
+
+	var teamIDs []uint
+	if err := config.DB.Model(&models.Team{}).Where("bracket_id = ?", bracketID).Pluck("id", &teamIDs).Error; err != nil {
+		return nil, err
+	}
+	inBracket := make(map[uint]bool, len(teamIDs))
+	for _, id := range teamIDs {
+		inBracket[id] = true
+	}
+	filtered := make([]models.ScoreboardEntry, 0, len(teamIDs))
+	for _, entry := range entries {
+		if inBracket[entry.TeamID] {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, nil
 }
 
 // calculateFirstBloodBonusForScoring determines the first blood bonus for a solve position during scoring recalculation
@@ -120,17 +175,17 @@ func createFirstBloodEntryForRecalc(challenge *models.Challenge, solve *models.S
 }
 
 // processSolveRecalculation processes a single solve during point recalculation
-func processSolveRecalculation(solve *models.Solve, position int, decayService *utils.DecayService) (int, error) {
+func processSolveRecalculation(solve *models.Solve, position int, bloodPosition int, decayService *utils.DecayService) (int, error) {
 	var challenge models.Challenge
 	if err := config.DB.Preload("DecayFormula").First(&challenge, solve.ChallengeID).Error; err != nil {
 		return 0, err
 	}
 
-	newPoints := decayService.CalculateDecayedPoints(&challenge, position)
-	firstBloodBonus := calculateFirstBloodBonusForScoring(&challenge, position)
+	newPoints := decayService.CalculateDecayedPoints(&challenge, position, solve.CreatedAt)
+	firstBloodBonus := calculateFirstBloodBonusForScoring(&challenge, bloodPosition)
 
 	if firstBloodBonus > 0 {
-		if err := createFirstBloodEntryForRecalc(&challenge, solve, position, firstBloodBonus); err != nil {
+		if err := createFirstBloodEntryForRecalc(&challenge, solve, bloodPosition, firstBloodBonus); err != nil {
 			log.Printf("Failed to recreate FirstBlood entry: %v", err)
 		}
 	}
@@ -138,45 +193,6 @@ func processSolveRecalculation(solve *models.Solve, position int, decayService *
 	return newPoints + firstBloodBonus, nil
 }
 
-// teamScore holds team and its calculated score
-type teamScore struct {
-	team  models.Team
-	score int
-}
-
-// calculateTeamScoreWithHints calculates team score including hint costs
-func calculateTeamScoreWithHints(team models.Team, decayService *utils.DecayService) teamScore {
-	totalScore, err := calculateTeamScore(team.ID, decayService)
-	if err != nil {
-		return teamScore{team: team, score: 0}
-	}
-
-	hintsCost := getTeamHintsCost(team.ID)
-	return teamScore{team: team, score: totalScore - hintsCost}
-}
-
-// getTopTeams returns top N teams sorted by score
-func getTopTeams(teams []models.Team, decayService *utils.DecayService, topN int) []models.Team {
-	var teamScores []teamScore
-
-	for _, team := range teams {
-		teamScores = append(teamScores, calculateTeamScoreWithHints(team, decayService))
-	}
-
-	// Sort by score descending
-	sort.Slice(teamScores, func(i, j int) bool {
-		return teamScores[i].score > teamScores[j].score
-	})
-
-	// Extract top N teams
-	topTeams := make([]models.Team, 0, topN)
-	for i := 0; i < len(teamScores) && i < topN; i++ {
-		topTeams = append(topTeams, teamScores[i].team)
-	}
-
-	return topTeams
-}
-
 // timelinePoint represents a point in the team timeline with dynamic team scores
 type timelinePoint struct {
 	Time   string         `json:"time"`
@@ -196,24 +212,24 @@ type timelineResponse struct {
 	Timeline []timelinePoint `json:"timeline"`
 }
 
-// calculateSolvePointsForTimeline calculates points for a solve including position lookup
-func calculateSolvePointsForTimeline(solve *models.Solve, challenge *models.Challenge, decayService *utils.DecayService) int {
-	var position int64
-	config.DB.Model(&models.Solve{}).
-		Where(queryChallengeCreatedAtLTE, challenge.ID, solve.CreatedAt).
-		Count(&position)
-	position-- // Convert to 0-based
-
-	points := decayService.CalculateDecayedPoints(challenge, int(position))
-	points += calculateFirstBloodBonusForScoring(challenge, int(position))
+// scoreEvent is a score change of a team or a user shown on the timelines
+type scoreEvent struct {
+	At     time.Time
+	ID     uint
+	Points int
+}
 
-	return points
+// sortScoreEvents orders solve and award events by time
+func sortScoreEvents(events []scoreEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
 }
 
 // buildTimelinePoint creates a timeline point with current scores for all teams
-func buildTimelinePoint(solve *models.Solve, allTeams []models.Team, teamScoresMap map[uint]int) timelinePoint {
+func buildTimelinePoint(at time.Time, allTeams []models.Team, teamScoresMap map[uint]int) timelinePoint {
 	point := timelinePoint{
-		Time:   solve.CreatedAt.Format("15:04"),
+		Time:   at.Format("15:04"),
 		Scores: make(map[string]int),
 	}
 
@@ -224,37 +240,46 @@ func buildTimelinePoint(solve *models.Solve, allTeams []models.Team, teamScoresM
 	return point
 }
 
-// GetLeaderboard calculates and returns team rankings with current points
+// GetLeaderboard returns team rankings from the materialized scoreboard, or its frozen state
 func GetLeaderboard(c *gin.Context) {
-	var teams []models.Team
-	if err := config.DB.Preload("Users").Find(&teams).Error; err != nil {
-		utils.InternalServerError(c, "failed_to_fetch_teams")
+	bracketID, ok := bracketParam(c)
+	if !ok {
 		return
 	}
 
-	decayService := utils.NewDecay()
-	var leaderboard []dto.TeamScore
+	entries, err := getScoreboardEntries(c, bracketID)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_scoreboard")
+		return
+	}
 
+	teamIDs := make([]uint, len(entries))
+	for i, entry := range entries {
+		teamIDs[i] = entry.TeamID
+	}
+	var teams []models.Team
+	if err := config.DB.Preload("Users").Preload("Bracket").Where("id IN ?", teamIDs).Find(&teams).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_teams")
+		return
+	}
+	teamsByID := make(map[uint]models.Team, len(teams))
 	for _, team := range teams {
-		totalScore, err := calculateTeamScore(team.ID, decayService)
-		if err != nil {
+		teamsByID[team.ID] = team
+	}
+
+	leaderboard := make([]dto.TeamScore, 0, len(entries))
+	for _, entry := range entries {
+		team, ok := teamsByID[entry.TeamID]
+		if !ok {
 			continue
 		}
-
-		hintsCost := getTeamHintsCost(team.ID)
-		finalScore := totalScore - hintsCost
-
-		var solveCount int64
-		config.DB.Model(&models.Solve{}).Where(queryTeamID, team.ID).Count(&solveCount)
-
 		leaderboard = append(leaderboard, dto.TeamScore{
 			Team:       team,
-			TotalScore: finalScore,
-			SolveCount: int(solveCount),
+			TotalScore: entry.TotalScore,
+			SolveCount: entry.SolveCount,
 		})
 	}
 
-	sortLeaderboard(leaderboard)
 	utils.OKResponse(c, leaderboard)
 }
 
@@ -274,14 +299,32 @@ func RecalculateTeamPoints(c *gin.Context) {
 		return
 	}
 
+	// First blood ranks are counted inside each bracket when enabled
+	var brackets map[uint]uint
+	if utils.FirstBloodPerBracket() {
+		var err error
+		if brackets, err = utils.TeamBrackets(); err != nil {
+			utils.InternalServerError(c, "failed_to_fetch_teams")
+			return
+		}
+	}
+
 	updatedCount := 0
-	challengePositions := make(map[uint]int) // Track position per challenge
+	challengePositions := make(map[uint]int)  // Track position per challenge
+	bracketPositions := make(map[[2]uint]int) // Track position per challenge and bracket
 
 	for _, solve := range solves {
 		position := challengePositions[solve.ChallengeID]
 		challengePositions[solve.ChallengeID]++
 
-		newPointsWithBonus, err := processSolveRecalculation(&solve, position, decayService)
+		bloodPosition := position
+		if brackets != nil {
+			key := [2]uint{solve.ChallengeID, brackets[solve.TeamID]}
+			bloodPosition = bracketPositions[key]
+			bracketPositions[key]++
+		}
+
+		newPointsWithBonus, err := processSolveRecalculation(&solve, position, bloodPosition, decayService)
 		if err != nil {
 			continue
 		}
@@ -297,9 +340,15 @@ func RecalculateTeamPoints(c *gin.Context) {
 		}
 	}
 
+	staleTeams, err := utils.RebuildScoreboard()
+	if err != nil {
+		log.Printf("Failed to rebuild scoreboard: %v", err)
+	}
+
 	utils.OKResponse(c, gin.H{
 		"message":        "points_recalculated",
 		"updated_solves": updatedCount,
+		"updated_teams":  staleTeams,
 	})
 }
 
@@ -331,42 +380,39 @@ func GetTeamScore(c *gin.Context) {
 		return
 	}
 
-	// Calculate total score with decay
-	decayService := utils.NewDecay()
-	totalScore, err := calculateTeamScore(*user.TeamID, decayService)
+	entry, err := utils.GetTeamScoreEntry(*user.TeamID)
 	if err != nil {
 		utils.InternalServerError(c, "failed_to_calculate_score")
 		return
 	}
 
-	// Get total spent on hints
-	var totalSpent int64
-	config.DB.Model(&models.HintPurchase{}).
-		Where("team_id = ?", *user.TeamID).
-		Select("COALESCE(SUM(cost), 0)").
-		Scan(&totalSpent)
-
 	utils.OKResponse(c, gin.H{
-		"totalScore":     totalScore,
-		"availableScore": totalScore - int(totalSpent),
-		"spentOnHints":   int(totalSpent),
+		"totalScore":     entry.SolvePoints + entry.AwardPoints,
+		"availableScore": entry.TotalScore,
+		"awardPoints":    entry.AwardPoints,
+		"spentOnHints":   entry.HintsCost,
 	})
 }
 
-// GetTeamTimeline returns solve activity timeline for all teams
+// GetTeamTimeline returns solve activity timeline for the top teams of the scoreboard
 func GetTeamTimeline(c *gin.Context) {
-	var teams []models.Team
-	if err := config.DB.Preload("Users").Find(&teams).Error; err != nil {
-		utils.InternalServerError(c, "failed_to_fetch_teams")
+	bracketID, ok := bracketParam(c)
+	if !ok {
 		return
 	}
 
-	decayService := utils.NewDecay()
+	entries, err := getScoreboardEntries(c, bracketID)
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_scoreboard")
+		return
+	}
 
-	// Sort teams by score and limit to top 10 for cleaner chart
-	allTeams := getTopTeams(teams, decayService, 10)
+	// Limit to top 10 for cleaner chart
+	if len(entries) > 10 {
+		entries = entries[:10]
+	}
 
-	if len(allTeams) == 0 {
+	if len(entries) == 0 {
 		utils.OKResponse(c, timelineResponse{
 			Teams:    []teamInfo{},
 			Timeline: []timelinePoint{},
@@ -374,14 +420,30 @@ func GetTeamTimeline(c *gin.Context) {
 		return
 	}
 
-	// Get all solves for all teams ordered by time
-	teamIDs := make([]uint, len(allTeams))
-	for i, team := range allTeams {
-		teamIDs[i] = team.ID
+	teamIDs := make([]uint, len(entries))
+	for i, entry := range entries {
+		teamIDs[i] = entry.TeamID
+	}
+
+	var teams []models.Team
+	if err := config.DB.Where("id IN ?", teamIDs).Find(&teams).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_teams")
+		return
+	}
+	teamsByID := make(map[uint]models.Team, len(teams))
+	for _, team := range teams {
+		teamsByID[team.ID] = team
+	}
+	allTeams := make([]models.Team, 0, len(entries))
+	for _, entry := range entries {
+		if team, ok := teamsByID[entry.TeamID]; ok {
+			allTeams = append(allTeams, team)
+		}
 	}
 
+	// Get all solves for all teams ordered by time
 	var allSolves []models.Solve
-	if err := config.DB.Where("team_id IN ?", teamIDs).
+	if err := config.DB.Scopes(visibleSolves(c)).Where("team_id IN ?", teamIDs).
 		Order("created_at ASC").
 		Find(&allSolves).Error; err != nil {
 		utils.InternalServerError(c, "failed_to_fetch_solves")
@@ -404,7 +466,22 @@ func GetTeamTimeline(c *gin.Context) {
 		}
 	}
 
-	timeline := buildTimeline(allSolves, allTeams, decayService)
+	var allAwards []models.Award
+	if err := config.DB.Scopes(visibleAwards(c)).Where("team_id IN ?", teamIDs).Find(&allAwards).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_awards")
+		return
+	}
+
+	events := make([]scoreEvent, 0, len(allSolves)+len(allAwards))
+	for _, solve := range allSolves {
+		events = append(events, scoreEvent{At: solve.CreatedAt, ID: solve.TeamID, Points: solve.Points})
+	}
+	for _, award := range allAwards {
+		events = append(events, scoreEvent{At: award.AwardedAt, ID: award.TeamID, Points: award.Value})
+	}
+	sortScoreEvents(events)
+
+	timeline := buildTimeline(events, allTeams)
 
 	utils.OKResponse(c, timelineResponse{
 		Teams:    teamsInfo,
@@ -412,21 +489,15 @@ func GetTeamTimeline(c *gin.Context) {
 	})
 }
 
-// buildTimeline constructs the timeline from solves
-func buildTimeline(allSolves []models.Solve, allTeams []models.Team, decayService *utils.DecayService) []timelinePoint {
+// buildTimeline constructs the timeline from sorted events, using the points stored on each solve
+func buildTimeline(events []scoreEvent, allTeams []models.Team) []timelinePoint {
 	timeline := []timelinePoint{}
 	teamScoresMap := make(map[uint]int)
 
-	for _, solve := range allSolves {
-		var challenge models.Challenge
-		if err := config.DB.Preload("DecayFormula").First(&challenge, solve.ChallengeID).Error; err != nil {
-			continue
-		}
-
-		points := calculateSolvePointsForTimeline(&solve, &challenge, decayService)
-		teamScoresMap[solve.TeamID] += points
+	for _, event := range events {
+		teamScoresMap[event.ID] += event.Points
 
-		point := buildTimelinePoint(&solve, allTeams, teamScoresMap)
+		point := buildTimelinePoint(event.At, allTeams, teamScoresMap)
 		timeline = append(timeline, point)
 	}
 
@@ -0,0 +1,338 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+	"gorm.io/gorm"
+)
+
+// needsSecondFactor tells if the login of a user must go through the second step, to enter
+// a code or to enrol when REQUIRE_ADMIN_2FA applies to them
+func needsSecondFactor(user *models.User) bool {
+	return user.TOTPEnabled || utils.TwoFactorRequired(user)
+}
+
+// verifySecondFactor accepts a current TOTP code or an unused recovery code of the user, then burns it
+func verifySecondFactor(user *models.User, code string) bool {
+	if !user.TOTPEnabled {
+		return false
+	}
+
+	if counter, ok := utils.ValidateTOTP(user.TOTPSecret, code, user.TOTPCounter); ok {
+		// The condition rejects a concurrent request that used the same code
+		result := config.DB.Model(&models.User{}).
+			Where("id = ? AND totp_counter < ?", user.ID, counter).
+			Update("totp_counter", counter)
+		return result.Error == nil && result.RowsAffected == 1
+	}
+
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashRecoveryCode(user.ID, code)).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// replaceRecoveryCodes generates new recovery codes for a user, invalidating the previous ones
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	records := make([]models.RecoveryCode, len(codes))
+	for i, code := range codes {
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: utils.HashRecoveryCode(userID, code)}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// beginTwoFactorSetup stores a new secret for the user and answers with what the authenticator app needs
+func beginTwoFactorSetup(c *gin.Context, user *models.User) {
+	if user.TOTPEnabled {
+		utils.ConflictError(c, "2fa_already_enabled")
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_setup_2fa")
+		return
+	}
+	if err := config.DB.Model(user).Update("totp_secret", secret).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_setup_2fa")
+		return
+	}
+
+	uri := utils.TOTPProvisioningURI(secret, user.Username)
+	qrCode, err := utils.QRCodeSVG(uri)
+	if err != nil {
+		debug.Log("Failed to draw the QR code of user %d: %v", user.ID, err)
+	}
+	utils.OKResponse(c, gin.H{
+		"secret": secret,
+		"uri":    uri,
+		"qrCode": qrCode,
+	})
+}
+
+// enableTwoFactor confirms the enrolment with a first code from the app and returns the recovery codes.
+// It answers the request itself when it fails.
+func enableTwoFactor(c *gin.Context, user *models.User) ([]string, bool) {
+	var input dto.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, ErrInvalidInput)
+		return nil, false
+	}
+	if user.TOTPEnabled {
+		utils.ConflictError(c, "2fa_already_enabled")
+		return nil, false
+	}
+	if user.TOTPSecret == "" {
+		utils.BadRequestError(c, "2fa_setup_required")
+		return nil, false
+	}
+
+	counter, ok := utils.ValidateTOTP(user.TOTPSecret, input.Code, user.TOTPCounter)
+	if !ok {
+		utils.BadRequestError(c, "invalid_2fa_code")
+		return nil, false
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"totp_enabled": true,
+			"totp_counter": counter,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_enable_2fa")
+		return nil, false
+	}
+	return codes, true
+}
+
+// GetPendingTwoFactorLogin tells the login page whether the second step asks for a code or an enrolment
+func GetPendingTwoFactorLogin(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+	utils.OKResponse(c, gin.H{"twoFactorSetup": !user.TOTPEnabled})
+}
+
+// LoginTwoFactor completes a login with a TOTP or recovery code
+func LoginTwoFactor(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var input dto.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, ErrInvalidInput)
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.BadRequestError(c, "2fa_setup_required")
+		return
+	}
+	if !verifySecondFactor(user, input.Code) {
+		utils.UnauthorizedError(c, "invalid_2fa_code")
+		return
+	}
+
+	utils.ClearPendingTwoFactor(c)
+	if err := generateAndSetTokens(c, user.ID, user.Role); err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "Login successful"})
+}
+
+// LoginTwoFactorSetup starts the enrolment required to finish a login when REQUIRE_ADMIN_2FA applies
+func LoginTwoFactorSetup(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+	if !utils.TwoFactorRequired(user) {
+		utils.ForbiddenError(c, "2fa_not_required")
+		return
+	}
+	beginTwoFactorSetup(c, user)
+}
+
+// LoginTwoFactorEnable completes the required enrolment and the login with it
+func LoginTwoFactorEnable(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+	if !utils.TwoFactorRequired(user) {
+		utils.ForbiddenError(c, "2fa_not_required")
+		return
+	}
+
+	codes, ok := enableTwoFactor(c, user)
+	if !ok {
+		return
+	}
+
+	utils.ClearPendingTwoFactor(c)
+	if err := generateAndSetTokens(c, user.ID, user.Role); err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+	utils.OKResponse(c, gin.H{"recoveryCodes": codes})
+}
+
+// GetTwoFactorStatus returns the two-factor state of the current user
+func GetTwoFactorStatus(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var recoveryCodesLeft int64
+	if user.TOTPEnabled {
+		config.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&recoveryCodesLeft)
+	}
+	utils.OKResponse(c, gin.H{
+		"enabled":           user.TOTPEnabled,
+		"required":          utils.TwoFactorRequired(user),
+		"recoveryCodesLeft": recoveryCodesLeft,
+	})
+}
+
+// SetupTwoFactor starts the enrolment of the current user
+func SetupTwoFactor(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+	beginTwoFactorSetup(c, user)
+}
+
+// EnableTwoFactor confirms the enrolment of the current user
+func EnableTwoFactor(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+	codes, ok := enableTwoFactor(c, user)
+	if !ok {
+		return
+	}
+	utils.OKResponse(c, gin.H{"recoveryCodes": codes})
+}
+
+// DisableTwoFactor turns two-factor authentication off for the current user, who proves it with a code
+func DisableTwoFactor(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var input dto.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, ErrInvalidInput)
+		return
+	}
+	if utils.TwoFactorRequired(user) {
+		utils.ForbiddenError(c, "2fa_required")
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.BadRequestError(c, "2fa_not_enabled")
+		return
+	}
+	if !verifySecondFactor(user, input.Code) {
+		utils.BadRequestError(c, "invalid_2fa_code")
+		return
+	}
+
+	if err := resetTwoFactor(user.ID); err != nil {
+		utils.InternalServerError(c, "failed_to_disable_2fa")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "2fa_disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user, who proves it with a code
+func RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var input dto.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, ErrInvalidInput)
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.BadRequestError(c, "2fa_not_enabled")
+		return
+	}
+	if !verifySecondFactor(user, input.Code) {
+		utils.BadRequestError(c, "invalid_2fa_code")
+		return
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		utils.InternalServerError(c, "failed_to_generate_recovery_codes")
+		return
+	}
+	utils.OKResponse(c, gin.H{"recoveryCodes": codes})
+}
+
+// resetTwoFactor removes the secret and recovery codes of a user
+func resetTwoFactor(userID uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_secret":  "",
+			"totp_enabled": false,
+			"totp_counter": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// ResetUserTwoFactor lets an admin turn off two-factor authentication for a user who lost their authenticator
+func ResetUserTwoFactor(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "user_not_found")
+		return
+	}
+	if err := resetTwoFactor(user.ID); err != nil {
+		utils.InternalServerError(c, "failed_to_reset_2fa")
+		return
+	}
+	utils.OKResponse(c, gin.H{"message": "2fa_reset"})
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
-	"strings"
 	"encoding/json"
+	"sort"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	"github.com/pwnthemall/pwnthemall/backend/config"
@@ -143,11 +145,9 @@ func GetCurrentUser(c *gin.Context) {
 	if user.TeamID != nil {
 		// Count of solves for the team
 		config.DB.Model(&models.Solve{}).Where("team_id = ?", *user.TeamID).Count(&solvesCount)
-		// Calculate current points with decay
-		decayService := utils.NewDecay()
-		score, err := calculateTeamScore(*user.TeamID, decayService)
-		if err == nil {
-			totalPoints = score
+		// Current points with decay, from the scoreboard
+		if entry, err := utils.GetTeamScoreEntry(*user.TeamID); err == nil {
+			totalPoints = entry.SolvePoints + entry.AwardPoints
 		}
 	}
 
@@ -204,8 +204,27 @@ func BanOrUnbanUser(c *gin.Context) {
 		return
 	}
 
-	user.Banned = !user.Banned
-	config.DB.Save(&user)
+	if err := setUserBanned(&user, !user.Banned); err != nil {
+		utils.InternalServerError(c, "failed_to_update_user")
+		return
+	}
+
+	utils.OKResponse(c, gin.H{"banned": user.Banned})
+}
+
+// setUserBanned updates the ban state of a user and notifies them when banned
+func setUserBanned(user *models.User, banned bool) error {
+	user.Banned = banned
+	if err := config.DB.Save(user).Error; err != nil {
+		return err
+	}
+
+	// A banned user is logged out of every device at once
+	if user.Banned {
+		if _, err := utils.RevokeUserSessions(user.ID, 0); err != nil {
+			return err
+		}
+	}
 
 	// Broadcast ban event to the specific user via WebSocket
 	if user.Banned && utils.UpdatesHub != nil {
@@ -215,8 +234,7 @@ func BanOrUnbanUser(c *gin.Context) {
 		})
 		utils.UpdatesHub.SendToUser(user.ID, payload)
 	}
-
-	utils.OKResponse(c, gin.H{"banned": user.Banned})
+	return nil
 }
 
 // GetUserByIP searches for users by IP address (admin only)
@@ -251,23 +269,61 @@ func GetUserByIP(c *gin.Context) {
 	utils.OKResponse(c, filteredUsers)
 }
 
+// individualScore is the score of a user in the individual leaderboard
+type individualScore struct {
+	UserID     uint
+	TotalScore int
+	SolveCount int
+}
+
+// individualScores sums the solves each user submitted and the awards credited to them, best score first.
+// Solves use their stored Points value, which already includes decay and first blood bonuses.
+func individualScores(c *gin.Context, bracketID uint) ([]individualScore, error) {
+	var solveScores []individualScore
+	if err := config.DB.Model(&models.Solve{}).Scopes(visibleSolves(c), bracketSolves(bracketID)).
+		Select("user_id, COALESCE(SUM(points), 0) as total_score, COUNT(*) as solve_count").
+		Group("user_id").
+		Scan(&solveScores).Error; err != nil {
+		return nil, err
+	}
+
+	var awardScores []individualScore
+	if err := config.DB.Model(&models.Award{}).Scopes(visibleAwards(c), bracketAwards(bracketID)).
+		Where("user_id IS NOT NULL").
+		Select("user_id, COALESCE(SUM(value), 0) as total_score").
+		Group("user_id").
+		Scan(&awardScores).Error; err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[uint]*individualScore, len(solveScores))
+	scores := make([]individualScore, 0, len(solveScores)+len(awardScores))
+	for _, score := range append(solveScores, awardScores...) {
+		if existing, ok := byUser[score.UserID]; ok {
+			existing.TotalScore += score.TotalScore
+			existing.SolveCount += score.SolveCount
+			continue
+		}
+		scores = append(scores, score)
+		byUser[score.UserID] = &scores[len(scores)-1]
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].TotalScore > scores[j].TotalScore
+	})
+	return scores, nil
+}
+
 // GetIndividualLeaderboard returns individual user rankings based on points from solves they submitted
-// Each user's score is the sum of points from solves where they were the submitter
+// Each user's score is the sum of points from solves where they were the submitter, plus their awards
 func GetIndividualLeaderboard(c *gin.Context) {
-	// Query to aggregate scores by user
-	// We use the stored Points value in each Solve record (already includes decay and first blood bonuses)
-	type userScore struct {
-		UserID     uint
-		TotalScore int
-		SolveCount int64
+	bracketID, ok := bracketParam(c)
+	if !ok {
+		return
 	}
 
-	var scores []userScore
-	if err := config.DB.Model(&models.Solve{}).
-		Select("user_id, COALESCE(SUM(points), 0) as total_score, COUNT(*) as solve_count").
-		Group("user_id").
-		Order("total_score DESC").
-		Scan(&scores).Error; err != nil {
+	scores, err := individualScores(c, bracketID)
+	if err != nil {
 		utils.InternalServerError(c, "failed_to_fetch_individual_scores")
 		return
 	}
@@ -296,7 +352,7 @@ func GetIndividualLeaderboard(c *gin.Context) {
 			User:       user,
 			TeamName:   teamName,
 			TotalScore: score.TotalScore,
-			SolveCount: int(score.SolveCount),
+			SolveCount: score.SolveCount,
 		})
 	}
 
@@ -324,22 +380,20 @@ type individualTimelineResponse struct {
 
 // GetIndividualTimeline returns solve activity timeline for top individual users
 func GetIndividualTimeline(c *gin.Context) {
-	// First, get top 10 users by total score
-	type userScore struct {
-		UserID     uint
-		TotalScore int
+	bracketID, ok := bracketParam(c)
+	if !ok {
+		return
 	}
 
-	var topScores []userScore
-	if err := config.DB.Model(&models.Solve{}).
-		Select("user_id, COALESCE(SUM(points), 0) as total_score").
-		Group("user_id").
-		Order("total_score DESC").
-		Limit(10).
-		Scan(&topScores).Error; err != nil {
+	// First, get top 10 users by total score
+	topScores, err := individualScores(c, bracketID)
+	if err != nil {
 		utils.InternalServerError(c, "failed_to_fetch_top_users")
 		return
 	}
+	if len(topScores) > 10 {
+		topScores = topScores[:10]
+	}
 
 	if len(topScores) == 0 {
 		utils.OKResponse(c, individualTimelineResponse{
@@ -388,24 +442,41 @@ func GetIndividualTimeline(c *gin.Context) {
 
 	// Get all solves for the top users ordered by time
 	var allSolves []models.Solve
-	if err := config.DB.Where("user_id IN ?", userIDs).
+	if err := config.DB.Scopes(visibleSolves(c), bracketSolves(bracketID)).Where("user_id IN ?", userIDs).
 		Order("created_at ASC").
 		Find(&allSolves).Error; err != nil {
 		utils.InternalServerError(c, "failed_to_fetch_solves")
 		return
 	}
 
+	// Awards credited to the top users
+	var allAwards []models.Award
+	if err := config.DB.Scopes(visibleAwards(c), bracketAwards(bracketID)).Where("user_id IN ?", userIDs).
+		Find(&allAwards).Error; err != nil {
+		utils.InternalServerError(c, "failed_to_fetch_awards")
+		return
+	}
+
+	events := make([]scoreEvent, 0, len(allSolves)+len(allAwards))
+	for _, solve := range allSolves {
+		events = append(events, scoreEvent{At: solve.CreatedAt, ID: solve.UserID, Points: solve.Points})
+	}
+	for _, award := range allAwards {
+		events = append(events, scoreEvent{At: award.AwardedAt, ID: *award.UserID, Points: award.Value})
+	}
+	sortScoreEvents(events)
+
 	// Build timeline
 	timeline := []individualTimelinePoint{}
 	userScoresMap := make(map[uint]int)
 
-	for _, solve := range allSolves {
-		// Add points from this solve
-		userScoresMap[solve.UserID] += solve.Points
+	for _, event := range events {
+		// Add points from this solve or award
+		userScoresMap[event.ID] += event.Points
 
 		// Create timeline point with current scores for all tracked users
 		point := individualTimelinePoint{
-			Time:   solve.CreatedAt.Format("15:04"),
+			Time:   event.At.Format("15:04"),
 			Scores: make(map[string]int),
 		}
 
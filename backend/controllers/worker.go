@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/dto"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// applyWorkerInput copies the request fields on a worker, keeping existing TLS material when omitted
+func applyWorkerInput(worker *models.Worker, input dto.WorkerInput) {
+	worker.Name = input.Name
+	worker.Host = input.Host
+	worker.PublicIP = input.PublicIP
+	worker.Capacity = input.Capacity
+	worker.Labels = input.Labels
+	if input.Enabled != nil {
+		worker.Enabled = *input.Enabled
+	}
+	if input.TLSCACert != "" {
+		worker.TLSCACert = input.TLSCACert
+	}
+	if input.TLSCert != "" {
+		worker.TLSCert = input.TLSCert
+	}
+	if input.TLSKey != "" {
+		worker.TLSKey = input.TLSKey
+	}
+}
+
+// GetWorkers returns every docker worker with its current instance count
+func GetWorkers(c *gin.Context) {
+	var workers []models.Worker
+	if err := config.DB.Order("id").Find(&workers).Error; err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	type workerWithLoad struct {
+		models.Worker
+		Instances int64 `json:"instances"`
+		TLS       bool  `json:"tls"`
+	}
+	result := make([]workerWithLoad, 0, len(workers))
+	for _, worker := range workers {
+		var count int64
+		config.DB.Model(&models.Instance{}).Where("worker_id = ?", worker.ID).Count(&count)
+		result = append(result, workerWithLoad{Worker: worker, Instances: count, TLS: worker.HasTLS()})
+	}
+
+	utils.OKResponse(c, result)
+}
+
+// CreateWorker registers a new docker worker and checks it right away
+func CreateWorker(c *gin.Context) {
+	var input dto.WorkerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
+	worker := models.Worker{Enabled: true}
+	applyWorkerInput(&worker, input)
+	if err := config.DB.Create(&worker).Error; err != nil {
+		utils.ConflictError(c, "worker_already_exists")
+		return
+	}
+
+	utils.CheckWorkerHealth(&worker)
+	utils.CreatedResponse(c, worker)
+}
+
+// UpdateWorker updates a docker worker and reconnects to it
+func UpdateWorker(c *gin.Context) {
+	var worker models.Worker
+	if err := config.DB.First(&worker, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "worker_not_found")
+		return
+	}
+
+	var input dto.WorkerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequestError(c, err.Error())
+		return
+	}
+
+	applyWorkerInput(&worker, input)
+	if err := config.DB.Save(&worker).Error; err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	config.ResetWorkerClient(worker.ID)
+	utils.CheckWorkerHealth(&worker)
+	utils.OKResponse(c, worker)
+}
+
+// DeleteWorker removes a docker worker that has no running instance
+func DeleteWorker(c *gin.Context) {
+	var worker models.Worker
+	if err := config.DB.First(&worker, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "worker_not_found")
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Instance{}).Where("worker_id = ?", worker.ID).Count(&count)
+	if count > 0 {
+		utils.ConflictError(c, "worker_has_instances")
+		return
+	}
+
+	if err := config.DB.Delete(&worker).Error; err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	config.ResetWorkerClient(worker.ID)
+	utils.OKResponse(c, gin.H{"message": "worker_deleted"})
+}
+
+// CheckWorker pings a docker worker and returns its health
+func CheckWorker(c *gin.Context) {
+	var worker models.Worker
+	if err := config.DB.First(&worker, c.Param("id")).Error; err != nil {
+		utils.NotFoundError(c, "worker_not_found")
+		return
+	}
+
+	config.ResetWorkerClient(worker.ID)
+	utils.CheckWorkerHealth(&worker)
+	utils.OKResponse(c, worker)
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+)
+
+func setupWorkerDB(t *testing.T) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Worker{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+}
+
+func TestCreateWorkerKeepsEnabledFlag(t *testing.T) {
+	setupWorkerDB(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/workers", CreateWorker)
+
+	for _, tc := range []struct {
+		name    string
+		body    string
+		enabled bool
+	}{
+		{"disabled", `{"name": "disabled", "host": "tcp://127.0.0.1:1", "enabled": false}`, false},
+		{"default", `{"name": "default", "host": "tcp://127.0.0.1:1"}`, true},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/workers", strings.NewReader(tc.body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("%s: status %d: %s", tc.name, rec.Code, rec.Body)
+		}
+
+		var worker models.Worker
+		if err := config.DB.Where("name = ?", tc.name).First(&worker).Error; err != nil {
+			t.Fatalf("%s: read back: %v", tc.name, err)
+		}
+		if worker.Enabled != tc.enabled {
+			t.Errorf("%s: enabled = %v, want %v", tc.name, worker.Enabled, tc.enabled)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
 // RegisterInput represents user registration request
 type RegisterInput struct {
 	Username string `json:"username" binding:"required,max=32"`
@@ -13,3 +19,43 @@ type LoginInput struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+// APITokenInput represents a personal API token creation request
+type APITokenInput struct {
+	Name      string     `json:"name" binding:"required,max=64"`
+	Scope     string     `json:"scope" binding:"required,oneof=read submit admin"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreatedAPIToken is returned once on creation, the only time the token is shown in clear
+type CreatedAPIToken struct {
+	models.APIToken
+	Token string `json:"token"`
+}
+
+// MailTokenInput carries a token received by email
+type MailTokenInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// EmailInput asks for a mail to be sent to an address
+type EmailInput struct {
+	Email string `json:"email" binding:"required,email,max=254"`
+}
+
+// ResetPasswordInput sets a new password with a reset token
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// TwoFactorCodeInput carries a TOTP code or a recovery code
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// SessionInfo is a session listed to its user or an admin
+type SessionInfo struct {
+	models.Session
+	Current bool `json:"current"` // Session of the request
+}
@@ -57,11 +57,18 @@ type HintWithPurchased struct {
 // ChallengeWithSolved represents a challenge with solve status and hints
 type ChallengeWithSolved struct {
 	models.Challenge
-	Solved             bool                `json:"solved"`
-	Locked             bool                `json:"locked,omitempty"`       // True if depends_on requirement not met
-	Hints              []HintWithPurchased `json:"hints,omitempty"`
-	GeoRadiusKm        *float64            `json:"geoRadiusKm,omitempty"`
-	TeamFailedAttempts int64               `json:"teamFailedAttempts,omitempty"`
+	Solved               bool                  `json:"solved"`
+	Locked               bool                  `json:"locked,omitempty"` // True if the prerequisites are not met
+	MissingPrerequisites []models.Prerequisite `json:"missingPrerequisites,omitempty"`
+	Hints                []HintWithPurchased   `json:"hints,omitempty"`
+	GeoRadiusKm          *float64              `json:"geoRadiusKm,omitempty"`
+	TeamFailedAttempts   int64                 `json:"teamFailedAttempts,omitempty"`
+}
+
+// ArchivedChallenge represents an archived challenge awaiting admin review
+type ArchivedChallenge struct {
+	models.Challenge
+	SolveCount int64 `json:"solveCount"`
 }
 
 // SolveWithUser represents a solve with user information
@@ -31,4 +31,6 @@ type AdminInstanceDTO struct {
 	Status        string    `json:"status"`
 	CreatedAt     time.Time `json:"createdAt"`
 	ExpiresAt     time.Time `json:"expiresAt"`
+	WorkerID      *uint     `json:"workerId"`
+	WorkerName    string    `json:"workerName"`
 }
@@ -0,0 +1,67 @@
+package dto
+
+import "time"
+
+// CTFtimeFeed is the scoreboard feed format imported by CTFtime
+type CTFtimeFeed struct {
+	Tasks     []string          `json:"tasks"`
+	Standings []CTFtimeStanding `json:"standings"`
+}
+
+// CTFtimeStanding is one team of the CTFtime feed
+type CTFtimeStanding struct {
+	Pos        int                        `json:"pos"`
+	Team       string                     `json:"team"`
+	Score      int                        `json:"score"`
+	TaskStats  map[string]CTFtimeTaskStat `json:"taskStats,omitempty"`
+	LastAccept int64                      `json:"lastAccept,omitempty"`
+}
+
+// CTFtimeTaskStat is a solve of the CTFtime feed
+type CTFtimeTaskStat struct {
+	Points int   `json:"points"`
+	Time   int64 `json:"time"`
+}
+
+// StandingExport is one row of the standings export
+type StandingExport struct {
+	Pos         int        `json:"pos"`
+	TeamID      uint       `json:"teamId"`
+	Team        string     `json:"team"`
+	Bracket     string     `json:"bracket"`
+	Score       int        `json:"score"`
+	SolvePoints int        `json:"solvePoints"`
+	AwardPoints int        `json:"awardPoints"`
+	HintsCost   int        `json:"hintsCost"`
+	SolveCount  int        `json:"solveCount"`
+	LastSolveAt *time.Time `json:"lastSolveAt"`
+}
+
+// SolveExport is one row of the solves export
+type SolveExport struct {
+	ChallengeID   uint      `json:"challengeId"`
+	Challenge     string    `json:"challenge"`
+	Category      string    `json:"category"`
+	TeamID        uint      `json:"teamId"`
+	Team          string    `json:"team"`
+	UserID        uint      `json:"userId"`
+	Username      string    `json:"username"`
+	Position      int       `json:"position"`
+	Points        int       `json:"points"`
+	PointsAtSolve int       `json:"pointsAtSolve"`
+	SolvedAt      time.Time `json:"solvedAt"`
+}
+
+// FirstBloodExport is one row of the first bloods export
+type FirstBloodExport struct {
+	ChallengeID uint      `json:"challengeId"`
+	Challenge   string    `json:"challenge"`
+	TeamID      uint      `json:"teamId"`
+	Team        string    `json:"team"`
+	Bracket     string    `json:"bracket"`
+	UserID      uint      `json:"userId"`
+	Username    string    `json:"username"`
+	Bonus       int64     `json:"bonus"`
+	Badge       string    `json:"badge"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -15,3 +15,17 @@ type SubmissionResponse struct {
 	ChallengeID uint                    `json:"challengeId"`
 	Challenge   *models.Challenge       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"challenge,omitempty"`
 }
+
+type SuspiciousActivityResponse struct {
+	ID          uint                    `json:"id"`
+	Type        string                  `json:"type"`
+	Status      string                  `json:"status"`
+	User        models.SafeUserWithTeam `json:"user"`
+	Team        models.SafeTeam         `json:"team"`
+	SourceTeam  models.SafeTeam         `json:"sourceTeam"`
+	ChallengeID uint                    `json:"challengeId"`
+	Challenge   string                  `json:"challenge"`
+	AutoBanned  bool                    `json:"autoBanned"`
+	ReviewedAt  *time.Time              `json:"reviewedAt,omitempty"`
+	CreatedAt   time.Time               `json:"createdAt"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/pwnthemall/pwnthemall/backend/models"
+import (
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
 
 // CreateTeamInput represents team creation request
 type CreateTeamInput struct {
@@ -21,3 +25,24 @@ type TeamScore struct {
 	TotalScore int         `json:"totalScore"`
 	SolveCount int         `json:"solveCount"`
 }
+
+// BracketInput represents bracket creation/update request
+type BracketInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// TeamBracketInput represents the bracket assigned to a team, null to remove it
+type TeamBracketInput struct {
+	BracketID *uint `json:"bracketId"`
+}
+
+// AwardInput represents award creation/update request, a negative value is a penalty
+type AwardInput struct {
+	TeamID    uint       `json:"teamId" binding:"required"`
+	UserID    *uint      `json:"userId"`
+	Value     int        `json:"value"`
+	Reason    string     `json:"reason" binding:"required"`
+	Category  string     `json:"category" binding:"max=50"`
+	AwardedAt *time.Time `json:"awardedAt"`
+}
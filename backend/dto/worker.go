@@ -0,0 +1,14 @@
+package dto
+
+// WorkerInput represents docker worker creation/update request
+type WorkerInput struct {
+	Name      string   `json:"name" binding:"required,max=100"`
+	Host      string   `json:"host" binding:"required"`
+	PublicIP  string   `json:"publicIp"`
+	TLSCACert string   `json:"tlsCaCert"`
+	TLSCert   string   `json:"tlsCert"`
+	TLSKey    string   `json:"tlsKey"`
+	Capacity  int      `json:"capacity" binding:"min=0"`
+	Labels    []string `json:"labels"`
+	Enabled   *bool    `json:"enabled"`
+}
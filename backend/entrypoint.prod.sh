@@ -16,6 +16,7 @@ esac
 if [ "$PTA_PLUGINS_ENABLED" = "true" ]; then
     echo "Host libvirt-worker
         StrictHostKeyChecking no
-        UserKnownHostsFile /dev/null" >> /home/app/.ssh/config
+        UserKnownHostsFile /dev/null
+        IdentityFile /home/app/.ssh/libvirt-worker" >> /home/app/.ssh/config
 fi
 /app/pwnthemall
\ No newline at end of file
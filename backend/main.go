@@ -14,6 +14,7 @@ import (
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
 	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"github.com/pwnthemall/pwnthemall/backend/pluginsystem"
 	"github.com/pwnthemall/pwnthemall/backend/routes"
@@ -26,6 +27,13 @@ var (
 	cleanDemo     = flag.Bool("clean-demo", false, "Remove all demo data from the database")
 	seedTeams     = flag.Int("teams", 30, "Number of demo teams to create (default: 30)")
 	seedTimeRange = flag.Int("time-range", 20, "Time range in hours for spreading solve timestamps (default: 20)")
+	rebuildScores = flag.Bool("rebuild-scoreboard", false, "Recompute the materialized scoreboard and report out of date teams")
+)
+
+// CLI flags for challenge archives
+var (
+	exportChallenges = flag.String("export-challenges", "", "Export all challenges to the given tar.gz archive")
+	importChallenges = flag.String("import-challenges", "", "Import challenges from the given tar.gz archive")
 )
 
 func generateRandomString(n int) (string, error) {
@@ -36,6 +44,38 @@ func generateRandomString(n int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// runScoreboardRebuild recomputes the scoreboard and reports how many teams were out of date
+func runScoreboardRebuild() {
+	stale, err := utils.RebuildScoreboard()
+	if err != nil {
+		log.Fatalf("Failed to rebuild scoreboard: %v", err)
+	}
+	log.Printf("Scoreboard rebuilt: %d team(s) were out of date", stale)
+}
+
+// runChallengeExport writes the challenge archive to a file
+func runChallengeExport(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := utils.ExportChallengesArchive(context.Background(), file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// runChallengeImport imports a challenge archive from a file
+func runChallengeImport(path string) (*utils.ArchiveImportResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return utils.ImportChallengesArchive(context.Background(), file, nil)
+}
+
 // initWebSocketHub initializes the WebSocket hubs
 func initWebSocketHub() {
 	utils.WebSocketHub = utils.NewHub()
@@ -49,7 +89,7 @@ func main() {
 	flag.Parse()
 
 	// Handle CLI seeding commands (requires only DB connection)
-	if *seedDemo || *cleanDemo {
+	if *seedDemo || *cleanDemo || *rebuildScores {
 		config.ConnectDB()
 
 		if *cleanDemo {
@@ -57,6 +97,7 @@ func main() {
 			if err := config.CleanDemoData(); err != nil {
 				log.Fatalf("Failed to clean demo data: %v", err)
 			}
+			runScoreboardRebuild()
 			log.Println("Demo data cleaned successfully")
 			os.Exit(0)
 		}
@@ -66,9 +107,40 @@ func main() {
 			if err := config.SeedDemoData(*seedTeams, *seedTimeRange); err != nil {
 				log.Fatalf("Failed to seed demo data: %v", err)
 			}
+			runScoreboardRebuild()
 			log.Println("Demo data seeded successfully")
 			os.Exit(0)
 		}
+
+		log.Println("Running: rebuild-scoreboard")
+		runScoreboardRebuild()
+		os.Exit(0)
+	}
+
+	// Handle CLI archive commands (requires DB and MinIO connections)
+	if *exportChallenges != "" || *importChallenges != "" {
+		config.ConnectDB()
+		config.ConnectMinio()
+
+		if *exportChallenges != "" {
+			log.Printf("Running: export-challenges (%s)\n", *exportChallenges)
+			if err := runChallengeExport(*exportChallenges); err != nil {
+				log.Fatalf("Failed to export challenges: %v", err)
+			}
+			log.Println("Challenges exported successfully")
+			os.Exit(0)
+		}
+
+		log.Printf("Running: import-challenges (%s)\n", *importChallenges)
+		result, err := runChallengeImport(*importChallenges)
+		if err != nil {
+			log.Fatalf("Failed to import challenges: %v", err)
+		}
+		for _, importErr := range result.Errors {
+			log.Printf("Import error: %s", importErr)
+		}
+		log.Printf("Challenges imported: %d files, %d challenges", result.Files, result.Challenges)
+		os.Exit(0)
 	}
 
 	config.ConnectDB()
@@ -81,6 +153,8 @@ func main() {
 
 	initWebSocketHub()
 
+	utils.CheckMailConfig()
+
 	// Sync all challenges from MinIO on startup
 	log.Println("INFO: Launching initial challenge sync goroutine...")
 	go func() {
@@ -92,9 +166,30 @@ func main() {
 		}
 	}()
 
+	// Record the subnets of team networks created before subnet leases
+	go utils.SeedSubnetLeases()
+
+	// Make sure the materialized scoreboard matches solves, hints and decay
+	go func() {
+		if stale, err := utils.RebuildScoreboard(); err != nil {
+			log.Printf("Warning: scoreboard rebuild failed: %v", err)
+		} else if stale > 0 {
+			log.Printf("INFO: Scoreboard rebuilt, %d team(s) were out of date", stale)
+		}
+	}()
+
 	// Start hint activation scheduler
 	utils.StartHintScheduler()
 
+	// Start challenge release scheduler
+	utils.StartChallengeScheduler()
+
+	// Start expired instance reaper
+	controllers.StartInstanceReaper()
+
+	// Start expired session cleanup
+	utils.StartSessionCleanup()
+
 	router := gin.Default()
 
 	sessionSecret := os.Getenv("SESSION_SECRET")
@@ -131,11 +226,15 @@ func main() {
 	routes.RegisterTeamRoutes(router)
 	routes.RegisterConfigRoutes(router)
 	routes.RegisterDockerConfigRoutes(router)
+	routes.RegisterWorkerRoutes(router)
 	routes.RegisterInstanceRoutes(router)
 	routes.RegisterNotificationRoutes(router)
 	routes.RegisterDecayFormulaRoutes(router)
 	routes.RegisterSubmissionRoutes(router)
 	routes.RegisterDashboardRoutes(router)
+	routes.RegisterScoreboardRoutes(router)
+	routes.RegisterBracketRoutes(router)
+	routes.RegisterAwardRoutes(router)
 
 	if os.Getenv("PTA_PLUGINS_ENABLED") == "true" {
 		debug.Log("Loading plugins...")
@@ -0,0 +1,42 @@
+package meta
+
+import "time"
+
+// ArchiveVersion is the format version written in exported archives
+const ArchiveVersion = 1
+
+// ArchiveManifest describes the event-wide data stored next to the challenge folders of an archive
+type ArchiveManifest struct {
+	Version       int                      `json:"version"`
+	ExportedAt    time.Time                `json:"exportedAt"`
+	Categories    []string                 `json:"categories"`
+	Difficulties  []string                 `json:"difficulties"`
+	DecayFormulas []ArchiveDecayFormula    `json:"decayFormulas"`
+	Badges        []ArchiveBadge           `json:"badges"`
+	Challenges    []ArchiveChallengeLayout `json:"challenges"`
+}
+
+type ArchiveDecayFormula struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Step      int    `json:"step"`
+	MinPoints int    `json:"minPoints"`
+	Interval  int    `json:"interval"`
+}
+
+type ArchiveBadge struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Color       string `json:"color"`
+	Type        string `json:"type"`
+}
+
+// ArchiveChallengeLayout holds the display settings that are not part of chall.yml
+type ArchiveChallengeLayout struct {
+	Slug           string  `json:"slug"`
+	Order          int     `json:"order"`
+	CoverPositionX float64 `json:"coverPositionX"`
+	CoverPositionY float64 `json:"coverPositionY"`
+	CoverZoom      float64 `json:"coverZoom"`
+}
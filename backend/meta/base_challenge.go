@@ -1,25 +1,53 @@
 package meta
 
 type BaseChallengeMetadata struct {
-	Name             string              `yaml:"name"`
-	Description      string              `yaml:"description"`
-	Category         string              `yaml:"category"`
-	Difficulty       string              `yaml:"difficulty"`
-	Type             string              `yaml:"type"`
-	Author           string              `yaml:"author"`
-	Hidden           bool                `yaml:"hidden"`
-	Flags            []string            `yaml:"flags"`
-	Files            []string            `yaml:"files,omitempty"`
-	Points           int                 `yaml:"points"`
-	ConnectionInfo   []string            `yaml:"connection_info,omitempty"`
-	DecayFormula     string              `yaml:"decay,omitempty"`
-	Hints            []HintMetadata      `yaml:"hints,omitempty"`
-	EnableFirstBlood bool                `yaml:"enableFirstBlood"`
-	FirstBlood       *FirstBloodMetadata `yaml:"firstBlood,omitempty"`
-	Attempts         int                 `yaml:"attempts,omitempty"`   // Max submission attempts (0 = unlimited)
-	DependsOn        string              `yaml:"depends_on,omitempty"` // Name of challenge that must be solved first
-	CoverImg         string              `yaml:"cover_img,omitempty"`  // Cover image filename relative to challenge folder
-	Emoji            string              `yaml:"emoji,omitempty"`      // Emoji to display when no cover image
+	Name             string                 `yaml:"name"`
+	Description      string                 `yaml:"description"`
+	Category         string                 `yaml:"category"`
+	Difficulty       string                 `yaml:"difficulty"`
+	Type             string                 `yaml:"type"`
+	Author           string                 `yaml:"author"`
+	Hidden           bool                   `yaml:"hidden"`
+	Flags            []FlagMetadata         `yaml:"flags"`
+	Files            []string               `yaml:"files,omitempty"`
+	Points           int                    `yaml:"points"`
+	ConnectionInfo   []string               `yaml:"connection_info,omitempty"`
+	DecayFormula     DecayFormula           `yaml:"decay,omitempty"`
+	Hints            []HintMetadata         `yaml:"hints,omitempty"`
+	EnableFirstBlood bool                   `yaml:"enableFirstBlood"`
+	FirstBlood       *FirstBloodMetadata    `yaml:"firstBlood,omitempty"`
+	Attempts         int                    `yaml:"attempts,omitempty"`      // Max submission attempts (0 = unlimited)
+	DependsOn        string                 `yaml:"depends_on,omitempty"`    // Name of challenge that must be solved first
+	Prerequisites    []PrerequisiteMetadata `yaml:"prerequisites,omitempty"` // Conditions to unlock the challenge, all must be met
+	CoverImg         string                 `yaml:"cover_img,omitempty"`     // Cover image filename relative to challenge folder
+	Emoji            string                 `yaml:"emoji,omitempty"`         // Emoji to display when no cover image
+	DynamicFlag      bool                   `yaml:"dynamic_flag,omitempty"`  // Generate a unique flag per team, injected into the instance
+	ReleaseAt        string                 `yaml:"release_at,omitempty"`    // RFC3339 time the challenge is revealed at
+	HideAt           string                 `yaml:"hide_at,omitempty"`       // RFC3339 time the challenge is hidden again at
+	WorkerLabels     []string               `yaml:"worker_labels,omitempty"` // Labels a worker needs to run the instances
+}
+
+// FlagMetadata accepts either a plain string (static flag) or a {value, type} mapping
+type FlagMetadata struct {
+	Value string `yaml:"value"`
+	Type  string `yaml:"type,omitempty"` // static (default), static_ci, regex
+}
+
+func (f *FlagMetadata) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		f.Value = value
+		f.Type = ""
+		return nil
+	}
+
+	type rawFlag FlagMetadata
+	var raw rawFlag
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*f = FlagMetadata(raw)
+	return nil
 }
 
 type HintMetadata struct {
@@ -35,8 +63,32 @@ type FirstBloodMetadata struct {
 	Badges  []string `yaml:"badges"`
 }
 
+// DecayFormula accepts either the name of an existing formula or an inline {type, step, min_points, interval} mapping
 type DecayFormula struct {
-	Name      string `yaml:"name"`
-	Step      int    `yaml:"step"`
-	MinPoints int    `yaml:"min_points"`
+	Name      string `yaml:"name,omitempty"`
+	Type      string `yaml:"type,omitempty"` // fixed, logarithmic, dynamic, time
+	Step      int    `yaml:"step,omitempty"`
+	MinPoints int    `yaml:"min_points,omitempty"`
+	Interval  int    `yaml:"interval,omitempty"` // Minutes between two point drops (time)
+}
+
+func (d *DecayFormula) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*d = DecayFormula{Name: name}
+		return nil
+	}
+
+	type rawDecay DecayFormula
+	var raw rawDecay
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*d = DecayFormula(raw)
+	return nil
+}
+
+// IsInline tells if the formula is defined in chall.yml rather than referenced by name
+func (d DecayFormula) IsInline() bool {
+	return d.Type != ""
 }
@@ -0,0 +1,28 @@
+package meta
+
+// PrerequisiteMetadata accepts either a plain challenge slug or a mapping with one of
+// all, any (with an optional count), category (with count) or min_score
+type PrerequisiteMetadata struct {
+	Challenge string                 `yaml:"challenge,omitempty"`
+	All       []PrerequisiteMetadata `yaml:"all,omitempty"`
+	Any       []PrerequisiteMetadata `yaml:"any,omitempty"`
+	Category  string                 `yaml:"category,omitempty"`
+	Count     int                    `yaml:"count,omitempty"`
+	MinScore  int                    `yaml:"min_score,omitempty"`
+}
+
+func (p *PrerequisiteMetadata) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var slug string
+	if err := unmarshal(&slug); err == nil {
+		*p = PrerequisiteMetadata{Challenge: slug}
+		return nil
+	}
+
+	type rawPrerequisite PrerequisiteMetadata
+	var raw rawPrerequisite
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*p = PrerequisiteMetadata(raw)
+	return nil
+}
@@ -0,0 +1,9 @@
+package meta
+
+type VMChallengeMetadata struct {
+	Base      BaseChallengeMetadata `yaml:",inline"`
+	DiskImage string                `yaml:"disk_image"` // qcow2 image path inside the challenge folder
+	Memory    int                   `yaml:"memory"`     // MiB
+	VCPUs     int                   `yaml:"vcpus"`
+	Ports     []int                 `yaml:"ports"`
+}
@@ -121,7 +121,7 @@ func SessionAuthRequired(needTeam bool) gin.HandlerFunc {
 
 func CheckPolicy(obj string, act string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, errMsg := utils.GetClaimsFromCookie(c)
+		claims, errMsg := utils.GetRequestClaims(c)
 		if claims == nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errMsg})
 			return
@@ -154,17 +154,26 @@ func CheckPolicy(obj string, act string) gin.HandlerFunc {
 			return
 		}
 
+		if !utils.APITokenScopeAllows(claims.Scope, c.Request.Method, c.FullPath()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient_scope"})
+			return
+		}
+
 		c.Next()
 	}
 }
 
 func AuthRequired(needTeam bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := utils.GetClaimsFromCookie(c)
+		claims, err := utils.GetRequestClaims(c)
 		if claims == nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err})
 			return
 		}
+		if !utils.APITokenScopeAllows(claims.Scope, c.Request.Method, c.FullPath()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient_scope"})
+			return
+		}
 		var user models.User
 		if err := config.DB.Preload("Team").First(&user, claims.UserID).Error; err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
@@ -192,11 +201,15 @@ func AuthRequired(needTeam bool) gin.HandlerFunc {
 
 func AuthRequiredTeamOrAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := utils.GetClaimsFromCookie(c)
+		claims, err := utils.GetRequestClaims(c)
 		if claims == nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err})
 			return
 		}
+		if !utils.APITokenScopeAllows(claims.Scope, c.Request.Method, c.FullPath()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient_scope"})
+			return
+		}
 		var user models.User
 		if err := config.DB.Preload("Team").First(&user, claims.UserID).Error; err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
@@ -220,4 +233,41 @@ func AuthRequiredTeamOrAdmin() gin.HandlerFunc {
 		c.Set("user", &user)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// SessionOnly refuses requests authenticated with an API token, for account and token management
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_token_id"); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api_token_not_allowed"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// TwoFactorPending loads the user waiting for the second login step, set by a successful first step
+func TwoFactorPending() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := utils.GetPendingTwoFactor(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "2fa_login_expired"})
+			return
+		}
+		var user models.User
+		if err := config.DB.First(&user, userID).Error; err != nil {
+			utils.ClearPendingTwoFactor(c)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "2fa_login_expired"})
+			return
+		}
+		if user.Banned {
+			utils.ClearPendingTwoFactor(c)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "banned"})
+			return
+		}
+
+		c.Set("user_id", user.ID)
+		c.Set("user", &user)
+		c.Next()
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"github.com/pwnthemall/pwnthemall/backend/utils"
+)
+
+// ChallengeUnlocked refuses access to the challenge of the route while it is hidden or until the team meets its
+// prerequisites. It runs after AuthRequiredTeamOrAdmin, admins always pass.
+func ChallengeUnlocked() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requireUnlocked(c, c.Param("id"))
+	}
+}
+
+// HintChallengeUnlocked refuses access to the hint of the route until the team unlocked its challenge
+func HintChallengeUnlocked() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var hint models.Hint
+		if err := config.DB.Select("id", "challenge_id").First(&hint, c.Param("id")).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "hint_not_found"})
+			return
+		}
+		requireUnlocked(c, hint.ChallengeID)
+	}
+}
+
+// ChallengeVisible answers 404 to non-admins for a hidden challenge, so unreleased or archived challenges cannot be
+// reached by ID. It runs after an authentication middleware.
+func ChallengeVisible() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := requestUser(c)
+		if !ok {
+			return
+		}
+		if user.Role == "admin" {
+			c.Next()
+			return
+		}
+		if _, ok := loadVisibleChallenge(c, c.Param("id")); ok {
+			c.Next()
+		}
+	}
+}
+
+// requestUser returns the authenticated user of the request, aborting it when missing
+func requestUser(c *gin.Context) (*models.User, bool) {
+	userI, ok := c.Get("user")
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, false
+	}
+	user, ok := userI.(*models.User)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "user_wrong_type"})
+		return nil, false
+	}
+	return user, true
+}
+
+// loadVisibleChallenge loads a challenge that is not hidden, aborting the request with a 404 otherwise
+func loadVisibleChallenge(c *gin.Context, challengeID interface{}) (*models.Challenge, bool) {
+	var challenge models.Challenge
+	if err := config.DB.Select("id", "hidden", "depends_on", "prerequisites").First(&challenge, challengeID).Error; err != nil || challenge.Hidden {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "challenge_not_found"})
+		return nil, false
+	}
+	return &challenge, true
+}
+
+// requireUnlocked checks the visibility and prerequisites of a challenge for the team of the request
+func requireUnlocked(c *gin.Context, challengeID interface{}) {
+	user, ok := requestUser(c)
+	if !ok {
+		return
+	}
+	if user.Role == "admin" {
+		c.Next()
+		return
+	}
+
+	challenge, ok := loadVisibleChallenge(c, challengeID)
+	if !ok {
+		return
+	}
+	if user.TeamID == nil {
+		c.Next()
+		return
+	}
+
+	missing, err := utils.MissingPrerequisites(*user.TeamID, challenge)
+	if err != nil {
+		debug.Log("Failed to check prerequisites of challenge %d: %v", challenge.ID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed_to_check_prerequisites"})
+		return
+	}
+	if len(missing) > 0 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "challenge_locked", "missingPrerequisites": missing})
+		return
+	}
+	c.Next()
+}
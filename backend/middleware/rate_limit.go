@@ -1,57 +1,235 @@
 package middleware
 
 import (
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
 )
 
-type rateLimiter struct {
-	attempts  map[string][]time.Time
-	mu        sync.RWMutex
-	maxTries  int
+// RateLimitScope selects who shares a rate limit
+type RateLimitScope int
+
+const (
+	RateLimitPerIP   RateLimitScope = iota // Every client IP has its own limit
+	RateLimitPerUser                       // Every user has its own limit, IP when anonymous
+	RateLimitPerTeam                       // Every team shares a limit, user then IP without team
+)
+
+// Rate limit algorithms, selected with the RATE_LIMIT_ALGORITHM config
+const (
+	RateLimitSlidingWindow = "sliding_window"
+	RateLimitTokenBucket   = "token_bucket"
+)
+
+// rateLimitRefresh is how long a limit read from the config is kept before reading it again
+const rateLimitRefresh = 30 * time.Second
+
+// rateLimitRule is a number of requests allowed per window with the algorithm enforcing it
+type rateLimitRule struct {
+	limit     int
 	window    time.Duration
+	algorithm string
 }
 
-var joinTeamLimiter = &rateLimiter{
-	attempts: make(map[string][]time.Time),
-	maxTries: 5,
-	window:   1 * time.Minute,
+// rateLimitEntry is the state of one key: request times for the sliding window, tokens for the bucket
+type rateLimitEntry struct {
+	requests []time.Time
+	tokens   float64
+	last     time.Time
 }
 
-func RateLimitJoinTeam() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.Next()
-			return
-		}
+type rateLimiter struct {
+	configKey    string
+	defaultLimit string
+	scope        RateLimitScope
 
-		key := string(rune(userID.(uint)))
-		now := time.Now()
+	mu          sync.Mutex
+	entries     map[string]*rateLimitEntry
+	rule        rateLimitRule
+	loadedAt    time.Time
+	lastCleanup time.Time
+}
 
-		joinTeamLimiter.mu.Lock()
-		defer joinTeamLimiter.mu.Unlock()
+// parseRateLimit reads a "limit/window" value such as "10/1m". A limit of 0 disables the rate limit.
+func parseRateLimit(value string) (int, time.Duration, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected limit/window, got %q", value)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || limit < 0 {
+		return 0, 0, fmt.Errorf("invalid limit %q", parts[0])
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || window <= 0 {
+		return 0, 0, fmt.Errorf("invalid window %q", parts[1])
+	}
+	return limit, window, nil
+}
+
+// currentRule returns the limit of the route, reloading it from the config when it is stale
+func (rl *rateLimiter) currentRule(now time.Time) rateLimitRule {
+	if !rl.loadedAt.IsZero() && now.Sub(rl.loadedAt) < rateLimitRefresh {
+		return rl.rule
+	}
+
+	value := config.GetConfigValue(rl.configKey, rl.defaultLimit)
+	limit, window, err := parseRateLimit(value)
+	if err != nil {
+		log.Printf("Invalid %s config, using %s: %v", rl.configKey, rl.defaultLimit, err)
+		limit, window, _ = parseRateLimit(rl.defaultLimit)
+	}
+	algorithm := config.GetConfigValue("RATE_LIMIT_ALGORITHM", RateLimitSlidingWindow)
+	if algorithm != RateLimitTokenBucket {
+		algorithm = RateLimitSlidingWindow
+	}
+
+	rule := rateLimitRule{limit: limit, window: window, algorithm: algorithm}
+	if rule != rl.rule {
+		// Entries built with another rule would be inconsistent
+		rl.entries = make(map[string]*rateLimitEntry)
+	}
+	rl.rule = rule
+	rl.loadedAt = now
+	return rule
+}
 
-		attempts := joinTeamLimiter.attempts[key]
-		var validAttempts []time.Time
-		for _, attemptTime := range attempts {
-			if now.Sub(attemptTime) < joinTeamLimiter.window {
-				validAttempts = append(validAttempts, attemptTime)
+// key returns who the request counts for, prefixed by the route
+func (rl *rateLimiter) key(c *gin.Context) string {
+	prefix := c.FullPath() + ":"
+	if rl.scope != RateLimitPerIP {
+		if userI, ok := c.Get("user"); ok {
+			if user, ok := userI.(*models.User); ok {
+				if rl.scope == RateLimitPerTeam && user.TeamID != nil {
+					return prefix + "team:" + strconv.FormatUint(uint64(*user.TeamID), 10)
+				}
+				return prefix + "user:" + strconv.FormatUint(uint64(user.ID), 10)
 			}
 		}
+		if userID, ok := c.Get("user_id"); ok {
+			return prefix + "user:" + fmt.Sprint(userID)
+		}
+	}
+	// ClientIP only honours forwarded headers sent by the trusted proxies set on the router, so a client cannot
+	// pick a new address on each request
+	return prefix + "ip:" + c.ClientIP()
+}
 
-		if len(validAttempts) >= joinTeamLimiter.maxTries {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too_many_attempts"})
-			c.Abort()
+// allow records a request for the key, returning how long to wait when it is over the limit
+func (rl *rateLimiter) allow(key string, rule rateLimitRule, now time.Time) (bool, time.Duration) {
+	entry, ok := rl.entries[key]
+	if !ok {
+		entry = &rateLimitEntry{tokens: float64(rule.limit), last: now}
+		rl.entries[key] = entry
+	}
+
+	if rule.algorithm == RateLimitTokenBucket {
+		// Refill limit tokens per window, up to a full bucket
+		rate := float64(rule.limit) / rule.window.Seconds()
+		entry.tokens = math.Min(float64(rule.limit), entry.tokens+now.Sub(entry.last).Seconds()*rate)
+		entry.last = now
+		if entry.tokens < 1 {
+			return false, time.Duration((1 - entry.tokens) / rate * float64(time.Second))
+		}
+		entry.tokens--
+		return true, 0
+	}
+
+	// Sliding window: count the requests of the last window
+	valid := entry.requests[:0]
+	for _, at := range entry.requests {
+		if now.Sub(at) < rule.window {
+			valid = append(valid, at)
+		}
+	}
+	entry.requests = valid
+	entry.last = now
+	if len(valid) >= rule.limit {
+		return false, valid[0].Add(rule.window).Sub(now)
+	}
+	entry.requests = append(entry.requests, now)
+	return true, 0
+}
+
+// cleanup drops the keys idle for more than a window
+func (rl *rateLimiter) cleanup(rule rateLimitRule, now time.Time) {
+	if now.Sub(rl.lastCleanup) < rule.window {
+		return
+	}
+	for key, entry := range rl.entries {
+		if now.Sub(entry.last) >= rule.window {
+			delete(rl.entries, key)
+		}
+	}
+	rl.lastCleanup = now
+}
+
+// RateLimit limits the requests to a route. The limit is read from the configKey config
+// as "limit/window" (e.g. "10/1m", 0 disables it), defaultLimit is used when it is unset.
+// Blocked requests get a 429 with a Retry-After header.
+func RateLimit(configKey string, defaultLimit string, scope RateLimitScope) gin.HandlerFunc {
+	rl := &rateLimiter{
+		configKey:    configKey,
+		defaultLimit: defaultLimit,
+		scope:        scope,
+		entries:      make(map[string]*rateLimitEntry),
+	}
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		key := rl.key(c)
+
+		rl.mu.Lock()
+		rule := rl.currentRule(now)
+		if rule.limit == 0 {
+			rl.mu.Unlock()
+			c.Next()
 			return
 		}
+		rl.cleanup(rule, now)
+		allowed, retryAfter := rl.allow(key, rule, now)
+		rl.mu.Unlock()
 
-		validAttempts = append(validAttempts, now)
-		joinTeamLimiter.attempts[key] = validAttempts
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too_many_requests", "retryAfter": seconds})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
+
+// RateLimitJoinTeam limits the attempts of a user to join a team, against password guessing
+func RateLimitJoinTeam() gin.HandlerFunc {
+	return RateLimit("RATE_LIMIT_JOIN_TEAM", "5/1m", RateLimitPerUser)
+}
+
+// RateLimitSubmit limits the flag submissions of a team, against brute-forcing
+func RateLimitSubmit() gin.HandlerFunc {
+	return RateLimit("RATE_LIMIT_SUBMIT", "10/1m", RateLimitPerTeam)
+}
+
+// RateLimitMail limits the verification and password reset emails requested from an IP, against mail flooding
+func RateLimitMail() gin.HandlerFunc {
+	return RateLimit("RATE_LIMIT_MAIL", "3/10m", RateLimitPerIP)
+}
+
+// RateLimitTwoFactor limits the codes a user can try, against guessing TOTP and recovery codes
+func RateLimitTwoFactor() gin.HandlerFunc {
+	return RateLimit("RATE_LIMIT_2FA", "5/1m", RateLimitPerUser)
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// API token scopes, from the most to the least restricted
+const (
+	APITokenScopeRead   = "read"   // GET requests only
+	APITokenScopeSubmit = "submit" // Read access and flag submissions
+	APITokenScopeAdmin  = "admin"  // Everything the owner's role allows, admins only
+)
+
+// APIToken is a personal token authenticating scripts and bots as its owner. Only a hash of the token is stored.
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"userId"`
+	User       *User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Name       string     `gorm:"not null;size:64" json:"name"`
+	TokenHash  string     `gorm:"not null;uniqueIndex;size:64" json:"-"`
+	Prefix     string     `gorm:"size:16" json:"prefix"` // First characters of the token, to recognize it in the list
+	Scope      string     `gorm:"not null;size:16" json:"scope"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
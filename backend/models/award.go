@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Award is a manual bonus or penalty (negative value) on a team score
+type Award struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TeamID    uint      `gorm:"not null;index" json:"teamId"`
+	Team      *Team     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"team,omitempty"`
+	UserID    *uint     `gorm:"index" json:"userId,omitempty"` // Member credited in the individual leaderboard
+	User      *User     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"user,omitempty"`
+	Value     int       `gorm:"not null" json:"value"`
+	Reason    string    `gorm:"not null;type:text" json:"reason"`
+	Category  string    `gorm:"size:50" json:"category"`
+	AwardedAt time.Time `gorm:"not null;index" json:"awardedAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
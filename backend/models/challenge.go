@@ -37,9 +37,15 @@ type Challenge struct {
 	FirstBloodBadges      pq.StringArray       `gorm:"type:text[]" json:"firstBloodBadges"`
 	MaxAttempts           int                  `gorm:"default:0" json:"maxAttempts"` // 0 = unlimited attempts
 	DependsOn             string               `json:"dependsOn,omitempty"`          // Name of the challenge that must be solved first
+	Prerequisites         []Prerequisite       `gorm:"type:jsonb;serializer:json" json:"prerequisites,omitempty"` // Conditions to unlock the challenge, all must be met
 	CoverImg              string               `json:"coverImg,omitempty"`            // Cover image filename (e.g., "cover_resized.webp")
 	Emoji                 string               `json:"emoji,omitempty"`               // Emoji to display when no cover image
 	CoverPositionX        float64              `gorm:"default:50" json:"coverPositionX"` // X position for cover image (0-100, default 50 = center)
 	CoverPositionY        float64              `gorm:"default:50" json:"coverPositionY"` // Y position for cover image (0-100, default 50 = center)
 	CoverZoom             float64              `gorm:"default:100" json:"coverZoom"`     // Zoom level for cover image (100-200, default 100 = no zoom)
+	DynamicFlag           bool                 `gorm:"default:false" json:"dynamicFlag"` // Unique flag per team injected into docker/compose instances
+	ReleaseAt             *time.Time           `gorm:"index" json:"releaseAt,omitempty"` // Challenge stays hidden until then, time decay starts from it
+	HideAt                *time.Time           `gorm:"index" json:"hideAt,omitempty"`    // Challenge is hidden again from then
+	ArchivedAt            *time.Time           `gorm:"index" json:"archivedAt,omitempty"` // Set when chall.yml disappeared from MinIO, the challenge awaits review
+	WorkerLabels          pq.StringArray       `gorm:"type:text[]" json:"workerLabels,omitempty"` // Labels a worker needs to run the instances
 }
@@ -3,7 +3,8 @@ package models
 type DecayFormula struct {
 	ID        uint   `gorm:"primaryKey" json:"id"`
 	Name      string `gorm:"unique;not null" json:"name"`
-	Type      string `gorm:"default:'logarithmic'" json:"type"` // Type: fixed, logarithmic, dynamic
-	Step      int    `gorm:"default:10" json:"step"`           // For logarithmic: multiplier; For dynamic: solves to reach minimum
+	Type      string `gorm:"default:'logarithmic'" json:"type"` // Type: fixed, logarithmic, dynamic, time
+	Step      int    `gorm:"default:10" json:"step"`           // For logarithmic: multiplier; For dynamic: solves to reach minimum; For time: points lost per interval
 	MinPoints int    `gorm:"default:10" json:"minPoints"`       // Minimum points floor
+	Interval  int    `gorm:"default:0" json:"interval"`         // For time: minutes between two point drops
 }
@@ -6,8 +6,8 @@ type DynamicFlag struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	Value       string    `json:"value"`
 	Team        Team      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"team"`
-	TeamID      uint      `json:"teamId"`
+	TeamID      uint      `gorm:"uniqueIndex:idx_dynamic_flag_team_challenge" json:"teamId"`
 	Challenge   Challenge `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"challenge"`
-	ChallengeID uint      `json:"challengeId"`
+	ChallengeID uint      `gorm:"uniqueIndex:idx_dynamic_flag_team_challenge" json:"challengeId"`
 	CreatedAt   time.Time `json:"createdAt"`
 }
@@ -2,9 +2,16 @@ package models
 
 import "time"
 
+const (
+	FlagTypeStatic   = "static"
+	FlagTypeStaticCI = "static_ci"
+	FlagTypeRegex    = "regex"
+)
+
 type Flag struct {
 	ID          uint       `gorm:"primaryKey" json:"id"`
-	Value       string     `json:"value"`
+	Value       string     `json:"value"`                                // SHA-256 for static types, raw pattern for regex
+	Type        string     `gorm:"size:20;default:'static'" json:"type"` // static, static_ci, regex
 	ChallengeID uint       `json:"challengeId"`
 	Challenge   *Challenge `gorm:"constraint:OnDelete:CASCADE;" json:"challenge,omitempty"`
 	CreatedAt   time.Time  `json:"createdAt"`
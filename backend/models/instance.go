@@ -19,4 +19,7 @@ type Instance struct {
 	Ports       pq.Int64Array `gorm:"type:integer[]" json:"ports"`
 	ExpiresAt   time.Time     `json:"expiresAt"`
 	Status      string        `json:"status" gorm:"default:'running'"` // running, stopped, expired
+	Extensions  int           `gorm:"default:0" json:"extensions"`     // Number of lifetime extensions used
+	WorkerID    *uint         `gorm:"index" json:"workerId,omitempty"` // nil = default docker host from DockerConfig
+	Worker      *Worker       `gorm:"foreignKey:WorkerID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"worker,omitempty"`
 }
@@ -0,0 +1,13 @@
+package models
+
+// Prerequisite is one condition to unlock a challenge. Exactly one kind is set:
+// a challenge slug to solve, an All/Any group of nested conditions, a number of
+// solves in a category or a minimum team score.
+type Prerequisite struct {
+	Challenge string         `json:"challenge,omitempty"`
+	All       []Prerequisite `json:"all,omitempty"`
+	Any       []Prerequisite `json:"any,omitempty"`
+	Category  string         `json:"category,omitempty"`
+	Count     int            `json:"count,omitempty"` // Conditions to meet in Any (default 1), or solves needed in Category
+	MinScore  int            `json:"minScore,omitempty"`
+}
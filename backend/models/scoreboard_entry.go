@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ScoreboardEntry is the materialized score of a team, refreshed on every event changing it
+type ScoreboardEntry struct {
+	TeamID      uint       `gorm:"primaryKey" json:"teamId"`
+	Team        *Team      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"team,omitempty"`
+	SolvePoints int        `gorm:"not null;default:0" json:"solvePoints"` // Decayed solve points with first blood bonuses
+	AwardPoints int        `gorm:"not null;default:0" json:"awardPoints"` // Sum of manual awards and penalties
+	HintsCost   int        `gorm:"not null;default:0" json:"hintsCost"`
+	TotalScore  int        `gorm:"not null;default:0;index" json:"totalScore"`
+	SolveCount  int        `gorm:"not null;default:0" json:"solveCount"`
+	LastSolveAt *time.Time `json:"lastSolveAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Session is a login of a user, which lives as long as its refresh token. Each refresh rotates the token and
+// increments the generation, so the tokens of a session form a family where only the latest one is valid.
+type Session struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"userId"`
+	User       *User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	TokenHash  string     `gorm:"size:64" json:"-"` // Hash of the current refresh token
+	Generation int        `gorm:"not null;default:0" json:"-"`
+	RotatedAt  *time.Time `json:"-"`
+	UserAgent  string     `gorm:"size:255" json:"userAgent"`
+	IPAddress  string     `gorm:"size:64" json:"ipAddress"`
+	LastUsedAt time.Time  `json:"lastUsedAt"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SubnetLease is the challenge network subnet allocated to a team from PTA_DOCKER_CHALL_BASE_CIDR
+type SubnetLease struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TeamID    uint      `gorm:"uniqueIndex;not null" json:"teamId"`
+	Subnet    string    `gorm:"uniqueIndex;not null" json:"subnet"`
+	Gateway   string    `gorm:"not null" json:"gateway"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type SuspiciousActivity struct {
+	ID           uint        `gorm:"primaryKey" json:"id"`
+	Type         string      `gorm:"not null;size:50;default:'flag_sharing'" json:"type"`
+	Status       string      `gorm:"not null;size:20;default:'pending';index" json:"status"` // pending, dismissed
+	SubmissionID *uint       `json:"submissionId,omitempty"`
+	Submission   *Submission `gorm:"foreignKey:SubmissionID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	UserID       uint        `json:"userId"`
+	User         *User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
+	TeamID       uint        `json:"teamId"` // Team that submitted the flag
+	Team         *Team       `gorm:"foreignKey:TeamID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"team,omitempty"`
+	SourceTeamID uint        `json:"sourceTeamId"` // Team the flag was generated for
+	SourceTeam   *Team       `gorm:"foreignKey:SourceTeamID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"sourceTeam,omitempty"`
+	ChallengeID  uint        `json:"challengeId"`
+	Challenge    *Challenge  `gorm:"foreignKey:ChallengeID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"challenge,omitempty"`
+	AutoBanned   bool        `gorm:"default:false" json:"autoBanned"`
+	ReviewedByID *uint       `json:"reviewedById,omitempty"`
+	ReviewedAt   *time.Time  `json:"reviewedAt,omitempty"`
+	CreatedAt    time.Time   `json:"createdAt"`
+}
@@ -41,7 +41,12 @@ type User struct {
 	ID            uint           `gorm:"primaryKey" json:"id"`
 	Username      string         `gorm:"unique;not null;size:32" json:"username"`
 	Email         string         `gorm:"unique;not null;size:254" json:"email"`
+	EmailVerified bool           `gorm:"not null;default:true" json:"emailVerified"` // Only false for registrations waiting for their verification email
 	Password      string         `json:"-"`
+	OIDCSubject   *string        `gorm:"uniqueIndex;size:255" json:"-"` // Subject at the identity provider for single sign-on users
+	TOTPSecret    string         `gorm:"size:64" json:"-"`              // Set at enrolment, in use once TOTPEnabled
+	TOTPEnabled   bool           `gorm:"not null;default:false" json:"totpEnabled"`
+	TOTPCounter   int64          `json:"-"` // Period of the last accepted code, so a code cannot be replayed
 	Role          string         `gorm:"not null;default:'member'" json:"role"`
 	CreatedAt     time.Time      `json:"createdAt"`
 	UpdatedAt     time.Time      `json:"updatedAt"`
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// VMSpec stores the virtual machine settings of a vm challenge
+type VMSpec struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ChallengeID uint      `gorm:"uniqueIndex" json:"challengeId"`
+	DiskImage   string    `json:"diskImage"`
+	Memory      int       `json:"memory"` // MiB
+	VCPUs       int       `json:"vcpus"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
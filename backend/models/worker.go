@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Worker struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Name          string         `gorm:"uniqueIndex;not null" json:"name"`
+	Host          string         `gorm:"not null" json:"host"` // unix socket, ssh:// or tcp:// docker host
+	PublicIP      string         `json:"publicIp"`             // Replaces $ip in connection_info (defaults to PTA_DOCKER_WORKER_IP)
+	TLSCACert     string         `gorm:"type:text" json:"-"`
+	TLSCert       string         `gorm:"type:text" json:"-"`
+	TLSKey        string         `gorm:"type:text" json:"-"`
+	Capacity      int            `gorm:"default:0" json:"capacity"` // Max concurrent instances (0 = unlimited)
+	Labels        pq.StringArray `gorm:"type:text[]" json:"labels"`
+	Enabled       bool           `json:"enabled"` // Set to true on creation unless the request disables it
+	Healthy       bool           `gorm:"default:false" json:"healthy"`
+	LastError     string         `json:"lastError,omitempty"`
+	LastCheckedAt *time.Time     `json:"lastCheckedAt,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+}
+
+// HasTLS tells if TLS material is configured for this worker
+func (w *Worker) HasTLS() bool {
+	return w.TLSCACert != "" || w.TLSCert != ""
+}
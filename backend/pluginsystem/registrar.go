@@ -69,7 +69,7 @@ func (g *GinRouteRegistrar) RegisterRouteWithMiddlewares(method, path, handlerNa
 
 func (g *GinRouteRegistrar) createAuthMiddleware(path, method, requireRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, errMsg := utils.GetClaimsFromCookie(c)
+		claims, errMsg := utils.GetRequestClaims(c)
 		if claims == nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errMsg})
 			return
@@ -99,6 +99,11 @@ func (g *GinRouteRegistrar) createAuthMiddleware(path, method, requireRole strin
 			}
 		}
 
+		if !utils.APITokenScopeAllows(claims.Scope, method, path) {
+			c.AbortWithStatusJSON(403, gin.H{"error": "insufficient_scope"})
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("user_role", claims.Role)
 
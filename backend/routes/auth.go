@@ -10,13 +10,34 @@ func RegisterAuthRoutes(router *gin.Engine) {
 	auth := router.Group("/")
 	{
 		auth.POST("login", controllers.Login)
+		auth.GET("login/2fa", middleware.TwoFactorPending(), controllers.GetPendingTwoFactorLogin)
+		auth.POST("login/2fa", middleware.TwoFactorPending(), middleware.RateLimitTwoFactor(), controllers.LoginTwoFactor)
+		auth.POST("login/2fa/setup", middleware.TwoFactorPending(), controllers.LoginTwoFactorSetup)
+		auth.POST("login/2fa/enable", middleware.TwoFactorPending(), middleware.RateLimitTwoFactor(), controllers.LoginTwoFactorEnable)
 		auth.POST("refresh", controllers.Refresh)
 		auth.POST("register", controllers.Register)
-		auth.POST("logout", middleware.AuthRequired(false), controllers.Logout)
+		auth.POST("verify-email", controllers.VerifyEmail)
+		auth.POST("verify-email/resend", middleware.RateLimitMail(), controllers.ResendVerificationEmail)
+		auth.POST("password/forgot", middleware.RateLimitMail(), controllers.ForgotPassword)
+		auth.POST("password/reset", controllers.ResetPassword)
+		auth.GET("oidc/login", controllers.OIDCLogin)
+		auth.GET("oidc/callback", controllers.OIDCCallback)
+		auth.POST("logout", controllers.Logout)
+		auth.POST("logout/all", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.LogoutEverywhere)
 		auth.GET("me", middleware.AuthRequired(false), controllers.GetCurrentUser)
-		auth.PATCH("me", middleware.AuthRequired(false), controllers.UpdateCurrentUser)
-		auth.PUT("me/password", middleware.AuthRequired(false), controllers.UpdateCurrentUserPassword)
-		auth.DELETE("me", middleware.AuthRequired(false), controllers.DeleteCurrentUser)
+		auth.PATCH("me", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.UpdateCurrentUser)
+		auth.PUT("me/password", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.UpdateCurrentUserPassword)
+		auth.DELETE("me", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.DeleteCurrentUser)
+		auth.GET("me/tokens", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.GetAPITokens)
+		auth.POST("me/tokens", middleware.AuthRequired(false), middleware.SessionOnly(), middleware.DemoRestriction, controllers.CreateAPIToken)
+		auth.DELETE("me/tokens/:id", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.DeleteAPIToken)
+		auth.GET("me/sessions", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.GetMySessions)
+		auth.DELETE("me/sessions/:id", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.DeleteMySession)
+		auth.GET("me/2fa", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.GetTwoFactorStatus)
+		auth.POST("me/2fa/setup", middleware.AuthRequired(false), middleware.SessionOnly(), controllers.SetupTwoFactor)
+		auth.POST("me/2fa/enable", middleware.AuthRequired(false), middleware.SessionOnly(), middleware.RateLimitTwoFactor(), controllers.EnableTwoFactor)
+		auth.POST("me/2fa/disable", middleware.AuthRequired(false), middleware.SessionOnly(), middleware.RateLimitTwoFactor(), controllers.DisableTwoFactor)
+		auth.POST("me/2fa/recovery-codes", middleware.AuthRequired(false), middleware.SessionOnly(), middleware.RateLimitTwoFactor(), controllers.RegenerateRecoveryCodes)
 		auth.GET("pwn", middleware.AuthRequired(false), func(c *gin.Context) {
 			c.JSON(200, gin.H{"success": "true"})
 		})
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterAwardRoutes(router *gin.Engine) {
+	adminAwards := router.Group("/admin/awards", middleware.AuthRequired(false))
+	{
+		adminAwards.GET("", middleware.CheckPolicy("/admin/awards", "read"), controllers.GetAwards)
+		adminAwards.GET("/:id", middleware.CheckPolicy("/admin/awards", "read"), controllers.GetAward)
+		adminAwards.POST("", middleware.DemoRestriction, middleware.CheckPolicy("/admin/awards", "write"), controllers.CreateAward)
+		adminAwards.PUT("/:id", middleware.DemoRestriction, middleware.CheckPolicy("/admin/awards", "write"), controllers.UpdateAward)
+		adminAwards.DELETE("/:id", middleware.DemoRestriction, middleware.CheckPolicy("/admin/awards", "write"), controllers.DeleteAward)
+	}
+}
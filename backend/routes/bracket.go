@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterBracketRoutes(router *gin.Engine) {
+	brackets := router.Group("/brackets", middleware.AuthRequired(false))
+	{
+		brackets.GET("", middleware.CheckPolicy("/brackets", "read"), controllers.GetBrackets)
+		brackets.POST("", middleware.CheckPolicy("/brackets", "write"), controllers.CreateBracket)
+		brackets.PUT("/:id", middleware.CheckPolicy("/brackets/:id", "write"), controllers.UpdateBracket)
+		brackets.DELETE("/:id", middleware.CheckPolicy("/brackets/:id", "write"), controllers.DeleteBracket)
+	}
+}
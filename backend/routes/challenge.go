@@ -10,25 +10,26 @@ func RegisterChallengeRoutes(router *gin.Engine) {
 	challenges := router.Group("/challenges")
 	{
 		challenges.GET("", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges", "read"), controllers.GetChallenges)
-		challenges.GET("/:id", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id", "read"), controllers.GetChallenge)
-		challenges.GET("/:id/solves", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/solves", "read"), controllers.GetChallengeSolves)
-		challenges.GET("/:id/firstbloods", middleware.AuthRequired(false), middleware.CheckPolicy("/challenges/:id/firstbloods", "read"), controllers.GetChallengeFirstBloods)
+		challenges.GET("/:id", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id", "read"), middleware.ChallengeUnlocked(), controllers.GetChallenge)
+		challenges.GET("/:id/solves", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/solves", "read"), middleware.ChallengeVisible(), controllers.GetChallengeSolves)
+		challenges.GET("/:id/firstbloods", middleware.AuthRequired(false), middleware.CheckPolicy("/challenges/:id/firstbloods", "read"), middleware.ChallengeVisible(), controllers.GetChallengeFirstBloods)
 		challenges.GET("/category/:category", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/category/:category", "read"), controllers.GetChallengesByCategoryName)
 
-		challenges.GET("/:id/files", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/files", "read"), controllers.GetChallengeFiles)
-		challenges.GET("/:id/files/:filename", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/files/:filename", "read"), controllers.DownloadChallengeFile)
+		challenges.GET("/:id/files", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/files", "read"), middleware.ChallengeUnlocked(), controllers.GetChallengeFiles)
+		challenges.GET("/:id/files/:filename", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/files/:filename", "read"), middleware.ChallengeUnlocked(), controllers.DownloadChallengeFile)
 
 		challenges.POST("", middleware.CheckPolicy("/challenges", "write"), controllers.CreateChallenge)
-		challenges.POST("/:id/submit", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/submit", "write"), controllers.SubmitChallenge)
-		challenges.POST("/:id/build", middleware.DemoRestriction, middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/build", "write"), controllers.BuildChallengeImage)
-		challenges.GET("/:id/instance-status", middleware.DemoRestriction, middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/instance-status", "read"), controllers.GetInstanceStatus)
-		challenges.POST("/:id/start", middleware.DemoRestriction, middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/start", "write"), controllers.StartChallengeInstance)
+		challenges.POST("/:id/submit", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/submit", "write"), middleware.ChallengeUnlocked(), middleware.RateLimitSubmit(), controllers.SubmitChallenge)
+		challenges.POST("/:id/build", middleware.DemoRestriction, middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/build", "write"), middleware.ChallengeUnlocked(), controllers.BuildChallengeImage)
+		challenges.GET("/:id/instance-status", middleware.DemoRestriction, middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/instance-status", "read"), middleware.ChallengeUnlocked(), controllers.GetInstanceStatus)
+		challenges.POST("/:id/start", middleware.DemoRestriction, middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/start", "write"), middleware.ChallengeUnlocked(), controllers.StartChallengeInstance)
+		challenges.POST("/:id/extend", middleware.DemoRestriction, middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/extend", "write"), middleware.ChallengeUnlocked(), controllers.ExtendChallengeInstance)
 		challenges.POST("/:id/stop", middleware.DemoRestriction, middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/stop", "write"), controllers.StopChallengeInstance)
 
-		challenges.GET("/:id/cover", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/cover", "read"), controllers.GetChallengeCover)
+		challenges.GET("/:id/cover", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/:id/cover", "read"), middleware.ChallengeVisible(), controllers.GetChallengeCover)
 
 		// Hint routes
-		challenges.POST("/hints/:id/purchase", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/hints/:id/purchase", "write"), controllers.PurchaseHint)
+		challenges.POST("/hints/:id/purchase", middleware.AuthRequiredTeamOrAdmin(), middleware.CheckPolicy("/challenges/hints/:id/purchase", "write"), middleware.HintChallengeUnlocked(), controllers.PurchaseHint)
 		// challenges.PUT("/:id", middleware.CheckPolicy("/challenges/:id", "write"), controllers.UpdateUser)
 		// challenges.DELETE("/:id", middleware.CheckPolicy("/challenges/:id", "write"), controllers.DeleteUser)
 
@@ -38,7 +39,11 @@ func RegisterChallengeRoutes(router *gin.Engine) {
 	{
 		// Allow admins access without requiring a team; policy check restricts to admin role
 		adminChallenges.GET("", middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges", "read"), controllers.GetAllChallengesAdmin)
+		adminChallenges.GET("/export", middleware.DemoRestriction, middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges/export", "read"), controllers.ExportChallengesArchive)
+		adminChallenges.POST("/import", middleware.DemoRestriction, middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges/import", "write"), controllers.ImportChallengesArchive)
+		adminChallenges.GET("/archived", middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges/archived", "read"), controllers.GetArchivedChallengesAdmin)
 		adminChallenges.GET("/:id", middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges/:id", "read"), controllers.GetChallengeAdmin)
+		adminChallenges.DELETE("/:id", middleware.DemoRestriction, middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges/:id", "write"), controllers.PurgeChallengeAdmin)
 		adminChallenges.PUT("/:id", middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges/:id", "write"), controllers.UpdateChallengeAdmin)
 		adminChallenges.PUT("/:id/general", middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges/:id", "write"), controllers.UpdateChallengeGeneralAdmin)
 		adminChallenges.DELETE("/hints/:hintId", middleware.AuthRequired(false), middleware.CheckPolicy("/admin/challenges/hints/:hintId", "write"), controllers.DeleteHint)
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterScoreboardRoutes(router *gin.Engine) {
+	// Public CTFtime feed, frozen like the leaderboard
+	router.GET("/scoreboard/ctftime.json", controllers.GetCTFtimeScoreboard)
+
+	adminScoreboard := router.Group("/admin/scoreboard", middleware.AuthRequired(false))
+	{
+		adminScoreboard.GET("/export/standings", middleware.CheckPolicy("/admin/scoreboard", "read"), controllers.ExportStandings)
+		adminScoreboard.GET("/export/solves", middleware.CheckPolicy("/admin/scoreboard", "read"), controllers.ExportSolves)
+		adminScoreboard.GET("/export/first-bloods", middleware.CheckPolicy("/admin/scoreboard", "read"), controllers.ExportFirstBloods)
+		adminScoreboard.POST("/reveal", middleware.DemoRestriction, middleware.CheckPolicy("/admin/scoreboard", "write"), controllers.RevealScoreboard)
+		adminScoreboard.POST("/freeze", middleware.DemoRestriction, middleware.CheckPolicy("/admin/scoreboard", "write"), controllers.FreezeScoreboard)
+	}
+}
@@ -10,5 +10,7 @@ func RegisterSubmissionRoutes(router *gin.Engine) {
 	adminSubmissions := router.Group("/admin/submissions", middleware.AuthRequired(false))
 	{
 		adminSubmissions.GET("", middleware.CheckPolicy("/admin/submissions", "read"), controllers.GetAllSubmissions)
+		adminSubmissions.GET("/suspicious", middleware.CheckPolicy("/admin/submissions", "read"), controllers.GetSuspiciousActivities)
+		adminSubmissions.PUT("/suspicious/:id/dismiss", middleware.CheckPolicy("/admin/submissions", "write"), controllers.DismissSuspiciousActivity)
 	}
 }
@@ -30,6 +30,7 @@ func RegisterTeamRoutes(router *gin.Engine) {
 		teams.POST("/disband", middleware.CheckPolicy("/teams/disband", actionWrite), controllers.DisbandTeam)
 		teams.POST("/kick", middleware.CheckPolicy("/teams/kick", actionWrite), controllers.KickTeamMember)
 		teams.POST("/recalculate-points", middleware.CheckPolicy(pathTeamsRecalculate, actionWrite), controllers.RecalculateTeamPoints)
+		teams.PUT("/:id/bracket", middleware.CheckPolicy("/teams/:id/bracket", actionWrite), controllers.SetTeamBracket)
 		teams.PUT("/:id", middleware.CheckPolicy(pathTeamsID, actionWrite), controllers.UpdateTeam)
 		teams.DELETE("/:id", middleware.CheckPolicy(pathTeamsID, actionWrite), controllers.DeleteTeam)
 	}
@@ -18,5 +18,9 @@ func RegisterUserRoutes(router *gin.Engine) {
 		users.PUT("/:id", middleware.CheckPolicy("/users/:id", "write"), controllers.UpdateUser)
 		users.DELETE("/:id", middleware.CheckPolicy("/users/:id", "write"), controllers.DeleteUser)
 		users.POST("/:id/ban", middleware.CheckPolicy("/users/:id/ban", "write"), controllers.BanOrUnbanUser)
+		users.DELETE("/:id/2fa", middleware.CheckPolicy("/users/:id/2fa", "write"), controllers.ResetUserTwoFactor)
+		users.GET("/:id/sessions", middleware.CheckPolicy("/users/:id/sessions", "read"), controllers.GetUserSessions)
+		users.DELETE("/:id/sessions", middleware.CheckPolicy("/users/:id/sessions", "write"), controllers.DeleteUserSessions)
+		users.DELETE("/:id/sessions/:sessionId", middleware.CheckPolicy("/users/:id/sessions", "write"), controllers.DeleteUserSession)
 	}
 }
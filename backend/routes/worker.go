@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/controllers"
+	"github.com/pwnthemall/pwnthemall/backend/middleware"
+)
+
+func RegisterWorkerRoutes(router *gin.Engine) {
+
+	// Admin-only endpoints
+	workers := router.Group("/admin/workers", middleware.DemoRestriction, middleware.AuthRequired(false))
+	{
+		workers.GET("", middleware.CheckPolicy("/admin/workers", "read"), controllers.GetWorkers)
+		workers.POST("", middleware.CheckPolicy("/admin/workers", "write"), controllers.CreateWorker)
+		workers.PUT("/:id", middleware.CheckPolicy("/admin/workers/:id", "write"), controllers.UpdateWorker)
+		workers.DELETE("/:id", middleware.CheckPolicy("/admin/workers/:id", "write"), controllers.DeleteWorker)
+		workers.POST("/:id/check", middleware.CheckPolicy("/admin/workers/:id/check", "write"), controllers.CheckWorker)
+	}
+}
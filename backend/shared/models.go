@@ -5,3 +5,10 @@ type FirewallRequest struct {
 	Ports      []int    `json:"ports"`
 	AllowedIPs []string `json:"allowed_ips"`
 }
+
+// FirewallRule is a rule of a team chain managed by the firewall agent
+type FirewallRule struct {
+	Source string `json:"source,omitempty"`
+	Port   int    `json:"port"`
+	Action string `json:"action"`
+}
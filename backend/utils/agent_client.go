@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/shared"
+)
+
+const (
+	agentAPIVersion  = "v1"
+	agentDefaultPort = 8383
+)
+
+// AgentClient talks to the firewall agent API
+type AgentClient struct {
+	baseURL string
+	secret  string
+	http    *http.Client
+}
+
+// NewAgentClient builds a client from PTA_AGENT_URL (default: the host gateway), PTA_AGENT_SECRET
+// and the optional PTA_AGENT_TLS_CA, PTA_AGENT_TLS_CERT and PTA_AGENT_TLS_KEY files for mTLS
+func NewAgentClient() (*AgentClient, error) {
+	baseURL := strings.TrimRight(os.Getenv("PTA_AGENT_URL"), "/")
+	if baseURL == "" {
+		gateway, err := getDefaultGateway()
+		if err != nil {
+			return nil, fmt.Errorf("agent url not configured and no default gateway: %w", err)
+		}
+		baseURL = fmt.Sprintf("http://%s:%d", gateway, agentDefaultPort)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig, err := agentClientTLS()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &AgentClient{
+		baseURL: baseURL,
+		secret:  os.Getenv("PTA_AGENT_SECRET"),
+		http:    &http.Client{Timeout: 10 * time.Second, Transport: transport},
+	}, nil
+}
+
+// agentClientTLS loads the CA and client certificate used to reach the agent over mTLS
+func agentClientTLS() (*tls.Config, error) {
+	caFile := os.Getenv("PTA_AGENT_TLS_CA")
+	certFile := os.Getenv("PTA_AGENT_TLS_CERT")
+	if caFile == "" && certFile == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read agent CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("invalid agent CA %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, os.Getenv("PTA_AGENT_TLS_KEY"))
+		if err != nil {
+			return nil, fmt.Errorf("load agent client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// do sends a request to the agent and decodes the JSON response into out when not nil
+func (a *AgentClient) do(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, a.baseURL+"/"+agentAPIVersion+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.secret != "" {
+		req.Header.Set("Authorization", "Bearer "+a.secret)
+	}
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("firewall agent unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("firewall agent returned %d: %s", resp.StatusCode, apiErr.Error)
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// ApplyTeamFirewall replaces the rules of a team, only allowing its members on its instance ports
+func (a *AgentClient) ApplyTeamFirewall(req shared.FirewallRequest) ([]shared.FirewallRule, error) {
+	var resp struct {
+		Rules []shared.FirewallRule `json:"rules"`
+	}
+	err := a.do(http.MethodPut, fmt.Sprintf("/teams/%d/firewall", req.TeamID), req, &resp)
+	return resp.Rules, err
+}
+
+// RemoveTeamFirewall deletes every rule of a team
+func (a *AgentClient) RemoveTeamFirewall(teamID uint) error {
+	return a.do(http.MethodDelete, fmt.Sprintf("/teams/%d/firewall", teamID), nil, nil)
+}
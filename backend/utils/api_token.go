@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// APITokenPrefix starts every personal API token, so leaked tokens are easy to spot
+const APITokenPrefix = "pta_"
+
+// requestClaimsKey caches the claims of a request in its context
+const requestClaimsKey = "request_claims"
+
+// apiTokenUsageInterval limits how often the last use of a token is written
+const apiTokenUsageInterval = time.Minute
+
+// GenerateAPIToken returns a new random token with its hash and display prefix
+func GenerateAPIToken() (token string, hash string, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashAPIToken(token), token[:len(APITokenPrefix)+6], nil
+}
+
+// HashAPIToken returns the SHA-256 of a token. Tokens are random, so a fast hash is enough and allows lookups.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetRequestClaims returns the claims of the request, from an API token in the Authorization
+// header or else from the access_token cookie
+func GetRequestClaims(c *gin.Context) (*TokenClaims, error) {
+	// Several middlewares of a route ask for the claims, which are only looked up once
+	if claims, ok := c.Get(requestClaimsKey); ok {
+		return claims.(*TokenClaims), nil
+	}
+
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		claims, err := GetClaimsFromCookie(c)
+		if claims != nil {
+			c.Set(requestClaimsKey, claims)
+		}
+		return claims, err
+	}
+
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, errors.New("invalid authorization header")
+	}
+
+	var apiToken models.APIToken
+	if err := config.DB.Preload("User").Where("token_hash = ?", HashAPIToken(token)).First(&apiToken).Error; err != nil || apiToken.User == nil {
+		return nil, errors.New("invalid api token")
+	}
+	if apiToken.User.Banned {
+		return nil, errors.New("banned")
+	}
+	if TwoFactorRequired(apiToken.User) && !apiToken.User.TOTPEnabled {
+		return nil, errors.New("2fa_setup_required")
+	}
+	now := time.Now()
+	if apiToken.ExpiresAt != nil && now.After(*apiToken.ExpiresAt) {
+		return nil, errors.New("api token expired")
+	}
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) > apiTokenUsageInterval {
+		go config.DB.Model(&models.APIToken{}).Where("id = ?", apiToken.ID).Update("last_used_at", now)
+	}
+
+	claims := &TokenClaims{
+		UserID:     apiToken.UserID,
+		Role:       apiToken.User.Role,
+		Scope:      apiToken.Scope,
+		APITokenID: apiToken.ID,
+	}
+	c.Set("api_token_id", apiToken.ID)
+	c.Set(requestClaimsKey, claims)
+	return claims, nil
+}
+
+// APITokenScopeAllows tells if a token scope grants a request. Claims from a cookie have no scope and are
+// only limited by their role.
+func APITokenScopeAllows(scope string, method string, fullPath string) bool {
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return true
+	}
+	switch scope {
+	case "", models.APITokenScopeAdmin:
+		return true
+	case models.APITokenScopeSubmit:
+		return method == http.MethodPost && fullPath == "/challenges/:id/submit"
+	default:
+		return false
+	}
+}
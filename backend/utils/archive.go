@@ -0,0 +1,377 @@
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/meta"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	archiveManifestName     = "manifest.json"
+	archiveChallengesPrefix = "challenges/"
+)
+
+// ArchiveImportResult summarizes what an archive import changed
+type ArchiveImportResult struct {
+	Files      int      `json:"files"`
+	Challenges int      `json:"challenges"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// ExportChallengesArchive writes every challenge folder of the bucket and the event-wide data as a tar.gz archive.
+// Each chall.yml is updated with the changes made from the admin panel (hints, decay, points, ...).
+func ExportChallengesArchive(ctx context.Context, w io.Writer) error {
+	var challenges []models.Challenge
+	if err := config.DB.Preload("ChallengeCategory").Preload("ChallengeDifficulty").Preload("DecayFormula").Preload("Hints").
+		Order("id").Find(&challenges).Error; err != nil {
+		return fmt.Errorf("failed to load challenges: %w", err)
+	}
+	bySlug := make(map[string]models.Challenge, len(challenges))
+	for _, challenge := range challenges {
+		bySlug[challenge.Slug] = challenge
+	}
+
+	manifest, err := buildArchiveManifest(challenges)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	// The manifest goes first so imports can create references before syncing challenges
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeArchiveEntry(tw, archiveManifestName, manifestJSON); err != nil {
+		return err
+	}
+
+	for object := range config.FS.ListObjects(ctx, bucketNameChallenges, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			return fmt.Errorf("failed to list challenges: %w", object.Err)
+		}
+		if err := exportArchiveObject(ctx, tw, object, bySlug); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// buildArchiveManifest collects categories, difficulties, decay formulas, badges and challenge layout
+func buildArchiveManifest(challenges []models.Challenge) (*meta.ArchiveManifest, error) {
+	manifest := &meta.ArchiveManifest{Version: meta.ArchiveVersion, ExportedAt: time.Now().UTC()}
+
+	if err := config.DB.Model(&models.ChallengeCategory{}).Order("id").Pluck("name", &manifest.Categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+	if err := config.DB.Model(&models.ChallengeDifficulty{}).Order("id").Pluck("name", &manifest.Difficulties).Error; err != nil {
+		return nil, fmt.Errorf("failed to load difficulties: %w", err)
+	}
+
+	var formulas []models.DecayFormula
+	if err := config.DB.Order("id").Find(&formulas).Error; err != nil {
+		return nil, fmt.Errorf("failed to load decay formulas: %w", err)
+	}
+	for _, formula := range formulas {
+		manifest.DecayFormulas = append(manifest.DecayFormulas, meta.ArchiveDecayFormula{
+			Name:      formula.Name,
+			Type:      formula.Type,
+			Step:      formula.Step,
+			MinPoints: formula.MinPoints,
+			Interval:  formula.Interval,
+		})
+	}
+
+	var badges []models.Badge
+	if err := config.DB.Order("id").Find(&badges).Error; err != nil {
+		return nil, fmt.Errorf("failed to load badges: %w", err)
+	}
+	for _, badge := range badges {
+		manifest.Badges = append(manifest.Badges, meta.ArchiveBadge{
+			Name:        badge.Name,
+			Description: badge.Description,
+			Icon:        badge.Icon,
+			Color:       badge.Color,
+			Type:        badge.Type,
+		})
+	}
+
+	for _, challenge := range challenges {
+		manifest.Challenges = append(manifest.Challenges, meta.ArchiveChallengeLayout{
+			Slug:           challenge.Slug,
+			Order:          challenge.Order,
+			CoverPositionX: challenge.CoverPositionX,
+			CoverPositionY: challenge.CoverPositionY,
+			CoverZoom:      challenge.CoverZoom,
+		})
+	}
+
+	return manifest, nil
+}
+
+// exportArchiveObject copies one bucket object into the archive
+func exportArchiveObject(ctx context.Context, tw *tar.Writer, object minio.ObjectInfo, bySlug map[string]models.Challenge) error {
+	obj, err := config.FS.GetObject(ctx, bucketNameChallenges, object.Key, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", object.Key, err)
+	}
+	defer obj.Close()
+
+	name := archiveChallengesPrefix + object.Key
+	slug := strings.Split(object.Key, "/")[0]
+	challenge, known := bySlug[slug]
+	if path.Base(object.Key) != "chall.yml" || !known {
+		header := &tar.Header{Name: name, Mode: 0644, Size: object.Size, ModTime: object.LastModified}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, obj); err != nil {
+			return fmt.Errorf("failed to export %s: %w", object.Key, err)
+		}
+		return nil
+	}
+
+	content, err := io.ReadAll(obj)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", object.Key, err)
+	}
+	updated, err := applyChallengeStateToYAML(content, challenge)
+	if err != nil {
+		log.Printf("Warning: exporting %s unchanged: %v", object.Key, err)
+		updated = content
+	}
+	return writeArchiveEntry(tw, name, updated)
+}
+
+func writeArchiveEntry(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
+
+// applyChallengeStateToYAML overwrites the chall.yml keys that can be edited from the admin panel.
+// Flags are kept from the original file since only their hash may be stored.
+func applyChallengeStateToYAML(content []byte, challenge models.Challenge) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	set := func(key string, value interface{}) {
+		for i := range doc {
+			if doc[i].Key == key {
+				doc[i].Value = value
+				return
+			}
+		}
+		doc = append(doc, yaml.MapItem{Key: key, Value: value})
+	}
+	unset := func(key string) {
+		for i := range doc {
+			if doc[i].Key == key {
+				doc = append(doc[:i], doc[i+1:]...)
+				return
+			}
+		}
+	}
+
+	set("name", challenge.Name)
+	set("description", challenge.Description)
+	set("author", challenge.Author)
+	set("hidden", challenge.Hidden)
+	set("points", challenge.Points)
+	if challenge.ChallengeCategory != nil {
+		set("category", challenge.ChallengeCategory.Name)
+	}
+	if challenge.ChallengeDifficulty != nil {
+		set("difficulty", challenge.ChallengeDifficulty.Name)
+	}
+	if challenge.DecayFormula != nil {
+		set("decay", challenge.DecayFormula.Name)
+	}
+
+	if len(challenge.Hints) > 0 {
+		hints := make([]meta.HintMetadata, 0, len(challenge.Hints))
+		for _, hint := range challenge.Hints {
+			isActive := hint.IsActive
+			hintMeta := meta.HintMetadata{Title: hint.Title, Content: hint.Content, Cost: hint.Cost, IsActive: &isActive}
+			if hint.AutoActiveAt != nil {
+				autoActiveAt := hint.AutoActiveAt.UTC().Format(time.RFC3339)
+				hintMeta.AutoActiveAt = &autoActiveAt
+			}
+			hints = append(hints, hintMeta)
+		}
+		set("hints", hints)
+	} else {
+		unset("hints")
+	}
+
+	set("enableFirstBlood", challenge.EnableFirstBlood)
+	if challenge.EnableFirstBlood && len(challenge.FirstBloodBonuses) > 0 {
+		bonuses := make([]int, len(challenge.FirstBloodBonuses))
+		for i, bonus := range challenge.FirstBloodBonuses {
+			bonuses[i] = int(bonus)
+		}
+		set("firstBlood", meta.FirstBloodMetadata{Bonuses: bonuses, Badges: challenge.FirstBloodBadges})
+	} else {
+		unset("firstBlood")
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// ImportChallengesArchive uploads the challenge folders of an archive to the bucket and syncs them.
+// Challenges are matched by slug, so importing the same archive twice leaves the event unchanged.
+func ImportChallengesArchive(ctx context.Context, r io.Reader, updatesHub *Hub) (*ArchiveImportResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	result := &ArchiveImportResult{}
+	var manifest *meta.ArchiveManifest
+	var challengeKeys []string
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("invalid archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		switch {
+		case name == archiveManifestName:
+			manifest = &meta.ArchiveManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return result, fmt.Errorf("invalid manifest: %w", err)
+			}
+			if manifest.Version > meta.ArchiveVersion {
+				return result, fmt.Errorf("unsupported archive version %d", manifest.Version)
+			}
+			if err := importArchiveReferences(manifest); err != nil {
+				return result, err
+			}
+
+		case strings.HasPrefix(name, archiveChallengesPrefix):
+			if manifest == nil {
+				return result, fmt.Errorf("invalid archive: %s must come first", archiveManifestName)
+			}
+			key := strings.TrimPrefix(name, archiveChallengesPrefix)
+			if key == "" || key == ".." || strings.HasPrefix(key, "../") || path.IsAbs(key) {
+				return result, fmt.Errorf("invalid archive path: %s", header.Name)
+			}
+			if _, err := config.FS.PutObject(ctx, bucketNameChallenges, key, tr, header.Size, minio.PutObjectOptions{}); err != nil {
+				return result, fmt.Errorf("failed to upload %s: %w", key, err)
+			}
+			result.Files++
+			if path.Base(key) == "chall.yml" {
+				challengeKeys = append(challengeKeys, key)
+			}
+
+		default:
+			debug.Log("Ignoring archive entry %s", header.Name)
+		}
+	}
+
+	if manifest == nil {
+		return result, fmt.Errorf("invalid archive: %s not found", archiveManifestName)
+	}
+
+	for _, key := range challengeKeys {
+		if err := SyncChallengesFromMinIO(ctx, bucketNameChallenges+"/"+key, updatesHub); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		result.Challenges++
+	}
+
+	for _, layout := range manifest.Challenges {
+		if err := config.DB.Model(&models.Challenge{}).Where(querySlug, layout.Slug).Updates(map[string]interface{}{
+			"order":            layout.Order,
+			"cover_position_x": layout.CoverPositionX,
+			"cover_position_y": layout.CoverPositionY,
+			"cover_zoom":       layout.CoverZoom,
+		}).Error; err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", layout.Slug, err))
+		}
+	}
+
+	log.Printf("Archive imported: %d files, %d challenges, %d errors", result.Files, result.Challenges, len(result.Errors))
+	return result, nil
+}
+
+// importArchiveReferences creates or updates the categories, difficulties, decay formulas and badges of a manifest
+func importArchiveReferences(manifest *meta.ArchiveManifest) error {
+	for _, name := range manifest.Categories {
+		category := models.ChallengeCategory{Name: name}
+		if err := createOrGetEntity(&category, map[string]interface{}{"name": name}); err != nil {
+			return fmt.Errorf("failed to import category %s: %w", name, err)
+		}
+	}
+
+	for _, name := range manifest.Difficulties {
+		difficulty := models.ChallengeDifficulty{Name: name}
+		if err := createOrGetEntity(&difficulty, map[string]interface{}{"name": name}); err != nil {
+			return fmt.Errorf("failed to import difficulty %s: %w", name, err)
+		}
+	}
+
+	for _, f := range manifest.DecayFormulas {
+		var formula models.DecayFormula
+		config.DB.Where("name = ?", f.Name).Limit(1).Find(&formula)
+		formula.Name = f.Name
+		formula.Type = f.Type
+		formula.Step = f.Step
+		formula.MinPoints = f.MinPoints
+		formula.Interval = f.Interval
+		if err := config.DB.Save(&formula).Error; err != nil {
+			return fmt.Errorf("failed to import decay formula %s: %w", f.Name, err)
+		}
+	}
+
+	for _, b := range manifest.Badges {
+		var badge models.Badge
+		config.DB.Where("name = ?", b.Name).Limit(1).Find(&badge)
+		badge.Name = b.Name
+		badge.Description = b.Description
+		badge.Icon = b.Icon
+		badge.Color = b.Color
+		badge.Type = b.Type
+		if err := config.DB.Save(&badge).Error; err != nil {
+			return fmt.Errorf("failed to import badge %s: %w", b.Name, err)
+		}
+	}
+
+	return nil
+}
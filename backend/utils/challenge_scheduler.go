@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// ChallengeScheduler manages the automatic release and hiding of challenges
+type ChallengeScheduler struct {
+	ticker   *time.Ticker
+	stopChan chan bool
+	running  bool
+}
+
+// NewChallengeScheduler creates a new challenge scheduler
+func NewChallengeScheduler() *ChallengeScheduler {
+	return &ChallengeScheduler{
+		stopChan: make(chan bool),
+		running:  false,
+	}
+}
+
+// Start begins the challenge release scheduler
+func (cs *ChallengeScheduler) Start() {
+	if cs.running {
+		log.Println("Challenge scheduler is already running")
+		return
+	}
+
+	cs.ticker = time.NewTicker(1 * time.Minute)
+	cs.running = true
+
+	log.Println("Challenge scheduler started, checking every minute")
+
+	// Check immediately on startup to catch up on releases missed while down
+	ApplyChallengeSchedule()
+
+	go func() {
+		for {
+			select {
+			case <-cs.ticker.C:
+				ApplyChallengeSchedule()
+			case <-cs.stopChan:
+				log.Println("Challenge scheduler stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the challenge scheduler
+func (cs *ChallengeScheduler) Stop() {
+	if !cs.running {
+		return
+	}
+
+	cs.running = false
+	if cs.ticker != nil {
+		cs.ticker.Stop()
+	}
+	cs.stopChan <- true
+}
+
+// Global scheduler instance
+var globalChallengeScheduler *ChallengeScheduler
+
+// StartChallengeScheduler starts the global challenge scheduler
+func StartChallengeScheduler() {
+	if globalChallengeScheduler == nil {
+		globalChallengeScheduler = NewChallengeScheduler()
+	}
+	globalChallengeScheduler.Start()
+}
+
+// StopChallengeScheduler stops the global challenge scheduler
+func StopChallengeScheduler() {
+	if globalChallengeScheduler != nil {
+		globalChallengeScheduler.Stop()
+	}
+}
+
+// BroadcastChallengeUpdate tells connected clients to reload the challenges
+func BroadcastChallengeUpdate() {
+	if UpdatesHub != nil {
+		if payload, err := json.Marshal(map[string]interface{}{
+			"event":  "challenge-category",
+			"action": "challenge_update",
+		}); err == nil {
+			UpdatesHub.SendToAll(payload)
+		}
+	}
+}
+
+// ApplyChallengeSchedule reveals challenges whose release time has come and hides the ones past their hide time.
+// A challenge changed after its scheduled time was edited by hand, and the scheduler leaves it alone.
+func ApplyChallengeSchedule() {
+	now := time.Now()
+
+	var released []models.Challenge
+	if err := config.DB.Where("hidden = true AND archived_at IS NULL AND release_at <= ? AND updated_at < release_at AND (hide_at IS NULL OR hide_at > ?)", now, now).
+		Order("\"order\" ASC, id ASC").Find(&released).Error; err != nil {
+		log.Printf("Failed to fetch challenges to release: %v", err)
+		return
+	}
+
+	var expired []models.Challenge
+	if err := config.DB.Where("hidden = false AND hide_at <= ? AND updated_at < hide_at", now).Find(&expired).Error; err != nil {
+		log.Printf("Failed to fetch challenges to hide: %v", err)
+		return
+	}
+
+	var releasedNames []string
+	for _, challenge := range released {
+		if err := config.DB.Model(&challenge).Update("hidden", false).Error; err != nil {
+			log.Printf("Failed to release challenge %d: %v", challenge.ID, err)
+			continue
+		}
+		releasedNames = append(releasedNames, challenge.Name)
+		log.Printf("Released challenge %d (%s)", challenge.ID, challenge.Name)
+	}
+
+	hiddenCount := 0
+	for _, challenge := range expired {
+		if err := config.DB.Model(&challenge).Update("hidden", true).Error; err != nil {
+			log.Printf("Failed to hide challenge %d: %v", challenge.ID, err)
+			continue
+		}
+		hiddenCount++
+		log.Printf("Hid challenge %d (%s)", challenge.ID, challenge.Name)
+	}
+
+	if len(releasedNames) == 0 && hiddenCount == 0 {
+		return
+	}
+	BroadcastChallengeUpdate()
+
+	if len(releasedNames) > 0 {
+		notification := models.Notification{
+			Title:   "New challenges released",
+			Message: fmt.Sprintf("A new wave is available: %s", strings.Join(releasedNames, ", ")),
+			Type:    "info",
+		}
+		if err := SendNotification(&notification); err != nil {
+			log.Printf("Failed to announce released challenges: %v", err)
+		}
+	}
+}
@@ -1,121 +1,154 @@
 package utils
 
 import (
+	"fmt"
 	"math"
-	
+	"time"
+
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 )
 
+// Decay formula types
+const (
+	DecayTypeFixed       = "fixed"
+	DecayTypeLogarithmic = "logarithmic"
+	DecayTypeDynamic     = "dynamic"
+	DecayTypeTime        = "time"
+)
+
 type DecayService struct{}
 
 func NewDecay() *DecayService {
 	return &DecayService{}
 }
 
-// CalculateCurrentPoints calcule les points actuels d'un challenge en fonction du nombre de solves
-func (ds *DecayService) CalculateCurrentPoints(challenge *models.Challenge) int {
-	// Si aucune decay formula n'est définie, retourner les points de base
-	if challenge.DecayFormulaID == 0 {
-		return challenge.Points
-	}
-
-	var decay models.DecayFormula
-	if err := config.DB.Where("id = ?", challenge.DecayFormulaID).First(&decay).Error; err != nil {
-		// Si la decay formula n'existe pas, retourner les points de base
-		return challenge.Points
+// ValidateDecayFormula checks the parameters required by the formula type
+func ValidateDecayFormula(decay *models.DecayFormula) error {
+	switch decay.Type {
+	case DecayTypeFixed, DecayTypeLogarithmic:
+	case DecayTypeDynamic:
+		if decay.Step <= 0 {
+			return fmt.Errorf("invalid_decay_step")
+		}
+	case DecayTypeTime:
+		if decay.Interval <= 0 {
+			return fmt.Errorf("invalid_decay_interval")
+		}
+	default:
+		return fmt.Errorf("invalid_decay_type")
 	}
-
-	// Compter le nombre de solves pour ce challenge
-	var solveCount int64
-	config.DB.Model(&models.Solve{}).Where("challenge_id = ?", challenge.ID).Count(&solveCount)
-
-	// If only one solve, return base points (no decay for first solve)
-	if solveCount <= 1 {
-		return challenge.Points
+	if decay.Step < 0 || decay.MinPoints < 0 {
+		return fmt.Errorf("invalid_decay_values")
 	}
-
-	// For decay calculation: use solveCount directly as solveNumber
-	// solveCount=2 means 2 solves, so we calculate decay for the 2nd solve
-	return ds.calculateSolveBasedDecayDirect(challenge, &decay, int(solveCount))
+	return nil
 }
 
-// CalculateDecayedPoints calcule les points pour un solve spécifique en fonction du nombre total de solves
-func (ds *DecayService) CalculateDecayedPoints(challenge *models.Challenge, solvePosition int) int {
-	// Si aucune decay formula n'est définie, retourner les points de base
+// loadDecayFormula returns the decay formula of a challenge, using the preloaded one when available
+func loadDecayFormula(challenge *models.Challenge) (*models.DecayFormula, bool) {
 	if challenge.DecayFormulaID == 0 {
-		return challenge.Points
+		return nil, false
+	}
+	if challenge.DecayFormula != nil && challenge.DecayFormula.ID == challenge.DecayFormulaID {
+		return challenge.DecayFormula, true
 	}
 
 	var decay models.DecayFormula
 	if err := config.DB.Where("id = ?", challenge.DecayFormulaID).First(&decay).Error; err != nil {
-		// Si la decay formula n'existe pas, retourner les points de base
-		return challenge.Points
+		return nil, false
 	}
-
-	// Utiliser la position du solve comme nombre de solves à ce moment-là
-	return ds.calculateSolveBasedDecay(challenge, &decay, solvePosition)
+	return &decay, true
 }
 
-// calculateSolveBasedDecay calcule le decay basé sur le nombre de solves (0-based position)
-func (ds *DecayService) calculateSolveBasedDecay(challenge *models.Challenge, decay *models.DecayFormula, solvePosition int) int {
-	basePoints := challenge.Points
+// decayReleaseTime returns the moment time-based decay starts from: the challenge release, else the CTF
+// start, else the challenge creation
+func decayReleaseTime(challenge *models.Challenge) time.Time {
+	if challenge.ReleaseAt != nil {
+		return *challenge.ReleaseAt
+	}
+	if startTime, ok := config.GetCTFStartTime(); ok {
+		return startTime
+	}
+	return challenge.CreatedAt
+}
 
-	// La position est 0-based, donc on ajoute 1 pour avoir le numéro de solve (1-based)
-	solveNumber := solvePosition + 1
+// IsTimeBased tells if the points of a challenge depend on when it is solved rather than on the solve count
+func (ds *DecayService) IsTimeBased(challenge *models.Challenge) bool {
+	decay, ok := loadDecayFormula(challenge)
+	return ok && decay.Type == DecayTypeTime
+}
 
-	// Si c'est le premier solve, pas de decay
-	if solveNumber <= 1 {
-		return basePoints
+// CalculateCurrentPoints calcule les points actuels d'un challenge en fonction du nombre de solves
+func (ds *DecayService) CalculateCurrentPoints(challenge *models.Challenge) int {
+	// Si aucune decay formula n'est définie, retourner les points de base
+	decay, ok := loadDecayFormula(challenge)
+	if !ok {
+		return challenge.Points
 	}
 
-	var currentPoints int
+	if decay.Type == DecayTypeTime {
+		return ds.calculateDecay(challenge, decay, 1, time.Now())
+	}
 
-	// Apply decay based on type
-	switch decay.Type {
-	case "fixed":
-		// No decay - always return base points
-		currentPoints = basePoints
+	// Compter le nombre de solves pour ce challenge
+	var solveCount int64
+	config.DB.Model(&models.Solve{}).Where("challenge_id = ?", challenge.ID).Count(&solveCount)
 
-	case "logarithmic":
-		fallthrough
-	default:
-		// Logarithmic decay: basePoints - (step * log2(solveNumber))
-		// Points decay quickly at first, then slow down
-		logValue := math.Log2(float64(solveNumber))
-		pointsLost := int(float64(decay.Step) * logValue)
-		currentPoints = basePoints - pointsLost
-	}
+	// solveCount=2 means 2 solves, so we calculate decay for the 2nd solve
+	return ds.calculateDecay(challenge, decay, int(solveCount), time.Now())
+}
 
-	// S'assurer qu'on ne descend pas en dessous du minimum
-	if currentPoints < decay.MinPoints {
-		currentPoints = decay.MinPoints
+// CalculateDecayedPoints calcule les points pour un solve spécifique (position 0-based) fait à solvedAt.
+// The submit path and RecalculateTeamPoints both rely on it so a solve is always worth the same.
+func (ds *DecayService) CalculateDecayedPoints(challenge *models.Challenge, solvePosition int, solvedAt time.Time) int {
+	decay, ok := loadDecayFormula(challenge)
+	if !ok {
+		return challenge.Points
 	}
 
-	return currentPoints
+	// La position est 0-based, donc on ajoute 1 pour avoir le numéro de solve (1-based)
+	return ds.calculateDecay(challenge, decay, solvePosition+1, solvedAt)
 }
 
-// calculateSolveBasedDecayDirect calcule le decay basé sur le nombre de solves (1-based count, no conversion)
-func (ds *DecayService) calculateSolveBasedDecayDirect(challenge *models.Challenge, decay *models.DecayFormula, solveNumber int) int {
+// calculateDecay applies a decay formula for the solveNumber-th solve (1-based) made at solvedAt
+func (ds *DecayService) calculateDecay(challenge *models.Challenge, decay *models.DecayFormula, solveNumber int, solvedAt time.Time) int {
 	basePoints := challenge.Points
-
-	// solveNumber is already 1-based (1 = first solve, 2 = second solve, etc.)
-	// First solve has no decay
-	if solveNumber <= 1 {
-		return basePoints
-	}
-
 	var currentPoints int
 
-	// Apply decay based on type
 	switch decay.Type {
-	case "fixed":
+	case DecayTypeFixed:
 		// No decay - always return base points
-		currentPoints = basePoints
+		return basePoints
 
-	case "logarithmic":
+	case DecayTypeTime:
+		// Time decay: basePoints - (step × number of intervals elapsed since release)
+		if decay.Interval <= 0 {
+			return basePoints
+		}
+		elapsed := solvedAt.Sub(decayReleaseTime(challenge))
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		intervals := int(elapsed / (time.Duration(decay.Interval) * time.Minute))
+		currentPoints = basePoints - decay.Step*intervals
+
+	case DecayTypeDynamic:
+		// CTFd quadratic decay: reaches minPoints after `step` solves, first solve gets full points
+		if solveNumber <= 1 || decay.Step <= 0 {
+			return basePoints
+		}
+		solves := float64(solveNumber - 1)
+		steps := float64(decay.Step)
+		value := (float64(decay.MinPoints-basePoints)/(steps*steps))*(solves*solves) + float64(basePoints)
+		currentPoints = int(math.Ceil(value))
+
+	case DecayTypeLogarithmic:
 		fallthrough
 	default:
+		// Si c'est le premier solve, pas de decay
+		if solveNumber <= 1 {
+			return basePoints
+		}
 		// Logarithmic decay: basePoints - (step * log2(solveNumber))
 		// Points decay quickly at first, then slow down
 		logValue := math.Log2(float64(solveNumber))
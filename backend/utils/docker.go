@@ -25,12 +25,18 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	"github.com/pwnthemall/pwnthemall/backend/config"
 	"github.com/pwnthemall/pwnthemall/backend/debug"
 	"github.com/pwnthemall/pwnthemall/backend/models"
 )
 
+var errDockerClientMissing = fmt.Errorf("docker client not connected")
+
+// ManagedLabel marks containers started by the platform so orphans can be found later
+const ManagedLabel = "pwnthemall.managed"
+
 func EnsureDockerClientConnected() error {
 	if config.DockerClient == nil {
 		if err := config.ConnectDocker(); err != nil {
@@ -103,9 +109,9 @@ func FindAvailablePorts(count int) ([]int, error) {
 	return ports, nil
 }
 
-func BuildDockerImage(slug string, sourceDir string) (string, error) {
-	if err := EnsureDockerClientConnected(); err != nil {
-		return "", err
+func BuildDockerImage(cli *client.Client, slug string, sourceDir string) (string, error) {
+	if cli == nil {
+		return "", errDockerClientMissing
 	}
 	tarReader, err := TarDirectory(sourceDir)
 	if err != nil {
@@ -123,7 +129,7 @@ func BuildDockerImage(slug string, sourceDir string) (string, error) {
 		Remove:         true,
 		SuppressOutput: true,
 	}
-	buildResponse, err := config.DockerClient.ImageBuild(ctx, tarReader, buildOptions)
+	buildResponse, err := cli.ImageBuild(ctx, tarReader, buildOptions)
 	if err != nil {
 		return imageName, err
 	}
@@ -137,9 +143,9 @@ func BuildDockerImage(slug string, sourceDir string) (string, error) {
 	return imageName, nil
 }
 
-func IsImageBuilt(slug string) (string, bool) {
-	if err := EnsureDockerClientConnected(); err != nil {
-		log.Printf("Docker client not connected: %v", err)
+func IsImageBuilt(cli *client.Client, slug string) (string, bool) {
+	if cli == nil {
+		log.Printf("Docker client not connected")
 		return "", false
 	}
 
@@ -156,7 +162,7 @@ func IsImageBuilt(slug string) (string, bool) {
 	filtersArgs := filters.NewArgs()
 	filtersArgs.Add("reference", imageName)
 
-	images, err := config.DockerClient.ImageList(ctx, image.ListOptions{
+	images, err := cli.ImageList(ctx, image.ListOptions{
 		Filters: filtersArgs,
 	})
 	if err != nil {
@@ -170,13 +176,13 @@ func IsImageBuilt(slug string) (string, bool) {
 	return imageName, false
 }
 
-func StartDockerInstance(image string, teamId int, userId int, internalPorts []int, hostPorts []int) (string, error) {
+func StartDockerInstance(cli *client.Client, image string, teamId int, userId int, internalPorts []int, hostPorts []int, env map[string]string) (string, error) {
 	if len(internalPorts) != len(hostPorts) {
 		return "", fmt.Errorf("internal and host ports length mismatch")
 	}
 
-	if err := EnsureDockerClientConnected(); err != nil {
-		return "", fmt.Errorf("docker client not connected: %w", err)
+	if cli == nil {
+		return "", errDockerClientMissing
 	}
 
 	ctx := context.Background()
@@ -186,7 +192,7 @@ func StartDockerInstance(image string, teamId int, userId int, internalPorts []i
 	containerName := baseContainerName
 
 	// Check if container with this name already exists
-	existing, err := config.DockerClient.ContainerList(ctx, container.ListOptions{
+	existing, err := cli.ContainerList(ctx, container.ListOptions{
 		All: true,
 	})
 
@@ -244,12 +250,17 @@ func StartDockerInstance(image string, teamId int, userId int, internalPorts []i
 	}
 
 	debug.Log("Creating Docker network for team %d", teamId)
-	networkName, err := EnsureTeamNetworkExists(teamId)
+	networkName, err := EnsureTeamNetworkExists(cli, teamId)
 	if err != nil {
 		return fmt.Sprintf("failed to ensure team %d", teamId), err
 	}
 	debug.Log("Docker network created: %s", networkName)
 
+	containerEnv := make([]string, 0, len(env))
+	for key, value := range env {
+		containerEnv = append(containerEnv, fmt.Sprintf("%s=%s", key, value))
+	}
+
 	containerTimeout := 60
 	networkingConfig := &network.NetworkingConfig{
 		EndpointsConfig: map[string]*network.EndpointSettings{
@@ -257,12 +268,14 @@ func StartDockerInstance(image string, teamId int, userId int, internalPorts []i
 		},
 	}
 
-	resp, err := config.DockerClient.ContainerCreate(
+	resp, err := cli.ContainerCreate(
 		ctx,
 		&container.Config{
 			Image:        image,
 			Tty:          false,
 			ExposedPorts: exposedPorts,
+			Env:          containerEnv,
+			Labels:       map[string]string{ManagedLabel: "true"},
 			StopTimeout:  &containerTimeout,
 		},
 		hostConfig,
@@ -274,7 +287,7 @@ func StartDockerInstance(image string, teamId int, userId int, internalPorts []i
 		return "", fmt.Errorf("failed to create container: %w", err)
 	}
 
-	if err := config.DockerClient.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
 		return "", fmt.Errorf("failed to start container: %w", err)
 	}
 
@@ -285,12 +298,11 @@ func StartDockerInstance(image string, teamId int, userId int, internalPorts []i
 	return containerName, nil
 }
 
-func StopDockerInstance(containerName string) error {
+func StopDockerInstance(cli *client.Client, containerName string) error {
 	debug.Log("Attempting to stop Docker container: %s", containerName)
 
-	if err := EnsureDockerClientConnected(); err != nil {
-		debug.Log("Docker client connection failed: %v", err)
-		return fmt.Errorf("docker client not connected: %w", err)
+	if cli == nil {
+		return errDockerClientMissing
 	}
 
 	ctx := context.Background()
@@ -301,7 +313,7 @@ func StopDockerInstance(containerName string) error {
 	}
 
 	debug.Log("Removing container %s with force", containerName)
-	if err := config.DockerClient.ContainerRemove(ctx, containerName, container.RemoveOptions{Force: true}); err != nil {
+	if err := cli.ContainerRemove(ctx, containerName, container.RemoveOptions{Force: true}); err != nil {
 		debug.Log("Failed to remove container %s: %v", containerName, err)
 		return fmt.Errorf("failed to remove container %s: %w", containerName, err)
 	}
@@ -310,11 +322,11 @@ func StopDockerInstance(containerName string) error {
 	return nil
 }
 
-func EnsureTeamNetworkExists(teamId int) (string, error) {
+func EnsureTeamNetworkExists(cli *client.Client, teamId int) (string, error) {
 	ctx := context.Background()
 	networkName := fmt.Sprintf("team_%d_network", teamId)
 
-	networks, err := config.DockerClient.NetworkList(ctx, network.ListOptions{
+	networks, err := cli.NetworkList(ctx, network.ListOptions{
 		Filters: filters.NewArgs(filters.Arg("name", networkName)),
 	})
 	if err != nil {
@@ -322,16 +334,23 @@ func EnsureTeamNetworkExists(teamId int) (string, error) {
 		return "", fmt.Errorf("docker_network_unavailable")
 	}
 
-	if len(networks) > 0 {
-		return networkName, nil
-	}
-
 	subnet, gateway, err := GetTeamSubnet(teamId)
 	if err != nil {
 		debug.Log(err.Error())
 		return "", fmt.Errorf("docker_network_unavailable")
 	}
 
+	if len(networks) > 0 {
+		if teamNetworkMatchesLease(networks[0], subnet) {
+			return networkName, nil
+		}
+		// Network created before the lease (or with a released subnet), recreate it when unused
+		if err := cli.NetworkRemove(ctx, networks[0].ID); err != nil {
+			debug.Log("Keeping network %s outside of its lease %s: %v", networkName, subnet, err)
+			return networkName, nil
+		}
+	}
+
 	debug.Log("Team %d | subnet: %s | gateway: %s", teamId, subnet, gateway)
 
 	ipamConfig := &network.IPAMConfig{
@@ -343,7 +362,7 @@ func EnsureTeamNetworkExists(teamId int) (string, error) {
 		Config: []network.IPAMConfig{*ipamConfig},
 	}
 
-	_, err = config.DockerClient.NetworkCreate(
+	_, err = cli.NetworkCreate(
 		ctx,
 		networkName,
 		network.CreateOptions{
@@ -391,7 +410,7 @@ func RandomizeServicePorts(project *types.Project) ([]int, error) {
 	return ports, nil
 }
 
-func CreateComposeProject(slug string, teamId int, userId int, composeFile string) (*types.Project, error) {
+func CreateComposeProject(cli *client.Client, slug string, teamId int, userId int, composeFile string, env map[string]string) (*types.Project, error) {
 	ctx := context.TODO()
 	tmpDir, _, err := prepareChallengeContext(slug)
 	if err != nil {
@@ -424,7 +443,7 @@ func CreateComposeProject(slug string, teamId int, userId int, composeFile strin
 				}
 			}
 
-			imageName, err := BuildDockerImage(fmt.Sprintf("%s-%s", slug, svcName), sourceDir)
+			imageName, err := BuildDockerImage(cli, fmt.Sprintf("%s-%s", slug, svcName), sourceDir)
 			if err != nil {
 				return nil, fmt.Errorf("failed to build image for %s: %w", svcName, err)
 			}
@@ -436,7 +455,7 @@ func CreateComposeProject(slug string, teamId int, userId int, composeFile strin
 	}
 
 	debug.Log("Creating Docker network for team %d", teamId)
-	networkName, err := EnsureTeamNetworkExists(teamId)
+	networkName, err := EnsureTeamNetworkExists(cli, teamId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to ensure team network: %w", err)
 	}
@@ -453,6 +472,15 @@ func CreateComposeProject(slug string, teamId int, userId int, composeFile strin
 		svc.Networks = map[string]*types.ServiceNetworkConfig{
 			networkName: {Aliases: []string{svcName}},
 		}
+		if len(env) > 0 {
+			if svc.Environment == nil {
+				svc.Environment = types.MappingWithEquals{}
+			}
+			for key, value := range env {
+				v := value
+				svc.Environment[key] = &v
+			}
+		}
 		p.Services[svcName] = svc
 	}
 
@@ -460,10 +488,10 @@ func CreateComposeProject(slug string, teamId int, userId int, composeFile strin
 	return p, nil
 }
 
-func StartComposeInstance(project *types.Project, teamId int) error {
+func StartComposeInstance(cli *client.Client, project *types.Project, teamId int) error {
 	ctx := context.TODO()
 
-	networkName, err := EnsureTeamNetworkExists(teamId)
+	networkName, err := EnsureTeamNetworkExists(cli, teamId)
 	if err != nil {
 		return fmt.Errorf("failed to ensure team network: %w", err)
 	}
@@ -471,7 +499,7 @@ func StartComposeInstance(project *types.Project, teamId int) error {
 
 	dockerCli, err := command.NewDockerCli(
 		command.WithStandardStreams(),
-		command.WithAPIClient(config.DockerClient),
+		command.WithAPIClient(cli),
 	)
 	if err != nil {
 		return err
@@ -508,11 +536,11 @@ func StartComposeInstance(project *types.Project, teamId int) error {
 	return nil
 }
 
-func StopComposeInstance(projectName string) error {
+func StopComposeInstance(cli *client.Client, projectName string) error {
 	ctx := context.Background()
 	dockerCli, err := command.NewDockerCli(
 		command.WithStandardStreams(),
-		command.WithAPIClient(config.DockerClient),
+		command.WithAPIClient(cli),
 	)
 	if err != nil {
 		return err
@@ -535,6 +563,29 @@ func StopComposeInstance(projectName string) error {
 	return nil
 }
 
+// ListManagedContainers returns every container started by the platform
+func ListManagedContainers(cli *client.Client) ([]container.Summary, error) {
+	if cli == nil {
+		return nil, errDockerClientMissing
+	}
+
+	return cli.ContainerList(context.Background(), container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", ManagedLabel+"=true")),
+	})
+}
+
+// ManagedContainerInstanceName returns the name stored in Instance.Container for a managed container
+func ManagedContainerInstanceName(c container.Summary) (string, bool) {
+	if project, ok := c.Labels[api.ProjectLabel]; ok && project != "" {
+		return project, true
+	}
+	if len(c.Names) == 0 {
+		return "", false
+	}
+	return strings.TrimPrefix(c.Names[0], "/"), false
+}
+
 func getDockerImagePrefix() (string, error) {
 	var cfg models.DockerConfig
 	result := config.DB.First(&cfg)
@@ -585,6 +636,7 @@ func addServiceLabels(project *types.Project) {
 			api.WorkingDirLabel:  "/",
 			api.ConfigFilesLabel: strings.Join(project.ComposeFiles, ","),
 			api.OneoffLabel:      "False", // default, will be overridden by `run` command
+			ManagedLabel:         "true",
 		}
 		z++
 		project.Services[i] = s
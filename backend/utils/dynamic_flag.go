@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DynamicFlagEnvVar is the environment variable holding the team flag inside instances
+	DynamicFlagEnvVar        = "FLAG"
+	defaultDynamicFlagPrefix = "PTA"
+	queryDynamicFlag         = "team_id = ? AND challenge_id = ?"
+)
+
+// generateDynamicFlag builds a random flag using PTA_DYNAMIC_FLAG_PREFIX as wrapper
+func generateDynamicFlag() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	prefix := os.Getenv("PTA_DYNAMIC_FLAG_PREFIX")
+	if prefix == "" {
+		prefix = defaultDynamicFlagPrefix
+	}
+	return fmt.Sprintf("%s{%s}", prefix, hex.EncodeToString(buf)), nil
+}
+
+// GetOrCreateDynamicFlag returns the flag of a team for a challenge, generating it on first use.
+// Concurrent first starts race on the unique (team_id, challenge_id) index, the flag stored first wins.
+func GetOrCreateDynamicFlag(teamID uint, challengeID uint) (string, error) {
+	var dynamicFlag models.DynamicFlag
+	err := config.DB.Where(queryDynamicFlag, teamID, challengeID).First(&dynamicFlag).Error
+	if err == nil {
+		return dynamicFlag.Value, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to load dynamic flag: %w", err)
+	}
+
+	value, err := generateDynamicFlag()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate dynamic flag: %w", err)
+	}
+
+	dynamicFlag = models.DynamicFlag{
+		Value:       value,
+		TeamID:      teamID,
+		ChallengeID: challengeID,
+	}
+	result := config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}, {Name: "challenge_id"}},
+		DoNothing: true,
+	}).Create(&dynamicFlag)
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to store dynamic flag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Another request stored the flag meanwhile
+		var stored models.DynamicFlag
+		if err := config.DB.Where(queryDynamicFlag, teamID, challengeID).First(&stored).Error; err != nil {
+			return "", fmt.Errorf("failed to load dynamic flag: %w", err)
+		}
+		return stored.Value, nil
+	}
+	debug.Log("Generated dynamic flag for team %d on challenge %d", teamID, challengeID)
+	return value, nil
+}
+
+// DynamicFlagEnv returns the environment to inject into an instance for the given flag
+func DynamicFlagEnv(flag string) map[string]string {
+	if flag == "" {
+		return nil
+	}
+	return map[string]string{DynamicFlagEnvVar: flag}
+}
+
+// ValidateDynamicFlag checks the submitted value against the flag generated for this team only
+func ValidateDynamicFlag(teamID uint, challengeID uint, submittedValue string) bool {
+	if submittedValue == "" {
+		return false
+	}
+
+	var dynamicFlag models.DynamicFlag
+	if err := config.DB.Where(queryDynamicFlag, teamID, challengeID).First(&dynamicFlag).Error; err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(dynamicFlag.Value), []byte(submittedValue)) == 1
+}
+
+// FindDynamicFlagOwner returns the team owning the submitted dynamic flag when it belongs to another team
+func FindDynamicFlagOwner(teamID uint, challengeID uint, submittedValue string) (uint, bool) {
+	if submittedValue == "" {
+		return 0, false
+	}
+
+	var dynamicFlag models.DynamicFlag
+	if err := config.DB.Where("challenge_id = ? AND team_id <> ? AND value = ?", challengeID, teamID, submittedValue).
+		First(&dynamicFlag).Error; err != nil {
+		return 0, false
+	}
+	return dynamicFlag.TeamID, true
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// NormalizeFlagType returns a supported flag type, defaulting to static
+func NormalizeFlagType(flagType string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(flagType)) {
+	case "", models.FlagTypeStatic:
+		return models.FlagTypeStatic, nil
+	case models.FlagTypeStaticCI:
+		return models.FlagTypeStaticCI, nil
+	case models.FlagTypeRegex:
+		return models.FlagTypeRegex, nil
+	}
+	return "", fmt.Errorf("unknown flag type %q", flagType)
+}
+
+// StoredFlagValue returns the value persisted for a flag: hashed for static types, the pattern for regex
+func StoredFlagValue(value string, flagType string) (string, error) {
+	switch flagType {
+	case models.FlagTypeStaticCI:
+		return HashFlag(strings.ToLower(value)), nil
+	case models.FlagTypeRegex:
+		if _, err := compileFlagRegex(value); err != nil {
+			return "", fmt.Errorf("invalid regex flag %q: %w", value, err)
+		}
+		return value, nil
+	}
+	return HashFlag(value), nil
+}
+
+// compileFlagRegex compiles a regex flag so that it must match the whole submission
+func compileFlagRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// MatchFlag checks a submitted value against a stored flag according to its type
+func MatchFlag(flag models.Flag, submittedValue string) bool {
+	if submittedValue == "" || IsGeoFlag(flag.Value) {
+		return false
+	}
+
+	switch flag.Type {
+	case models.FlagTypeStaticCI:
+		return flag.Value == HashFlag(strings.ToLower(submittedValue))
+	case models.FlagTypeRegex:
+		re, err := compileFlagRegex(flag.Value)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(submittedValue)
+	}
+	return flag.Value == HashFlag(submittedValue)
+}
@@ -0,0 +1,250 @@
+package utils
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultTeamSubnetPrefix = 24
+	maxLeaseAttempts        = 5
+)
+
+// subnetPool describes the pool team subnets are carved from
+type subnetPool struct {
+	base   uint32
+	prefix int
+	size   uint32 // addresses per team subnet
+	count  uint32 // number of team subnets in the pool
+}
+
+// loadSubnetPool reads PTA_DOCKER_CHALL_BASE_CIDR and PTA_DOCKER_TEAM_SUBNET_PREFIX
+func loadSubnetPool() (*subnetPool, error) {
+	baseCIDR := os.Getenv("PTA_DOCKER_CHALL_BASE_CIDR")
+	_, ipnet, err := net.ParseCIDR(baseCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseCIDR: %w", err)
+	}
+
+	baseIP := ipnet.IP.To4()
+	if baseIP == nil {
+		return nil, fmt.Errorf("only IPv4 supported")
+	}
+	poolPrefix, _ := ipnet.Mask.Size()
+
+	prefix := defaultTeamSubnetPrefix
+	if raw := os.Getenv("PTA_DOCKER_TEAM_SUBNET_PREFIX"); raw != "" {
+		if prefix, err = strconv.Atoi(raw); err != nil {
+			return nil, fmt.Errorf("invalid team subnet prefix: %w", err)
+		}
+	}
+	if prefix > 30 {
+		return nil, fmt.Errorf("team subnet prefix must be /30 or larger")
+	}
+	if prefix < poolPrefix {
+		// The pool is smaller than a team subnet: it holds a single team subnet
+		prefix = poolPrefix
+	}
+
+	return &subnetPool{
+		base:   binary.BigEndian.Uint32(baseIP),
+		prefix: prefix,
+		size:   1 << uint(32-prefix),
+		count:  1 << uint(prefix-poolPrefix),
+	}, nil
+}
+
+// subnet returns the CIDR and gateway of the n-th team subnet of the pool
+func (p *subnetPool) subnet(n uint32) (string, string) {
+	start := p.base + n*p.size
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, start)
+	gateway := make(net.IP, 4)
+	binary.BigEndian.PutUint32(gateway, start+1)
+	return fmt.Sprintf("%s/%d", ip.String(), p.prefix), gateway.String()
+}
+
+// dockerSubnet is an IPv4 subnet used by a docker network, teamID is set for team networks
+type dockerSubnet struct {
+	teamID  uint
+	subnet  string
+	gateway string
+	ipnet   *net.IPNet
+}
+
+// teamNetworkID returns the team of a team_<id>_network name
+func teamNetworkID(name string) (uint, bool) {
+	var teamID uint
+	if _, err := fmt.Sscanf(name, "team_%d_network", &teamID); err != nil || name != fmt.Sprintf("team_%d_network", teamID) {
+		return 0, false
+	}
+	return teamID, true
+}
+
+// listDockerSubnets returns the IPv4 subnets of the networks of every docker target
+func listDockerSubnets() []dockerSubnet {
+	var subnets []dockerSubnet
+	for _, target := range ListDockerTargets() {
+		networks, err := target.Client.NetworkList(context.Background(), network.ListOptions{})
+		if err != nil {
+			debug.Log("Could not list networks on %s: %v", target.Name, err)
+			continue
+		}
+		for _, nw := range networks {
+			teamID, _ := teamNetworkID(nw.Name)
+			for _, cfg := range nw.IPAM.Config {
+				_, ipnet, err := net.ParseCIDR(cfg.Subnet)
+				if err != nil || ipnet.IP.To4() == nil {
+					continue
+				}
+				subnets = append(subnets, dockerSubnet{teamID: teamID, subnet: cfg.Subnet, gateway: cfg.Gateway, ipnet: ipnet})
+			}
+		}
+	}
+	return subnets
+}
+
+// subnetsOverlap tells if two subnets share addresses
+func subnetsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// leaseExistingSubnet records the subnet of a team network created before leases, unless already taken
+func leaseExistingSubnet(s dockerSubnet) bool {
+	gateway := s.gateway
+	if gateway == "" {
+		ip := s.ipnet.IP.To4()
+		gateway = net.IPv4(ip[0], ip[1], ip[2], ip[3]+1).String()
+	}
+	lease := models.SubnetLease{TeamID: s.teamID, Subnet: s.subnet, Gateway: gateway}
+	result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&lease)
+	if result.Error != nil {
+		debug.Log("Could not lease existing subnet %s to team %d: %v", s.subnet, s.teamID, result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
+
+// SeedSubnetLeases leases to each team the subnet its existing team network uses, so networks created
+// before leases (x.y.<teamID>.0/24) keep their subnet and new leases never collide with them
+func SeedSubnetLeases() {
+	seeded := 0
+	for _, s := range listDockerSubnets() {
+		if s.teamID != 0 && leaseExistingSubnet(s) {
+			seeded++
+		}
+	}
+	if seeded > 0 {
+		log.Printf("Subnet leases: %d existing team network(s) recorded", seeded)
+	}
+}
+
+// LeaseTeamSubnet returns the subnet leased to a team, allocating the first free one of the pool if needed.
+// Subnets overlapping a docker network of any target are skipped.
+func LeaseTeamSubnet(teamID uint) (string, string, error) {
+	var lease models.SubnetLease
+	if err := config.DB.Where("team_id = ?", teamID).First(&lease).Error; err == nil {
+		return lease.Subnet, lease.Gateway, nil
+	}
+
+	pool, err := loadSubnetPool()
+	if err != nil {
+		return "", "", err
+	}
+
+	inUse := listDockerSubnets()
+	for _, s := range inUse {
+		// The team network already exists: keep its subnet
+		if s.teamID == teamID && leaseExistingSubnet(s) {
+			if err := config.DB.Where("team_id = ?", teamID).First(&lease).Error; err != nil {
+				return "", "", fmt.Errorf("failed to fetch subnet lease: %w", err)
+			}
+			return lease.Subnet, lease.Gateway, nil
+		}
+	}
+
+	for attempt := 0; attempt < maxLeaseAttempts; attempt++ {
+		var leased []string
+		if err := config.DB.Model(&models.SubnetLease{}).Pluck("subnet", &leased).Error; err != nil {
+			return "", "", fmt.Errorf("failed to fetch subnet leases: %w", err)
+		}
+		used := make(map[string]bool, len(leased))
+		for _, s := range leased {
+			used[s] = true
+		}
+
+		var subnet, gateway string
+		for n := uint32(0); n < pool.count && subnet == ""; n++ {
+			candidate, gw := pool.subnet(n)
+			if used[candidate] {
+				continue
+			}
+			_, candidateNet, _ := net.ParseCIDR(candidate)
+			free := true
+			for _, s := range inUse {
+				if subnetsOverlap(candidateNet, s.ipnet) {
+					free = false
+					break
+				}
+			}
+			if free {
+				subnet, gateway = candidate, gw
+			}
+		}
+		if subnet == "" {
+			return "", "", fmt.Errorf("subnet pool exhausted")
+		}
+
+		lease = models.SubnetLease{TeamID: teamID, Subnet: subnet, Gateway: gateway}
+		if err := config.DB.Create(&lease).Error; err != nil {
+			// Another request leased this subnet (or this team) concurrently, look again
+			var existing models.SubnetLease
+			if config.DB.Where("team_id = ?", teamID).First(&existing).Error == nil {
+				return existing.Subnet, existing.Gateway, nil
+			}
+			debug.Log("Subnet lease conflict for team %d on %s: %v", teamID, subnet, err)
+			continue
+		}
+
+		debug.Log("Leased subnet %s to team %d", subnet, teamID)
+		return subnet, gateway, nil
+	}
+
+	return "", "", fmt.Errorf("could not lease a subnet for team %d", teamID)
+}
+
+// ReleaseTeamSubnet frees the subnet of a team and removes its challenge networks
+func ReleaseTeamSubnet(teamID uint) error {
+	networkName := fmt.Sprintf("team_%d_network", teamID)
+	for _, target := range ListDockerTargets() {
+		if err := target.Client.NetworkRemove(context.Background(), networkName); err != nil {
+			debug.Log("Could not remove network %s on %s: %v", networkName, target.Name, err)
+		}
+	}
+
+	if err := config.DB.Where("team_id = ?", teamID).Delete(&models.SubnetLease{}).Error; err != nil {
+		return fmt.Errorf("failed to release subnet of team %d: %w", teamID, err)
+	}
+	return nil
+}
+
+// teamNetworkMatchesLease tells if an existing docker network uses the subnet leased to its team
+func teamNetworkMatchesLease(nw network.Summary, subnet string) bool {
+	for _, cfg := range nw.IPAM.Config {
+		if cfg.Subnet == subnet {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"net"
+	"testing"
+)
+
+func TestTeamNetworkID(t *testing.T) {
+	cases := map[string]struct {
+		id uint
+		ok bool
+	}{
+		"team_3_network":     {3, true},
+		"team_120_network":   {120, true},
+		"team_3_network_old": {0, false},
+		"team_x_network":     {0, false},
+		"bridge":             {0, false},
+	}
+	for name, want := range cases {
+		id, ok := teamNetworkID(name)
+		if id != want.id || ok != want.ok {
+			t.Errorf("teamNetworkID(%q) = %d, %v, want %d, %v", name, id, ok, want.id, want.ok)
+		}
+	}
+}
+
+func TestSubnetsOverlap(t *testing.T) {
+	parse := func(cidr string) *net.IPNet {
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ipnet
+	}
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"172.30.3.0/24", "172.30.3.0/24", true},
+		{"172.30.0.0/16", "172.30.3.0/24", true},
+		{"172.30.3.0/24", "172.30.0.0/16", true},
+		{"172.30.3.0/24", "172.30.4.0/24", false},
+		{"172.30.3.0/25", "172.30.3.128/25", false},
+	}
+	for _, c := range cases {
+		if got := subnetsOverlap(parse(c.a), parse(c.b)); got != c.want {
+			t.Errorf("subnetsOverlap(%s, %s) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package utils
 
 import (
+	"errors"
 	"os"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,15 +15,19 @@ var (
 )
 
 type TokenClaims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID     uint   `json:"user_id"`
+	Role       string `json:"role"`
+	Scope      string `json:"-"` // Scope of the API token, empty for cookie sessions
+	APITokenID uint   `json:"-"`
+	SessionID  uint   `json:"sid,omitempty"` // Session the access token was issued for, it stops working once revoked
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(userID uint, role string) (string, error) {
+func GenerateAccessToken(userID uint, role string, sessionID uint) (string, error) {
 	claims := TokenClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(45 * time.Minute)),
 		},
@@ -32,15 +36,6 @@ func GenerateAccessToken(userID uint, role string) (string, error) {
 	return token.SignedString(AccessSecret)
 }
 
-func GenerateRefreshToken(userID uint) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   strconv.Itoa(int(userID)),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(RefreshSecret)
-}
-
 func GetClaimsFromCookie(c *gin.Context) (*TokenClaims, error) {
 	tokenStr, err := c.Cookie("access_token")
 	if err != nil {
@@ -54,5 +49,13 @@ func GetClaimsFromCookie(c *gin.Context) (*TokenClaims, error) {
 		return nil, err
 	}
 
-	return token.Claims.(*TokenClaims), nil
+	claims := token.Claims.(*TokenClaims)
+	// Tokens issued before sessions existed carry none and last until they expire
+	if claims.SessionID != 0 {
+		if !SessionActive(claims.SessionID) {
+			return nil, errors.New("session_revoked")
+		}
+		c.Set("session_id", claims.SessionID)
+	}
+	return claims, nil
 }
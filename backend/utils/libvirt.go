@@ -0,0 +1,422 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/lib/pq"
+	"github.com/minio/minio-go/v7"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+const (
+	defaultVMMemory      = 512
+	defaultVMVCPUs       = 1
+	defaultLibvirtPool   = "default"
+	defaultVMPortRange   = "40000-49999"
+	vmBaseVolumeHashSize = 12
+)
+
+var errLibvirtMissing = fmt.Errorf("libvirt not connected")
+
+// vmImageStore gives access to the disk images of vm challenges, faked in tests
+type vmImageStore interface {
+	ETag(key string) (string, error)
+	Open(key string) (io.ReadCloser, error)
+}
+
+// minioImageStore reads disk images from the challenges bucket
+type minioImageStore struct{}
+
+func (minioImageStore) ETag(key string) (string, error) {
+	info, err := config.FS.StatObject(context.Background(), bucketNameChallenges, key, minio.StatObjectOptions{})
+	if err != nil {
+		return "", err
+	}
+	return info.ETag, nil
+}
+
+func (minioImageStore) Open(key string) (io.ReadCloser, error) {
+	return config.FS.GetObject(context.Background(), bucketNameChallenges, key, minio.GetObjectOptions{})
+}
+
+var vmImages vmImageStore = minioImageStore{}
+
+var (
+	// vmBaseLocks serializes the base disk upload of each challenge
+	vmBaseLocks sync.Map
+	// reservedVMPorts holds the ports handed to VMs still booting, before their instance row exists
+	reservedVMPorts   = map[int]bool{}
+	reservedVMPortsMu sync.Mutex
+)
+
+// EnsureLibvirtConnected connects to libvirt if no connection is available yet
+func EnsureLibvirtConnected() error {
+	if config.Libvirt == nil {
+		return config.ConnectLibvirt()
+	}
+	if _, err := config.Libvirt.Version(); err != nil {
+		return config.ConnectLibvirt()
+	}
+	return nil
+}
+
+// libvirtPool returns the storage pool holding VM disks
+func libvirtPool() string {
+	if pool := os.Getenv("PTA_LIBVIRT_POOL"); pool != "" {
+		return pool
+	}
+	return defaultLibvirtPool
+}
+
+// LibvirtPublicIP returns the address replacing $ip in connection info of VM instances
+func LibvirtPublicIP() string {
+	return os.Getenv("PTA_LIBVIRT_WORKER_IP")
+}
+
+// vmBaseVolumeName returns the pool volume holding a version of the base disk of a challenge.
+// The ETag of the image is part of the name so that a new image is uploaded instead of reusing the old one.
+func vmBaseVolumeName(slug, etag string) string {
+	hash := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(etag))
+	if len(hash) > vmBaseVolumeHashSize {
+		hash = hash[:vmBaseVolumeHashSize]
+	}
+	if hash == "" {
+		return fmt.Sprintf("pta-%s-base.qcow2", slug)
+	}
+	return fmt.Sprintf("pta-%s-%s-base.qcow2", slug, hash)
+}
+
+// VMInstanceName returns the domain name of a team VM
+func VMInstanceName(slug string, teamID, userID int) string {
+	return fmt.Sprintf("pta-%s_%d_%d", slug, teamID, userID)
+}
+
+// lockVMBase locks the base disk of a challenge and returns the unlock function
+func lockVMBase(slug string) func() {
+	mu, _ := vmBaseLocks.LoadOrStore(slug, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// EnsureVMBaseImage uploads the current challenge disk image to the libvirt pool if missing
+func EnsureVMBaseImage(slug string, spec models.VMSpec) (string, error) {
+	conn := config.Libvirt
+	if conn == nil {
+		return "", errLibvirtMissing
+	}
+
+	objectKey := path.Join(slug, spec.DiskImage)
+	etag, err := vmImages.ETag(objectKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get disk image %s: %w", objectKey, err)
+	}
+
+	// Concurrent first starts would otherwise upload the same volume twice
+	unlock := lockVMBase(slug)
+	defer unlock()
+
+	pool := libvirtPool()
+	volume := vmBaseVolumeName(slug, etag)
+	if conn.VolumeExists(pool, volume) {
+		return volume, nil
+	}
+
+	obj, err := vmImages.Open(objectKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get disk image %s: %w", objectKey, err)
+	}
+	defer obj.Close()
+
+	tmpFile, err := os.CreateTemp("", "pta-disk-*.qcow2")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, obj); err != nil {
+		tmpFile.Close()
+		return "", fmt.Errorf("failed to download disk image %s: %w", objectKey, err)
+	}
+	tmpFile.Close()
+
+	if err := conn.UploadVolume(pool, volume, tmpFile.Name()); err != nil {
+		return "", fmt.Errorf("failed to upload disk image: %w", err)
+	}
+	debug.Log("Uploaded base disk %s to pool %s", volume, pool)
+	return volume, nil
+}
+
+// vmPortRange returns the port range forwarded by the libvirt host from PTA_LIBVIRT_PORT_RANGE
+func vmPortRange() (int, int, error) {
+	value := os.Getenv("PTA_LIBVIRT_PORT_RANGE")
+	if value == "" {
+		value = defaultVMPortRange
+	}
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("invalid port range %q", value)
+	}
+	low, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q", value)
+	}
+	high, err := strconv.Atoi(strings.TrimSpace(bounds[1]))
+	if err != nil || low < 1 || high > 65535 || low > high {
+		return 0, 0, fmt.Errorf("invalid port range %q", value)
+	}
+	return low, high, nil
+}
+
+// pickFreePorts picks count random ports of the range that are not used
+func pickFreePorts(count, low, high int, used map[int]bool) ([]int, error) {
+	candidates := []int{}
+	for port := low; port <= high; port++ {
+		if !used[port] {
+			candidates = append(candidates, port)
+		}
+	}
+	if len(candidates) < count {
+		return nil, fmt.Errorf("only %d free ports left in %d-%d", len(candidates), low, high)
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	return candidates[:count], nil
+}
+
+// ReserveVMPorts picks ports for the hostfwd of a VM on the libvirt host. The backend cannot probe
+// that host, so ports are taken from PTA_LIBVIRT_PORT_RANGE minus the ones held by instances or
+// by VMs still booting. Release them with ReleaseVMPorts once the instance row exists.
+func ReserveVMPorts(count int) ([]int, error) {
+	low, high, err := vmPortRange()
+	if err != nil {
+		return nil, err
+	}
+
+	var held []pq.Int64Array
+	if err := config.DB.Model(&models.Instance{}).Where("status = ?", "running").Pluck("ports", &held).Error; err != nil {
+		return nil, err
+	}
+
+	reservedVMPortsMu.Lock()
+	defer reservedVMPortsMu.Unlock()
+
+	used := map[int]bool{}
+	for port := range reservedVMPorts {
+		used[port] = true
+	}
+	for _, ports := range held {
+		for _, port := range ports {
+			used[int(port)] = true
+		}
+	}
+
+	ports, err := pickFreePorts(count, low, high, used)
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range ports {
+		reservedVMPorts[port] = true
+	}
+	return ports, nil
+}
+
+// ReleaseVMPorts drops the reservation of ports returned by ReserveVMPorts
+func ReleaseVMPorts(ports []int) {
+	reservedVMPortsMu.Lock()
+	defer reservedVMPortsMu.Unlock()
+	for _, port := range ports {
+		delete(reservedVMPorts, port)
+	}
+}
+
+var domainTemplate = template.Must(template.New("domain").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<domain type='{{xml .Type}}' xmlns:qemu='http://libvirt.org/schemas/domain/qemu/1.0'>
+  <name>{{xml .Name}}</name>
+  <memory unit='MiB'>{{.Memory}}</memory>
+  <vcpu>{{.VCPUs}}</vcpu>
+  <os>
+    <type arch='x86_64'>hvm</type>
+    <boot dev='hd'/>{{if .Env}}
+    <smbios mode='sysinfo'/>{{end}}
+  </os>{{if .Env}}
+  <sysinfo type='smbios'>
+    <oemStrings>{{range .Env}}
+      <entry>{{xml .}}</entry>{{end}}
+    </oemStrings>
+  </sysinfo>{{end}}
+  <devices>
+    <disk type='file' device='disk'>
+      <driver name='qemu' type='qcow2'/>
+      <source file='{{xml .Disk}}'/>
+      <target dev='vda' bus='virtio'/>
+    </disk>
+    <serial type='pty'/>
+    <console type='pty'/>
+  </devices>
+  <qemu:commandline>
+    <qemu:arg value='-netdev'/>
+    <qemu:arg value='{{xml .Netdev}}'/>
+    <qemu:arg value='-device'/>
+    <qemu:arg value='virtio-net-pci,netdev=net0'/>
+  </qemu:commandline>
+</domain>
+`))
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// buildDomainXML renders a transient domain forwarding host ports to the guest with user networking.
+// The environment is exposed to the guest as SMBIOS OEM strings (KEY=value).
+func buildDomainXML(name, disk string, spec models.VMSpec, internalPorts, hostPorts []int, env map[string]string) (string, error) {
+	netdev := "user,id=net0"
+	for i, port := range internalPorts {
+		netdev += fmt.Sprintf(",hostfwd=tcp::%d-:%d", hostPorts[i], port)
+	}
+
+	entries := make([]string, 0, len(env))
+	for k, v := range env {
+		entries = append(entries, k+"="+v)
+	}
+	sort.Strings(entries)
+
+	domainType := os.Getenv("PTA_LIBVIRT_DOMAIN_TYPE")
+	if domainType == "" {
+		domainType = "kvm"
+	}
+
+	memory := spec.Memory
+	if memory <= 0 {
+		memory = defaultVMMemory
+	}
+	vcpus := spec.VCPUs
+	if vcpus <= 0 {
+		vcpus = defaultVMVCPUs
+	}
+
+	var buf bytes.Buffer
+	err := domainTemplate.Execute(&buf, map[string]interface{}{
+		"Type":   domainType,
+		"Name":   name,
+		"Memory": memory,
+		"VCPUs":  vcpus,
+		"Disk":   disk,
+		"Netdev": netdev,
+		"Env":    entries,
+	})
+	return buf.String(), err
+}
+
+// ErrVMStartInProgress is returned when the same team VM is already being started
+var ErrVMStartInProgress = errors.New("vm start already in progress")
+
+// vmStarting holds the names of the VMs being started
+var vmStarting sync.Map
+
+// StartVMInstance boots a team VM on a copy-on-write overlay of the challenge disk
+func StartVMInstance(slug string, spec models.VMSpec, teamID int, userID int, internalPorts []int, hostPorts []int, env map[string]string) (string, error) {
+	if len(internalPorts) != len(hostPorts) {
+		return "", fmt.Errorf("internal and host ports length mismatch")
+	}
+
+	conn := config.Libvirt
+	if conn == nil {
+		return "", errLibvirtMissing
+	}
+
+	// The stale disk cleanup below would destroy a VM of the same name still booting
+	name := VMInstanceName(slug, teamID, userID)
+	if _, busy := vmStarting.LoadOrStore(name, struct{}{}); busy {
+		return "", ErrVMStartInProgress
+	}
+	defer vmStarting.Delete(name)
+
+	baseVolume, err := EnsureVMBaseImage(slug, spec)
+	if err != nil {
+		return "", err
+	}
+
+	pool := libvirtPool()
+	overlay := name + ".qcow2"
+
+	// Leftovers of a previous run would prevent the overlay creation
+	if conn.VolumeExists(pool, overlay) {
+		conn.DestroyDomain(name)
+		if err := conn.DeleteVolume(pool, overlay); err != nil {
+			return "", fmt.Errorf("failed to remove stale disk %s: %w", overlay, err)
+		}
+	}
+
+	disk, err := conn.CreateOverlayVolume(pool, overlay, baseVolume)
+	if err != nil {
+		return "", fmt.Errorf("failed to create instance disk: %w", err)
+	}
+
+	domainXML, err := buildDomainXML(name, strings.TrimSpace(disk), spec, internalPorts, hostPorts, env)
+	if err != nil {
+		conn.DeleteVolume(pool, overlay)
+		return "", err
+	}
+
+	if err := conn.CreateDomain(domainXML); err != nil {
+		conn.DeleteVolume(pool, overlay)
+		return "", fmt.Errorf("failed to start VM: %w", err)
+	}
+
+	debug.Log("VM %s started", name)
+	return name, nil
+}
+
+// StopVMInstance destroys a team VM and deletes its disk
+func StopVMInstance(name string) error {
+	if err := EnsureLibvirtConnected(); err != nil {
+		return err
+	}
+	conn := config.Libvirt
+
+	if state, err := conn.DomainState(name); err == nil {
+		debug.Log("Destroying VM %s (state: %s)", name, state)
+		if err := conn.DestroyDomain(name); err != nil {
+			return fmt.Errorf("failed to destroy VM %s: %w", name, err)
+		}
+	}
+
+	pool := libvirtPool()
+	overlay := name + ".qcow2"
+	if conn.VolumeExists(pool, overlay) {
+		if err := conn.DeleteVolume(pool, overlay); err != nil {
+			return fmt.Errorf("failed to delete disk of VM %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// VMInstanceState returns the libvirt state of a team VM (running, shut off, ...)
+func VMInstanceState(name string) (string, error) {
+	if err := EnsureLibvirtConnected(); err != nil {
+		return "", err
+	}
+	return config.Libvirt.DomainState(name)
+}
@@ -0,0 +1,334 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// fakeLibvirt keeps volumes and domains in memory
+type fakeLibvirt struct {
+	mu      sync.Mutex
+	volumes map[string]string // name -> content or backing volume
+	domains map[string]string // name -> domain XML
+	uploads int
+	// createHook runs before a domain is defined, letting tests hold a start midway
+	createHook func()
+}
+
+func newFakeLibvirt() *fakeLibvirt {
+	return &fakeLibvirt{volumes: map[string]string{}, domains: map[string]string{}}
+}
+
+func (f *fakeLibvirt) Version() (string, error) {
+	return "fake", nil
+}
+
+func (f *fakeLibvirt) VolumeExists(pool, name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.volumes[name]
+	return ok
+}
+
+func (f *fakeLibvirt) UploadVolume(pool, name, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	if _, ok := f.volumes[name]; ok {
+		f.mu.Unlock()
+		return fmt.Errorf("volume %s already exists", name)
+	}
+	f.volumes[name] = string(data)
+	f.uploads++
+	f.mu.Unlock()
+	// Leaves room for a concurrent start to see the volume half uploaded
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func (f *fakeLibvirt) CreateOverlayVolume(pool, name, backing string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.volumes[backing]; !ok {
+		return "", fmt.Errorf("backing volume %s does not exist", backing)
+	}
+	if _, ok := f.volumes[name]; ok {
+		return "", fmt.Errorf("volume %s already exists", name)
+	}
+	f.volumes[name] = "backing:" + backing
+	return "/pool/" + name + "\n", nil
+}
+
+func (f *fakeLibvirt) DeleteVolume(pool, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.volumes[name]; !ok {
+		return fmt.Errorf("volume %s does not exist", name)
+	}
+	delete(f.volumes, name)
+	return nil
+}
+
+func (f *fakeLibvirt) CreateDomain(domainXML string) error {
+	if f.createHook != nil {
+		f.createHook()
+	}
+	start := strings.Index(domainXML, "<name>") + len("<name>")
+	end := strings.Index(domainXML, "</name>")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.domains[domainXML[start:end]] = domainXML
+	return nil
+}
+
+func (f *fakeLibvirt) DestroyDomain(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.domains[name]; !ok {
+		return config.ErrDomainNotFound
+	}
+	// Transient domains vanish once destroyed
+	delete(f.domains, name)
+	return nil
+}
+
+func (f *fakeLibvirt) DomainState(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.domains[name]; !ok {
+		return "", config.ErrDomainNotFound
+	}
+	return "running", nil
+}
+
+// fakeImageStore serves disk images from memory
+type fakeImageStore struct {
+	images map[string]string
+	etags  map[string]string
+}
+
+func (s *fakeImageStore) ETag(key string) (string, error) {
+	etag, ok := s.etags[key]
+	if !ok {
+		return "", fmt.Errorf("object %s not found", key)
+	}
+	return etag, nil
+}
+
+func (s *fakeImageStore) Open(key string) (io.ReadCloser, error) {
+	data, ok := s.images[key]
+	if !ok {
+		return nil, fmt.Errorf("object %s not found", key)
+	}
+	return io.NopCloser(strings.NewReader(data)), nil
+}
+
+func setupFakeLibvirt(t *testing.T) (*fakeLibvirt, *fakeImageStore) {
+	t.Helper()
+	conn := newFakeLibvirt()
+	store := &fakeImageStore{
+		images: map[string]string{"box/disk.qcow2": "disk v1"},
+		etags:  map[string]string{"box/disk.qcow2": `"0123456789abcdef0123"`},
+	}
+
+	previousConn, previousStore := config.Libvirt, vmImages
+	config.SetLibvirtConnection(conn)
+	vmImages = store
+	t.Cleanup(func() {
+		config.SetLibvirtConnection(previousConn)
+		vmImages = previousStore
+	})
+	return conn, store
+}
+
+func TestStartVMInstanceDefinesDomain(t *testing.T) {
+	conn, _ := setupFakeLibvirt(t)
+	spec := models.VMSpec{DiskImage: "disk.qcow2", Memory: 1024}
+
+	name, err := StartVMInstance("box", spec, 3, 7, []int{22, 80}, []int{40022, 40080}, map[string]string{"FLAG": "PTA{x}"})
+	if err != nil {
+		t.Fatalf("StartVMInstance: %v", err)
+	}
+	if name != "pta-box_3_7" {
+		t.Errorf("name = %q, want pta-box_3_7", name)
+	}
+
+	base := "pta-box-0123456789ab-base.qcow2"
+	if conn.volumes[base] != "disk v1" {
+		t.Errorf("base volume %s = %q, want the uploaded image", base, conn.volumes[base])
+	}
+	if conn.volumes[name+".qcow2"] != "backing:"+base {
+		t.Errorf("overlay = %q, want backed by %s", conn.volumes[name+".qcow2"], base)
+	}
+
+	domain := conn.domains[name]
+	for _, want := range []string{
+		"<memory unit='MiB'>1024</memory>",
+		"<source file='/pool/pta-box_3_7.qcow2'/>",
+		"hostfwd=tcp::40022-:22,hostfwd=tcp::40080-:80",
+		"<entry>FLAG=PTA{x}</entry>",
+	} {
+		if !strings.Contains(domain, want) {
+			t.Errorf("domain XML does not contain %q:\n%s", want, domain)
+		}
+	}
+
+	state, err := VMInstanceState(name)
+	if err != nil || state != "running" {
+		t.Errorf("VMInstanceState = %q, %v, want running", state, err)
+	}
+}
+
+func TestStartVMInstanceReplacesStaleDisk(t *testing.T) {
+	conn, _ := setupFakeLibvirt(t)
+	spec := models.VMSpec{DiskImage: "disk.qcow2"}
+	conn.volumes["pta-box_3_7.qcow2"] = "leftover"
+
+	if _, err := StartVMInstance("box", spec, 3, 7, []int{22}, []int{40022}, nil); err != nil {
+		t.Fatalf("StartVMInstance: %v", err)
+	}
+	if conn.volumes["pta-box_3_7.qcow2"] == "leftover" {
+		t.Error("stale disk was reused")
+	}
+}
+
+func TestStartVMInstanceRefusesConcurrentStart(t *testing.T) {
+	conn, _ := setupFakeLibvirt(t)
+	spec := models.VMSpec{DiskImage: "disk.qcow2"}
+	booting := make(chan struct{})
+	release := make(chan struct{})
+	conn.createHook = func() {
+		close(booting)
+		<-release
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := StartVMInstance("box", spec, 3, 7, []int{22}, []int{40022}, nil)
+		done <- err
+	}()
+	<-booting
+
+	// The stale disk cleanup of a second start would destroy the VM being booted
+	if _, err := StartVMInstance("box", spec, 3, 7, []int{22}, []int{40023}, nil); !errors.Is(err, ErrVMStartInProgress) {
+		t.Errorf("second StartVMInstance error = %v, want ErrVMStartInProgress", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first StartVMInstance: %v", err)
+	}
+	if conn.volumes["pta-box_3_7.qcow2"] != "backing:pta-box-0123456789ab-base.qcow2" {
+		t.Errorf("overlay = %q, want the disk of the first start", conn.volumes["pta-box_3_7.qcow2"])
+	}
+	if !strings.Contains(conn.domains["pta-box_3_7"], "hostfwd=tcp::40022-:22") {
+		t.Error("the first VM was replaced or destroyed")
+	}
+
+	// Once the first start returned, the name is free again
+	conn.createHook = nil
+	if _, err := StartVMInstance("box", spec, 3, 7, []int{22}, []int{40022}, nil); err != nil {
+		t.Errorf("restart after the first start: %v", err)
+	}
+}
+
+func TestStopVMInstanceDestroysDomainAndDisk(t *testing.T) {
+	conn, _ := setupFakeLibvirt(t)
+	spec := models.VMSpec{DiskImage: "disk.qcow2"}
+
+	name, err := StartVMInstance("box", spec, 3, 7, []int{22}, []int{40022}, nil)
+	if err != nil {
+		t.Fatalf("StartVMInstance: %v", err)
+	}
+	if err := StopVMInstance(name); err != nil {
+		t.Fatalf("StopVMInstance: %v", err)
+	}
+
+	if _, ok := conn.domains[name]; ok {
+		t.Error("domain still defined")
+	}
+	if _, ok := conn.volumes[name+".qcow2"]; ok {
+		t.Error("overlay disk still present")
+	}
+	if _, ok := conn.volumes["pta-box-0123456789ab-base.qcow2"]; !ok {
+		t.Error("base disk must be kept for the next instances")
+	}
+	if _, err := VMInstanceState(name); err != config.ErrDomainNotFound {
+		t.Errorf("VMInstanceState error = %v, want ErrDomainNotFound", err)
+	}
+
+	// Stopping a VM that is already gone is not an error
+	if err := StopVMInstance(name); err != nil {
+		t.Errorf("second StopVMInstance: %v", err)
+	}
+}
+
+func TestEnsureVMBaseImageUploadsOncePerVersion(t *testing.T) {
+	conn, store := setupFakeLibvirt(t)
+	spec := models.VMSpec{DiskImage: "disk.qcow2"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := EnsureVMBaseImage("box", spec); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("EnsureVMBaseImage: %v", err)
+	}
+	if conn.uploads != 1 {
+		t.Errorf("%d uploads for concurrent starts, want 1", conn.uploads)
+	}
+
+	// A new image gets a new base volume instead of the stale one
+	store.images["box/disk.qcow2"] = "disk v2"
+	store.etags["box/disk.qcow2"] = `"fedcba9876543210-2"`
+	volume, err := EnsureVMBaseImage("box", spec)
+	if err != nil {
+		t.Fatalf("EnsureVMBaseImage: %v", err)
+	}
+	if volume != "pta-box-fedcba987654-base.qcow2" || conn.volumes[volume] != "disk v2" {
+		t.Errorf("volume %s = %q, want the new image", volume, conn.volumes[volume])
+	}
+	if conn.uploads != 2 {
+		t.Errorf("%d uploads, want 2", conn.uploads)
+	}
+}
+
+func TestPickFreePorts(t *testing.T) {
+	used := map[int]bool{40000: true, 40002: true}
+	ports, err := pickFreePorts(2, 40000, 40003, used)
+	if err != nil {
+		t.Fatalf("pickFreePorts: %v", err)
+	}
+	if len(ports) != 2 || ports[0] == ports[1] {
+		t.Fatalf("ports = %v, want two distinct ports", ports)
+	}
+	for _, port := range ports {
+		if port != 40001 && port != 40003 {
+			t.Errorf("port %d is used or out of range", port)
+		}
+	}
+
+	if _, err := pickFreePorts(3, 40000, 40003, used); err == nil {
+		t.Error("expected an error when the range is exhausted")
+	}
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// Purposes of the tokens sent by email, a token is only accepted for its own purpose
+const (
+	MailTokenVerifyEmail   = "verify_email"
+	MailTokenResetPassword = "reset_password"
+)
+
+// Lifetime of the tokens sent by email
+const (
+	VerifyEmailTokenTTL   = 24 * time.Hour
+	ResetPasswordTokenTTL = time.Hour
+)
+
+// ErrInvalidMailToken is returned for expired, tampered or already used tokens
+var ErrInvalidMailToken = errors.New("invalid_or_expired_token")
+
+type mailTokenClaims struct {
+	Purpose     string `json:"purpose"`
+	Fingerprint string `json:"fp"`
+	jwt.RegisteredClaims
+}
+
+// mailTokenKey derives the signing key of mail tokens, so they can never be used as access tokens
+func mailTokenKey() []byte {
+	mac := hmac.New(sha256.New, AccessSecret)
+	mac.Write([]byte("mail-token"))
+	return mac.Sum(nil)
+}
+
+// mailTokenFingerprint binds a token to the current state of the account. Verifying the email or changing
+// the password changes the fingerprint, which makes the token single use without storing it.
+func mailTokenFingerprint(user *models.User, purpose string) string {
+	mac := hmac.New(sha256.New, mailTokenKey())
+	mac.Write([]byte(purpose + "\x00" + user.Email + "\x00" + user.Password + "\x00" + strconv.FormatBool(user.EmailVerified)))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// GenerateMailToken signs a token for a user, valid for ttl
+func GenerateMailToken(user *models.User, purpose string, ttl time.Duration) (string, error) {
+	claims := mailTokenClaims{
+		Purpose:     purpose,
+		Fingerprint: mailTokenFingerprint(user, purpose),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(mailTokenKey())
+}
+
+// ParseMailToken checks a token for the given purpose and returns its user
+func ParseMailToken(tokenStr string, purpose string) (*models.User, error) {
+	claims := &mailTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		return mailTokenKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid || claims.Purpose != purpose {
+		return nil, ErrInvalidMailToken
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, claims.Subject).Error; err != nil {
+		return nil, ErrInvalidMailToken
+	}
+	if !hmac.Equal([]byte(claims.Fingerprint), []byte(mailTokenFingerprint(&user, purpose))) {
+		return nil, ErrInvalidMailToken
+	}
+	return &user, nil
+}
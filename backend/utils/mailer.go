@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+)
+
+//go:embed templates/mail/*
+var mailTemplates embed.FS
+
+// SMTP connection security, selected with the SMTP_TLS config
+const (
+	SMTPStartTLS = "starttls" // Plain connection upgraded with STARTTLS, usually port 587
+	SMTPTLS      = "tls"      // Implicit TLS, usually port 465
+	SMTPNone     = "none"     // No encryption, for local mail catchers only
+)
+
+// MailMessage is an email ready to be sent
+type MailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// MailEnabled tells if an SMTP server is configured. Mail links need PUBLIC_URL: request headers cannot be
+// trusted to build them, anyone could get a reset link pointing to their own host.
+func MailEnabled() bool {
+	return config.GetConfigValue("SMTP_HOST", "") != "" && PublicBaseURL() != ""
+}
+
+// PublicBaseURL returns PUBLIC_URL without its trailing slash, the only base used for links sent by mail
+func PublicBaseURL() string {
+	return strings.TrimSuffix(config.GetConfigValue("PUBLIC_URL", ""), "/")
+}
+
+// CheckMailConfig warns when SMTP is configured but emails stay disabled for lack of PUBLIC_URL
+func CheckMailConfig() {
+	if config.GetConfigValue("SMTP_HOST", "") != "" && PublicBaseURL() == "" {
+		log.Println("WARNING: SMTP_HOST is set but PUBLIC_URL is empty, emails are disabled until PTA_PUBLIC_URL is set")
+	}
+}
+
+// readMailTemplate returns a template from PTA_MAIL_TEMPLATES_DIR when it overrides it, else the embedded default
+func readMailTemplate(file string) (string, error) {
+	if dir := os.Getenv("PTA_MAIL_TEMPLATES_DIR"); dir != "" {
+		if content, err := os.ReadFile(filepath.Join(dir, file)); err == nil {
+			return string(content), nil
+		}
+	}
+	content, err := mailTemplates.ReadFile("templates/mail/" + file)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// RenderMail builds a message from the <name>.txt template, which defines the subject, and the optional <name>.html
+func RenderMail(to string, name string, data map[string]interface{}) (*MailMessage, error) {
+	if _, ok := data["SiteName"]; !ok {
+		data["SiteName"] = config.GetConfigValue("SITE_NAME", "pwnthemall")
+	}
+
+	textSource, err := readMailTemplate(name + ".txt")
+	if err != nil {
+		return nil, fmt.Errorf("missing mail template %s: %w", name, err)
+	}
+	textTemplate, err := template.New(name).Parse(textSource)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail template %s: %w", name, err)
+	}
+
+	var subject, text bytes.Buffer
+	if err := textTemplate.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("mail template %s has no subject: %w", name, err)
+	}
+	if err := textTemplate.Execute(&text, data); err != nil {
+		return nil, err
+	}
+
+	message := &MailMessage{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+	}
+
+	if htmlSource, err := readMailTemplate(name + ".html"); err == nil {
+		htmlTemplate, err := htmltemplate.New(name).Parse(htmlSource)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mail template %s.html: %w", name, err)
+		}
+		var html bytes.Buffer
+		if err := htmlTemplate.Execute(&html, data); err != nil {
+			return nil, err
+		}
+		message.HTML = html.String()
+	}
+	return message, nil
+}
+
+// SendTemplateMail renders a mail template and sends it
+func SendTemplateMail(to string, name string, data map[string]interface{}) error {
+	message, err := RenderMail(to, name, data)
+	if err != nil {
+		return err
+	}
+	return SendMail(message)
+}
+
+// SendMail sends a message through the configured SMTP server
+func SendMail(message *MailMessage) error {
+	host := config.GetConfigValue("SMTP_HOST", "")
+	if host == "" {
+		return fmt.Errorf("SMTP_HOST is not set")
+	}
+	port := config.GetConfigInt("SMTP_PORT", 587)
+	security := strings.ToLower(config.GetConfigValue("SMTP_TLS", SMTPStartTLS))
+	from := config.GetConfigValue("SMTP_FROM", "")
+	if from == "" {
+		return fmt.Errorf("SMTP_FROM is not set")
+	}
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+
+	body, err := buildMIMEMessage(fromAddress.String(), message)
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if security == SMTPTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if security == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS, set SMTP_TLS to tls or none", address)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if username := config.GetConfigValue("SMTP_USERNAME", ""); username != "" {
+		auth := smtp.PlainAuth("", username, os.Getenv("PTA_SMTP_PASSWORD"), host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(fromAddress.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(body); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMIMEMessage encodes a message with a plain text part and, when present, an HTML alternative
+func buildMIMEMessage(from string, message *MailMessage) ([]byte, error) {
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at != -1 {
+		domain = strings.Trim(from[at+1:], ">")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(random), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if message.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, message.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary := "pta-" + hex.EncodeToString(random)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain", message.Text},
+		{"text/html", message.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, part.content); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable encodes a mail part
+func writeQuotedPrintable(buf *bytes.Buffer, content string) error {
+	writer := quotedprintable.NewWriter(buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		return err
+	}
+	return writer.Close()
+}
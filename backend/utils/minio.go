@@ -123,6 +123,50 @@ func parseGeoChallenge(content []byte, objectKey string) (meta.BaseChallengeMeta
 	return composeMeta.Base, nil, &geoMeta, nil
 }
 
+// parseVMChallenge parses VM challenge metadata
+func parseVMChallenge(content []byte) (meta.BaseChallengeMetadata, []int, error) {
+	var vmMeta meta.VMChallengeMetadata
+	if err := yaml.Unmarshal(content, &vmMeta); err != nil {
+		return meta.BaseChallengeMetadata{}, nil, err
+	}
+	if vmMeta.DiskImage == "" {
+		return meta.BaseChallengeMetadata{}, nil, fmt.Errorf("disk_image is required for vm challenges")
+	}
+	if vmMeta.Memory < 0 || vmMeta.VCPUs < 0 {
+		return meta.BaseChallengeMetadata{}, nil, fmt.Errorf("memory and vcpus must be positive")
+	}
+	return vmMeta.Base, vmMeta.Ports, nil
+}
+
+// saveVMSpecForChallenge saves VMSpec data for vm challenges
+func saveVMSpecForChallenge(slug string, content []byte) {
+	var vmMeta meta.VMChallengeMetadata
+	if err := yaml.Unmarshal(content, &vmMeta); err != nil {
+		return
+	}
+
+	var challenge models.Challenge
+	if err := config.DB.Where(querySlug, slug).First(&challenge).Error; err != nil {
+		return
+	}
+
+	var existing models.VMSpec
+	if err := config.DB.Where(queryChallengeIDMinio, challenge.ID).First(&existing).Error; err == nil {
+		existing.DiskImage = vmMeta.DiskImage
+		existing.Memory = vmMeta.Memory
+		existing.VCPUs = vmMeta.VCPUs
+		_ = config.DB.Save(&existing).Error
+	} else {
+		spec := models.VMSpec{
+			ChallengeID: challenge.ID,
+			DiskImage:   vmMeta.DiskImage,
+			Memory:      vmMeta.Memory,
+			VCPUs:       vmMeta.VCPUs,
+		}
+		_ = config.DB.Create(&spec).Error
+	}
+}
+
 // parseChallengeByType parses challenge metadata based on type
 func parseChallengeByType(base meta.BaseChallengeMetadata, content []byte, objectKey string) (meta.BaseChallengeMetadata, []int, *meta.GeoChallengeMetadata, error) {
 	switch base.Type {
@@ -134,6 +178,9 @@ func parseChallengeByType(base meta.BaseChallengeMetadata, content []byte, objec
 		return baseData, ports, nil, err
 	case "geo":
 		return parseGeoChallenge(content, objectKey)
+	case "vm":
+		baseData, ports, err := parseVMChallenge(content)
+		return baseData, ports, nil, err
 	default:
 		return base, nil, nil, nil
 	}
@@ -150,11 +197,14 @@ func SyncAllChallengesFromMinIO(ctx context.Context, updatesHub *Hub) error {
 	
 	syncCount := 0
 	errorCount := 0
+	listingFailed := false
+	found := make(map[string]bool)
 	
 	for object := range objectCh {
 		if object.Err != nil {
 			log.Printf("Error listing object: %v", object.Err)
 			errorCount++
+			listingFailed = true
 			continue
 		}
 		
@@ -162,6 +212,7 @@ func SyncAllChallengesFromMinIO(ctx context.Context, updatesHub *Hub) error {
 		if filepath.Base(object.Key) != "chall.yml" {
 			continue
 		}
+		found[strings.Split(object.Key, "/")[0]] = true
 		
 		// Sync this challenge
 		key := bucketNameChallenges + "/" + object.Key
@@ -173,6 +224,11 @@ func SyncAllChallengesFromMinIO(ctx context.Context, updatesHub *Hub) error {
 		}
 	}
 	
+	// Challenges removed from the bucket while the backend was down
+	if !listingFailed {
+		archiveMissingChallenges(found, updatesHub)
+	}
+	
 	log.Printf("Initial sync completed: %d challenges synced, %d errors", syncCount, errorCount)
 	return nil
 }
@@ -184,13 +240,16 @@ func SyncChallengesFromMinIO(ctx context.Context, key string, updatesHub *Hub) e
 	// Try to retrieve and validate object
 	obj, err := retrieveAndValidateObject(ctx, bucketNameChallenges, objectKey)
 	if err != nil {
-		log.Printf("Object not found or error retrieving object %s: %v", objectKey, err)
+		if minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			log.Printf("Error retrieving object %s: %v", objectKey, err)
+			return err
+		}
+		log.Printf("Object %s not found: %v", objectKey, err)
 		slug := strings.Split(objectKey, "/")[0]
-		if err := deleteChallengeFromDB(slug); err != nil {
-			log.Printf("Error deleting challenge from DB: %v", err)
+		if err := archiveChallenge(slug, updatesHub); err != nil {
+			log.Printf("Error archiving challenge: %v", err)
 			return err
 		}
-		log.Printf("Deleted challenge with slug %s from DB", slug)
 		return nil
 	}
 	defer obj.Close()
@@ -228,18 +287,58 @@ func SyncChallengesFromMinIO(ctx context.Context, key string, updatesHub *Hub) e
 		saveGeoSpecForChallenge(slug, *geoMeta)
 	}
 
+	// Save VMSpec AFTER challenge is created
+	if metaData.Type == "vm" {
+		saveVMSpecForChallenge(slug, buf.Bytes())
+	}
+
 	log.Printf("Synced %s to DB", objectKey)
 	return nil
 }
 
-func deleteChallengeFromDB(slug string) error {
-	var challenge models.Challenge
-	if err := config.DB.Where("slug = ?", slug).Delete(&challenge).Error; err != nil {
-		return err
+// archiveChallenge hides a challenge whose chall.yml is gone and flags it for admin review.
+// Solves and first bloods are kept, an admin has to purge the challenge to delete them.
+func archiveChallenge(slug string, updatesHub *Hub) error {
+	now := time.Now()
+	result := config.DB.Model(&models.Challenge{}).
+		Where("slug = ? AND archived_at IS NULL", slug).
+		Updates(map[string]interface{}{"hidden": true, "archived_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	log.Printf("Archived challenge with slug %s, its chall.yml is missing", slug)
+
+	if updatesHub != nil {
+		if payload, err := json.Marshal(map[string]interface{}{
+			"event":  "challenge-category",
+			"action": "minio_sync",
+		}); err == nil {
+			updatesHub.SendToAll(payload)
+		}
 	}
 	return nil
 }
 
+// archiveMissingChallenges archives the challenges whose slug was not found in the bucket
+func archiveMissingChallenges(found map[string]bool, updatesHub *Hub) {
+	var slugs []string
+	if err := config.DB.Model(&models.Challenge{}).Where("archived_at IS NULL").Pluck("slug", &slugs).Error; err != nil {
+		log.Printf("Error listing challenges to archive: %v", err)
+		return
+	}
+	for _, slug := range slugs {
+		if found[slug] {
+			continue
+		}
+		if err := archiveChallenge(slug, updatesHub); err != nil {
+			log.Printf("Error archiving challenge %s: %v", slug, err)
+		}
+	}
+}
+
 // createOrGetEntity creates entity if not exists, otherwise gets existing
 func createOrGetEntity(dest interface{}, where map[string]interface{}) error {
 	result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(dest)
@@ -272,9 +371,18 @@ func createChallengeRelatedEntities(metaData meta.BaseChallengeMetadata) (uint,
 		return 0, 0, nil, nil, err
 	}
 
+	// Inline formulas are created (or updated) under their name
+	if metaData.DecayFormula.IsInline() {
+		cDecayFormula, err := syncInlineDecayFormula(metaData.DecayFormula)
+		if err != nil {
+			return 0, 0, nil, nil, err
+		}
+		return cCategory.ID, cDifficulty.ID, &cType, cDecayFormula, nil
+	}
+
 	// Get decay formula - default to "No Decay" if not specified or not found
 	var cDecayFormula models.DecayFormula
-	decayFormulaName := metaData.DecayFormula
+	decayFormulaName := metaData.DecayFormula.Name
 	if decayFormulaName == "" || decayFormulaName == "None" {
 		decayFormulaName = "No Decay"
 	}
@@ -288,6 +396,33 @@ func createChallengeRelatedEntities(metaData meta.BaseChallengeMetadata) (uint,
 	return cCategory.ID, cDifficulty.ID, &cType, &cDecayFormula, nil
 }
 
+// syncInlineDecayFormula creates or updates the decay formula defined in a chall.yml.
+// Unnamed formulas get a name built from their parameters so identical ones are shared.
+func syncInlineDecayFormula(decayMeta meta.DecayFormula) (*models.DecayFormula, error) {
+	name := decayMeta.Name
+	if name == "" {
+		name = fmt.Sprintf("%s (step %d, min %d)", decayMeta.Type, decayMeta.Step, decayMeta.MinPoints)
+		if decayMeta.Type == DecayTypeTime {
+			name = fmt.Sprintf("%s (step %d, min %d, every %dm)", decayMeta.Type, decayMeta.Step, decayMeta.MinPoints, decayMeta.Interval)
+		}
+	}
+
+	var formula models.DecayFormula
+	config.DB.Where("name = ?", name).Limit(1).Find(&formula)
+	formula.Name = name
+	formula.Type = decayMeta.Type
+	formula.Step = decayMeta.Step
+	formula.MinPoints = decayMeta.MinPoints
+	formula.Interval = decayMeta.Interval
+	if err := ValidateDecayFormula(&formula); err != nil {
+		return nil, fmt.Errorf("invalid decay formula %s: %w", name, err)
+	}
+	if err := config.DB.Save(&formula).Error; err != nil {
+		return nil, err
+	}
+	return &formula, nil
+}
+
 // populateBasicChallengeFields sets basic challenge fields from metadata
 func populateBasicChallengeFields(challenge *models.Challenge, metaData meta.BaseChallengeMetadata, slug string, categoryID uint, difficultyID uint, cType *models.ChallengeType, decayFormula *models.DecayFormula, isNewChallenge bool) {
 	challenge.Slug = slug
@@ -303,17 +438,51 @@ func populateBasicChallengeFields(challenge *models.Challenge, metaData meta.Bas
 	challenge.MaxAttempts = metaData.Attempts
 	challenge.DependsOn = metaData.DependsOn
 	challenge.Emoji = metaData.Emoji
-	
+	challenge.DynamicFlag = metaData.DynamicFlag
+	challenge.WorkerLabels = metaData.WorkerLabels
+
 	// Only set decay formula if:
 	// 1. It's a new challenge, OR
 	// 2. The YAML file explicitly specifies a decay formula (not empty/None)
-	if isNewChallenge || (metaData.DecayFormula != "" && metaData.DecayFormula != "None") {
+	if isNewChallenge || metaData.DecayFormula.IsInline() || (metaData.DecayFormula.Name != "" && metaData.DecayFormula.Name != "None") {
 		challenge.DecayFormula = decayFormula
 		challenge.DecayFormulaID = decayFormula.ID
 	}
 	// If it's an existing challenge and YAML doesn't specify decay, preserve existing decay formula
 }
 
+// setChallengeSchedule sets the release and hide times, hiding the challenge outside of them
+func setChallengeSchedule(challenge *models.Challenge, releaseAt string, hideAt string) error {
+	challenge.ReleaseAt = nil
+	challenge.HideAt = nil
+	if releaseAt != "" {
+		t, err := time.Parse(time.RFC3339, releaseAt)
+		if err != nil {
+			return fmt.Errorf("invalid release_at %q: %w", releaseAt, err)
+		}
+		challenge.ReleaseAt = &t
+	}
+	if hideAt != "" {
+		t, err := time.Parse(time.RFC3339, hideAt)
+		if err != nil {
+			return fmt.Errorf("invalid hide_at %q: %w", hideAt, err)
+		}
+		challenge.HideAt = &t
+	}
+	if challenge.ReleaseAt != nil && challenge.HideAt != nil && !challenge.HideAt.After(*challenge.ReleaseAt) {
+		return fmt.Errorf("hide_at must be after release_at")
+	}
+
+	now := time.Now()
+	if challenge.ReleaseAt != nil && challenge.ReleaseAt.After(now) {
+		challenge.Hidden = true
+	}
+	if challenge.HideAt != nil && !challenge.HideAt.After(now) {
+		challenge.Hidden = true
+	}
+	return nil
+}
+
 // setChallengePorts converts and sets port array
 func setChallengePorts(challenge *models.Challenge, ports []int) {
 	ports64 := make(pq.Int64Array, len(ports))
@@ -364,17 +533,29 @@ func setFirstBloodConfig(challenge *models.Challenge, firstBlood *meta.FirstBloo
 }
 
 // syncFlags removes old flags and creates new ones
-func syncFlags(challengeID uint, flags []string) error {
+func syncFlags(challengeID uint, flags []meta.FlagMetadata) error {
+	newFlags := make([]models.Flag, 0, len(flags))
+	for _, flagMeta := range flags {
+		flagType, err := NormalizeFlagType(flagMeta.Type)
+		if err != nil {
+			return err
+		}
+		value, err := StoredFlagValue(flagMeta.Value, flagType)
+		if err != nil {
+			return err
+		}
+		newFlags = append(newFlags, models.Flag{
+			Value:       value,
+			Type:        flagType,
+			ChallengeID: challengeID,
+		})
+	}
+
 	if err := config.DB.Where(queryChallengeIDMinio, challengeID).Delete(&models.Flag{}).Error; err != nil {
 		return err
 	}
 
-	for _, flagValue := range flags {
-		hashed := HashFlag(flagValue)
-		newFlag := models.Flag{
-			Value:       hashed,
-			ChallengeID: challengeID,
-		}
+	for _, newFlag := range newFlags {
 		if err := config.DB.Create(&newFlag).Error; err != nil {
 			return err
 		}
@@ -470,6 +651,15 @@ func setChallengeFiles(challenge *models.Challenge, files []string, slug string)
 }
 
 func updateOrCreateChallengeInDB(metaData meta.BaseChallengeMetadata, slug string, ports []int, updatesHub *Hub) error {
+	// Reject invalid prerequisites before touching the database
+	prerequisites, err := BuildPrerequisites(metaData.Prerequisites)
+	if err != nil {
+		return fmt.Errorf("invalid prerequisites: %w", err)
+	}
+	if err := ValidatePrerequisiteGraph(slug, prerequisites, metaData.DependsOn); err != nil {
+		return err
+	}
+
 	// Create or get related entities
 	categoryID, difficultyID, cType, decayFormula, err := createChallengeRelatedEntities(metaData)
 	if err != nil {
@@ -493,6 +683,14 @@ func updateOrCreateChallengeInDB(metaData meta.BaseChallengeMetadata, slug strin
 	setConnectionInfo(&challenge, metaData.ConnectionInfo)
 	challenge.EnableFirstBlood = metaData.EnableFirstBlood
 	setFirstBloodConfig(&challenge, metaData.FirstBlood)
+	challenge.Prerequisites = prerequisites
+	if challenge.ArchivedAt != nil {
+		log.Printf("Restoring archived challenge %s", slug)
+		challenge.ArchivedAt = nil
+	}
+	if err := setChallengeSchedule(&challenge, metaData.ReleaseAt, metaData.HideAt); err != nil {
+		return err
+	}
 	
 	// Set challenge files
 	if err := setChallengeFiles(&challenge, metaData.Files, slug); err != nil {
@@ -537,6 +735,11 @@ func updateOrCreateChallengeInDB(metaData meta.BaseChallengeMetadata, slug strin
 		return err
 	}
 
+	// Points or decay may have changed for the teams that solved it
+	if err := RefreshChallengeScores(challenge.ID); err != nil {
+		log.Printf("Warning: failed to refresh scoreboard for %s: %v", slug, err)
+	}
+
 	return nil
 }
 
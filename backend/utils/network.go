@@ -1,11 +1,7 @@
 package utils
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"net"
-	"net/http"
 	"os"
 	"strings"
 
@@ -17,31 +13,12 @@ import (
 	"github.com/vishvananda/netlink"
 )
 
+// GetTeamSubnet returns the subnet and gateway leased to a team
 func GetTeamSubnet(teamID int) (string, string, error) {
-	baseCIDR := os.Getenv("PTA_DOCKER_CHALL_BASE_CIDR")
-	_, ipnet, err := net.ParseCIDR(baseCIDR)
-	if err != nil {
-		return "", "", fmt.Errorf("invalid baseCIDR: %w", err)
-	}
-
-	baseIP := ipnet.IP.To4()
-	if baseIP == nil {
-		return "", "", fmt.Errorf("only IPv4 supported")
-	}
-
-	if ipnet.Mask[2] != 0 || ipnet.Mask[3] != 0 { // needs to be /16
-		return "", "", fmt.Errorf("baseCIDR must be /16 subnet")
+	if teamID < 1 {
+		return "", "", fmt.Errorf("invalid teamID %d", teamID)
 	}
-
-	if teamID < 1 || teamID > 254 {
-		return "", "", fmt.Errorf("teamID must be in 1..254")
-	}
-
-	subnetIP := net.IPv4(baseIP[0], baseIP[1], byte(teamID), 0)
-	gatewayIP := net.IPv4(baseIP[0], baseIP[1], byte(teamID), 1)
-	subnetStr := fmt.Sprintf("%s/24", subnetIP.String())
-	gatewayStr := gatewayIP.String()
-	return subnetStr, gatewayStr, nil
+	return LeaseTeamSubnet(uint(teamID))
 }
 
 func GetTeamIPs(teamID uint) ([]string, error) {
@@ -57,9 +34,10 @@ func GetTeamIPs(teamID uint) ([]string, error) {
 	return ips, nil
 }
 
+// GetTeamMappedPorts returns the host ports of the running instances of a team
 func GetTeamMappedPorts(teamID uint) ([]int, error) {
 	var insts []models.Instance
-	err := config.DB.Where("team_id = ?", teamID).Find(&insts).Error
+	err := config.DB.Where("team_id = ? AND status = ?", teamID, "running").Find(&insts).Error
 	if err != nil {
 		return nil, err
 	}
@@ -105,34 +83,85 @@ func RefreshTeamNetworkFirewall(teamID uint, teamSubnet string, allowedIPs []str
 	return nil
 }
 
+// firewallIsolationEnabled tells if team instances are protected by the firewall agent
+func firewallIsolationEnabled() bool {
+	return os.Getenv("PTA_DOCKER_INSTANCE_ISOLATION") == "true"
+}
+
+// PushFirewallToAgent restricts the ports of every team instance to the team members
 func PushFirewallToAgent(teamID uint, ports []int, allowedIPs []string) error {
-	if os.Getenv("PTA_DOCKER_INSTANCE_ISOLATION") == "true" {
+	if !firewallIsolationEnabled() {
+		return nil
+	}
 
-		agentURL, err := getDefaultGateway()
-		if err != nil {
-			debug.Log("getDefaultGateway error: %v", err)
-			return fmt.Errorf("firewall agent push failed")
-		}
-		body := shared.FirewallRequest{
-			TeamID:     teamID,
-			Ports:      ports,
-			AllowedIPs: allowedIPs,
-		}
+	client, err := NewAgentClient()
+	if err != nil {
+		debug.Log("NewAgentClient error: %v", err)
+		return fmt.Errorf("firewall agent push failed")
+	}
 
-		data, _ := json.Marshal(body)
-		debug.Log("PushFirewallToAgent: agentURL %s", agentURL)
-		resp, err := http.Post("http://"+agentURL+":8383/team/firewall", "application/json", bytes.NewReader(data))
-		if err != nil {
-			return fmt.Errorf("firewall agent push failed: %w", err)
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode != 200 {
-			return fmt.Errorf("firewall agent returned %d", resp.StatusCode)
+	// The agent replaces the whole team chain, so send the ports of all the team instances
+	teamPorts, err := GetTeamMappedPorts(teamID)
+	if err != nil {
+		return fmt.Errorf("firewall agent push failed: %w", err)
+	}
+	seen := make(map[int]bool, len(teamPorts))
+	for _, p := range teamPorts {
+		seen[p] = true
+	}
+	for _, p := range ports {
+		if !seen[p] {
+			teamPorts = append(teamPorts, p)
 		}
 	}
+
+	rules, err := client.ApplyTeamFirewall(shared.FirewallRequest{
+		TeamID:     teamID,
+		Ports:      teamPorts,
+		AllowedIPs: allowedIPs,
+	})
+	if err != nil {
+		return fmt.Errorf("firewall agent push failed: %w", err)
+	}
+	debug.Log("PushFirewallToAgent: team %d has %d rules", teamID, len(rules))
 	return nil
 }
 
+// SyncTeamFirewall re-applies the firewall of a team from its running instances once one stops, and removes
+// the team chain when none is left, so that freed ports are not dropped for the next instance using them
+func SyncTeamFirewall(teamID uint) error {
+	if !firewallIsolationEnabled() || teamID == 0 {
+		return nil
+	}
+
+	ports, err := GetTeamMappedPorts(teamID)
+	if err != nil {
+		return fmt.Errorf("firewall agent sync failed: %w", err)
+	}
+	if len(ports) == 0 {
+		return RemoveFirewallFromAgent(teamID)
+	}
+
+	teamIPs, err := GetTeamIPs(teamID)
+	if err != nil {
+		return fmt.Errorf("firewall agent sync failed: %w", err)
+	}
+	return PushFirewallToAgent(teamID, nil, teamIPs)
+}
+
+// RemoveFirewallFromAgent drops the rules of a team, e.g. once it is deleted
+func RemoveFirewallFromAgent(teamID uint) error {
+	if !firewallIsolationEnabled() {
+		return nil
+	}
+
+	client, err := NewAgentClient()
+	if err != nil {
+		return fmt.Errorf("firewall agent removal failed: %w", err)
+	}
+	return client.RemoveTeamFirewall(teamID)
+}
+
 func getDefaultGateway() (string, error) {
 	routes, err := netlink.RouteList(nil, netlink.FAMILY_V4)
 	if err != nil {
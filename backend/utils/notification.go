@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// notificationMessage is the WebSocket payload of a notification
+type notificationMessage struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SendNotification stores a notification and pushes it to its user, its team or everyone
+func SendNotification(notification *models.Notification) error {
+	if err := config.DB.Create(notification).Error; err != nil {
+		return err
+	}
+	if WebSocketHub == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(notificationMessage{
+		ID:        notification.ID,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Type:      notification.Type,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	switch {
+	case notification.UserID != nil:
+		WebSocketHub.SendToUser(*notification.UserID, payload)
+	case notification.TeamID != nil:
+		WebSocketHub.SendToTeam(*notification.TeamID, payload)
+	default:
+		WebSocketHub.SendToAll(payload)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"golang.org/x/oauth2"
+)
+
+// oidcDiscoveryTTL is how long the provider metadata is kept before discovering it again
+const oidcDiscoveryTTL = time.Hour
+
+// OIDCHTTPClient is used for every request to the identity provider
+var OIDCHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// OIDCProvider is the identity provider discovered from OIDC_ISSUER
+type OIDCProvider struct {
+	*oidc.Provider
+	issuer    string
+	clientID  string
+	verifier  *oidc.IDTokenVerifier
+	fetchedAt time.Time
+}
+
+var (
+	oidcMu       sync.Mutex
+	oidcProvider *OIDCProvider
+)
+
+// OIDCEnabled tells if single sign-on is enabled and configured
+func OIDCEnabled() bool {
+	return config.GetConfigBool("OIDC_ENABLED", false) &&
+		config.GetConfigValue("OIDC_ISSUER", "") != "" &&
+		config.GetConfigValue("OIDC_CLIENT_ID", "") != ""
+}
+
+// LocalLoginEnabled tells if users may log in and register with a password
+func LocalLoginEnabled() bool {
+	return config.GetConfigBool("LOCAL_LOGIN_ENABLED", true)
+}
+
+// GetOIDCProvider returns the provider of the configured issuer, discovering it when stale
+func GetOIDCProvider(ctx context.Context) (*OIDCProvider, error) {
+	issuer := strings.TrimSpace(config.GetConfigValue("OIDC_ISSUER", ""))
+	if issuer == "" {
+		return nil, fmt.Errorf("OIDC_ISSUER is not set")
+	}
+	clientID := config.GetConfigValue("OIDC_CLIENT_ID", "")
+
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+
+	if oidcProvider != nil && oidcProvider.issuer == issuer && oidcProvider.clientID == clientID && time.Since(oidcProvider.fetchedAt) < oidcDiscoveryTTL {
+		return oidcProvider, nil
+	}
+
+	provider, err := newOIDCProvider(ctx, issuer, clientID)
+	if err != nil {
+		return nil, err
+	}
+	oidcProvider = provider
+	return oidcProvider, nil
+}
+
+// newOIDCProvider discovers an issuer and prepares the verifier of the ID tokens issued to clientID
+func newOIDCProvider(ctx context.Context, issuer, clientID string) (*OIDCProvider, error) {
+	// The provider keeps the client of this context to fetch and refresh the signing keys
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, OIDCHTTPClient), issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	return &OIDCProvider{
+		Provider:  provider,
+		issuer:    issuer,
+		clientID:  clientID,
+		verifier:  provider.Verifier(&oidc.Config{ClientID: clientID}),
+		fetchedAt: time.Now(),
+	}, nil
+}
+
+// OAuth2Config builds the authorization code flow configuration of the provider
+func (p *OIDCProvider) OAuth2Config(redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: os.Getenv("PTA_OIDC_CLIENT_SECRET"),
+		RedirectURL:  redirectURL,
+		Endpoint:     p.Endpoint(),
+		Scopes:       strings.Fields(config.GetConfigValue("OIDC_SCOPES", "openid profile email")),
+	}
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token and returns its claims
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (map[string]interface{}, error) {
+	idToken, err := p.verifier.Verify(oidc.ClientContext(ctx, OIDCHTTPClient), rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	if idToken.Nonce == "" || idToken.Nonce != nonce {
+		return nil, fmt.Errorf("invalid id token: nonce mismatch")
+	}
+	if idToken.Subject == "" {
+		return nil, fmt.Errorf("invalid id token: missing subject")
+	}
+
+	claims := map[string]interface{}{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	return claims, nil
+}
+
+// FetchUserInfo returns the claims of the userinfo endpoint, nil when the provider has none
+func (p *OIDCProvider) FetchUserInfo(ctx context.Context, token *oauth2.Token) (map[string]interface{}, error) {
+	if p.UserInfoEndpoint() == "" {
+		return nil, nil
+	}
+
+	userInfo, err := p.UserInfo(oidc.ClientContext(ctx, OIDCHTTPClient), oauth2.StaticTokenSource(token))
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err := userInfo.Claims(&claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// OIDCClaimValues returns a claim as a list of strings, for single values, arrays and space separated lists
+func OIDCClaimValues(claims map[string]interface{}, name string) []string {
+	var values []string
+	switch v := claims[name].(type) {
+	case string:
+		values = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	case bool:
+		values = []string{fmt.Sprint(v)}
+	}
+	return values
+}
+
+// OIDCRole maps the claims of a user to a role, or returns "" when no mapping is configured.
+// A user gets the admin role when the OIDC_ADMIN_CLAIM claim contains one of the OIDC_ADMIN_VALUES.
+func OIDCRole(claims map[string]interface{}) string {
+	claim := config.GetConfigValue("OIDC_ADMIN_CLAIM", "")
+	if claim == "" {
+		return ""
+	}
+
+	adminValues := make(map[string]bool)
+	for _, value := range strings.Split(config.GetConfigValue("OIDC_ADMIN_VALUES", ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			adminValues[value] = true
+		}
+	}
+	for _, value := range OIDCClaimValues(claims, claim) {
+		if adminValues[value] {
+			return "admin"
+		}
+	}
+	return "member"
+}
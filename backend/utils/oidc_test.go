@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "pwnthemall"
+
+// mockIdP is an identity provider serving discovery and JWKS, signing ID tokens with its key
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"userinfo_endpoint":                     idp.server.URL + "/userinfo",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// token signs an ID token with key, overriding the default claims with overrides
+func (idp *mockIdP) token(t *testing.T, key *rsa.PrivateKey, overrides jwt.MapClaims) string {
+	t.Helper()
+	claims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   testClientID,
+		"sub":   "user-1",
+		"nonce": "expected-nonce",
+		"email": "user@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	idp := newMockIdP(t)
+
+	provider, err := newOIDCProvider(context.Background(), idp.server.URL, testClientID)
+	if err != nil {
+		t.Fatalf("newOIDCProvider: %v", err)
+	}
+	endpoint := provider.Endpoint()
+	if endpoint.AuthURL != idp.server.URL+"/authorize" || endpoint.TokenURL != idp.server.URL+"/token" {
+		t.Errorf("endpoint = %+v, want the discovered ones", endpoint)
+	}
+	if provider.UserInfoEndpoint() != idp.server.URL+"/userinfo" {
+		t.Errorf("userinfo endpoint = %q", provider.UserInfoEndpoint())
+	}
+
+	// The discovered issuer must be the configured one
+	if _, err := newOIDCProvider(context.Background(), idp.server.URL+"/other", testClientID); err == nil {
+		t.Error("expected discovery of an unknown issuer to fail")
+	}
+}
+
+func TestOIDCVerifyIDToken(t *testing.T) {
+	idp := newMockIdP(t)
+	provider, err := newOIDCProvider(context.Background(), idp.server.URL, testClientID)
+	if err != nil {
+		t.Fatalf("newOIDCProvider: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := provider.VerifyIDToken(context.Background(), idp.token(t, idp.key, nil), "expected-nonce")
+	if err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if claims["sub"] != "user-1" || claims["email"] != "user@example.com" {
+		t.Errorf("claims = %v", claims)
+	}
+
+	cases := map[string]struct {
+		raw   string
+		nonce string
+		want  string
+	}{
+		"bad signature":  {idp.token(t, otherKey, nil), "expected-nonce", "signature"},
+		"wrong audience": {idp.token(t, idp.key, jwt.MapClaims{"aud": "someone-else"}), "expected-nonce", "audience"},
+		"wrong issuer":   {idp.token(t, idp.key, jwt.MapClaims{"iss": "https://evil.example"}), "expected-nonce", "different provider"},
+		"expired":        {idp.token(t, idp.key, jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}), "expected-nonce", "expired"},
+		"nonce mismatch": {idp.token(t, idp.key, nil), "another-nonce", "nonce"},
+		"missing nonce":  {idp.token(t, idp.key, jwt.MapClaims{"nonce": ""}), "", "nonce"},
+	}
+	for name, c := range cases {
+		_, err := provider.VerifyIDToken(context.Background(), c.raw, c.nonce)
+		if err == nil {
+			t.Errorf("%s: token accepted", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error %q does not mention %q", name, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/meta"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// BuildPrerequisites validates the prerequisites of a chall.yml and converts them for storage
+func BuildPrerequisites(metas []meta.PrerequisiteMetadata) ([]models.Prerequisite, error) {
+	if len(metas) == 0 {
+		return nil, nil
+	}
+
+	prerequisites := make([]models.Prerequisite, 0, len(metas))
+	for _, m := range metas {
+		prerequisite, err := buildPrerequisite(m)
+		if err != nil {
+			return nil, err
+		}
+		prerequisites = append(prerequisites, prerequisite)
+	}
+	return prerequisites, nil
+}
+
+// buildPrerequisite converts a single condition, checking that exactly one kind is set
+func buildPrerequisite(m meta.PrerequisiteMetadata) (models.Prerequisite, error) {
+	kinds := 0
+	for _, set := range []bool{m.Challenge != "", m.All != nil, m.Any != nil, m.Category != "", m.MinScore != 0} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return models.Prerequisite{}, fmt.Errorf("a prerequisite needs exactly one of challenge, all, any, category or min_score")
+	}
+
+	p := models.Prerequisite{
+		Challenge: strings.TrimSpace(m.Challenge),
+		Category:  strings.TrimSpace(m.Category),
+		MinScore:  m.MinScore,
+		Count:     m.Count,
+	}
+	var err error
+	switch {
+	case m.All != nil:
+		if len(m.All) == 0 {
+			return p, fmt.Errorf("prerequisite group all is empty")
+		}
+		if p.All, err = BuildPrerequisites(m.All); err != nil {
+			return p, err
+		}
+	case m.Any != nil:
+		if len(m.Any) == 0 {
+			return p, fmt.Errorf("prerequisite group any is empty")
+		}
+		if p.Count < 0 || p.Count > len(m.Any) {
+			return p, fmt.Errorf("prerequisite group any needs a count between 1 and %d", len(m.Any))
+		}
+		if p.Any, err = BuildPrerequisites(m.Any); err != nil {
+			return p, err
+		}
+	case p.Category != "":
+		if p.Count < 0 {
+			return p, fmt.Errorf("invalid solve count %d for category %s", p.Count, p.Category)
+		}
+	case p.MinScore < 0:
+		return p, fmt.Errorf("invalid min_score %d", p.MinScore)
+	}
+	if p.Count != 0 && p.Any == nil && p.Category == "" {
+		return p, fmt.Errorf("count only applies to any and category prerequisites")
+	}
+	return p, nil
+}
+
+// requiredCount returns how many conditions of an any group, or solves of a category, must be met
+func requiredCount(p models.Prerequisite) int {
+	if p.Count > 0 {
+		return p.Count
+	}
+	return 1
+}
+
+// prerequisiteSlugs appends every challenge slug referenced by the conditions
+func prerequisiteSlugs(prerequisites []models.Prerequisite, slugs []string) []string {
+	for _, p := range prerequisites {
+		if p.Challenge != "" {
+			slugs = append(slugs, p.Challenge)
+		}
+		slugs = prerequisiteSlugs(p.All, slugs)
+		slugs = prerequisiteSlugs(p.Any, slugs)
+	}
+	return slugs
+}
+
+// ValidatePrerequisiteGraph checks that the prerequisites of a challenge do not form a cycle
+// with the other challenges. dependsOn is the legacy depends_on challenge name.
+func ValidatePrerequisiteGraph(slug string, prerequisites []models.Prerequisite, dependsOn string) error {
+	var challenges []models.Challenge
+	if err := config.DB.Select("slug", "name", "depends_on", "prerequisites").Find(&challenges).Error; err != nil {
+		return err
+	}
+
+	slugByName := make(map[string]string, len(challenges))
+	for _, challenge := range challenges {
+		slugByName[challenge.Name] = challenge.Slug
+	}
+
+	edges := make(map[string][]string, len(challenges)+1)
+	for _, challenge := range challenges {
+		edges[challenge.Slug] = challengeDependencies(challenge.Prerequisites, challenge.DependsOn, slugByName)
+	}
+	edges[slug] = challengeDependencies(prerequisites, dependsOn, slugByName)
+
+	for _, dependency := range edges[slug] {
+		if _, ok := edges[dependency]; !ok {
+			log.Printf("Warning: challenge %s requires unknown challenge %s", slug, dependency)
+		}
+	}
+
+	// Only a cycle going through this challenge can be new, the others were rejected on their own sync
+	visited := make(map[string]bool)
+	var path []string
+	var visit func(string) bool
+	visit = func(current string) bool {
+		path = append(path, current)
+		for _, next := range edges[current] {
+			if next == slug {
+				path = append(path, next)
+				return true
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			if visit(next) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(slug) {
+		return fmt.Errorf("prerequisite cycle: %s", strings.Join(path, " -> "))
+	}
+	return nil
+}
+
+// PrerequisiteSlugs returns every challenge slug referenced by the conditions
+func PrerequisiteSlugs(prerequisites []models.Prerequisite) []string {
+	return prerequisiteSlugs(prerequisites, nil)
+}
+
+// challengeDependencies returns the slugs a challenge depends on, including its legacy depends_on
+func challengeDependencies(prerequisites []models.Prerequisite, dependsOn string, slugByName map[string]string) []string {
+	slugs := PrerequisiteSlugs(prerequisites)
+	if dependsOn != "" {
+		if dependencySlug, ok := slugByName[dependsOn]; ok {
+			slugs = append(slugs, dependencySlug)
+		}
+	}
+	return slugs
+}
+
+// PrerequisiteProgress is what a team has achieved towards unlocking challenges
+type PrerequisiteProgress struct {
+	Solved     map[string]bool // Solved challenge slugs
+	Categories map[string]int  // Solve count per category name
+	Score      int
+	slugByName map[string]string
+}
+
+// LoadPrerequisiteProgress fetches the solves and score of a team
+func LoadPrerequisiteProgress(teamID uint) (*PrerequisiteProgress, error) {
+	progress := &PrerequisiteProgress{
+		Solved:     make(map[string]bool),
+		Categories: make(map[string]int),
+		slugByName: make(map[string]string),
+	}
+
+	var solved []struct {
+		Slug     string
+		Category string
+	}
+	err := config.DB.Table("solves").
+		Select("challenges.slug AS slug, challenge_categories.name AS category").
+		Joins("JOIN challenges ON challenges.id = solves.challenge_id").
+		Joins("LEFT JOIN challenge_categories ON challenge_categories.id = challenges.challenge_category_id").
+		Where("solves.team_id = ?", teamID).
+		Scan(&solved).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch solves: %w", err)
+	}
+	for _, s := range solved {
+		progress.Solved[s.Slug] = true
+		progress.Categories[s.Category]++
+	}
+
+	var challenges []models.Challenge
+	if err := config.DB.Select("slug", "name").Find(&challenges).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch challenges: %w", err)
+	}
+	for _, challenge := range challenges {
+		progress.slugByName[challenge.Name] = challenge.Slug
+	}
+
+	entry, err := GetTeamScoreEntry(teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch team score: %w", err)
+	}
+	progress.Score = entry.TotalScore
+
+	return progress, nil
+}
+
+// Missing returns the prerequisites of a challenge the team has not met yet
+func (p *PrerequisiteProgress) Missing(challenge *models.Challenge) []models.Prerequisite {
+	var missing []models.Prerequisite
+	if challenge.DependsOn != "" {
+		slug, ok := p.slugByName[challenge.DependsOn]
+		if !ok {
+			slug = challenge.DependsOn
+		}
+		if !p.Solved[slug] {
+			missing = append(missing, models.Prerequisite{Challenge: slug})
+		}
+	}
+	for _, prerequisite := range challenge.Prerequisites {
+		if !p.met(prerequisite) {
+			missing = append(missing, prerequisite)
+		}
+	}
+	return missing
+}
+
+// met tells if the team fulfills a single condition
+func (p *PrerequisiteProgress) met(prerequisite models.Prerequisite) bool {
+	switch {
+	case prerequisite.Challenge != "":
+		return p.Solved[prerequisite.Challenge]
+	case prerequisite.All != nil:
+		for _, nested := range prerequisite.All {
+			if !p.met(nested) {
+				return false
+			}
+		}
+		return true
+	case prerequisite.Any != nil:
+		count := 0
+		for _, nested := range prerequisite.Any {
+			if p.met(nested) {
+				count++
+			}
+		}
+		return count >= requiredCount(prerequisite)
+	case prerequisite.Category != "":
+		return p.Categories[prerequisite.Category] >= requiredCount(prerequisite)
+	default:
+		return p.Score >= prerequisite.MinScore
+	}
+}
+
+// MissingPrerequisites returns what a team still lacks to unlock a challenge
+func MissingPrerequisites(teamID uint, challenge *models.Challenge) ([]models.Prerequisite, error) {
+	if challenge.DependsOn == "" && len(challenge.Prerequisites) == 0 {
+		return nil, nil
+	}
+	progress, err := LoadPrerequisiteProgress(teamID)
+	if err != nil {
+		return nil, err
+	}
+	return progress.Missing(challenge), nil
+}
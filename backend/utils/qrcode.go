@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCodeSVG encodes a text as a QR code and returns it as an SVG image
+func QRCodeSVG(text string) (string, error) {
+	qr, err := qrcode.New(text, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+
+	// The bitmap already includes the quiet zone around the code
+	bitmap := qr.Bitmap()
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x, y)
+			}
+		}
+	}
+	dimension := len(bitmap)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="100%%" height="100%%" fill="#ffffff"/><path d="%s" fill="#000000"/></svg>`,
+		dimension, dimension, path.String()), nil
+}
@@ -0,0 +1,470 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm/clause"
+)
+
+// scoreboardMu serializes refreshes so a slow refresh never overwrites a newer one
+var scoreboardMu sync.Mutex
+
+// scoreboardVersion changes every time scoreboard entries are written or removed
+var scoreboardVersion atomic.Uint64
+
+// frozenScoreboard caches the scoreboard at the freeze time until the scores change
+var frozenScoreboard struct {
+	sync.Mutex
+	valid   bool
+	before  time.Time
+	version uint64
+	entries []models.ScoreboardEntry
+}
+
+// solvePoints returns the points a solve is currently worth, first blood bonus included.
+// Solve-based decays use the current challenge value, time decay the value at solve time.
+func solvePoints(ds *DecayService, challenge *models.Challenge, currentValue int, position int, bloodPosition int, solvedAt time.Time) int {
+	points := currentValue
+	if ds.IsTimeBased(challenge) {
+		points = ds.CalculateDecayedPoints(challenge, position, solvedAt)
+	}
+	if challenge.EnableFirstBlood && bloodPosition < len(challenge.FirstBloodBonuses) {
+		points += int(challenge.FirstBloodBonuses[bloodPosition])
+	}
+	return points
+}
+
+// FirstBloodPerBracket tells if first bloods are ranked inside each bracket rather than globally
+func FirstBloodPerBracket() bool {
+	return config.GetConfigBool("FIRST_BLOOD_PER_BRACKET", false)
+}
+
+// TeamBrackets returns the bracket of every team, 0 for teams without one
+func TeamBrackets() (map[uint]uint, error) {
+	var teams []models.Team
+	if err := config.DB.Unscoped().Select("id", "bracket_id").Find(&teams).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch team brackets: %w", err)
+	}
+	brackets := make(map[uint]uint, len(teams))
+	for _, team := range teams {
+		if team.BracketID != nil {
+			brackets[team.ID] = *team.BracketID
+		}
+	}
+	return brackets, nil
+}
+
+// FirstBloodPosition returns the 0-based first blood rank the next solve of a challenge by a team gets
+func FirstBloodPosition(challengeID uint, teamID uint) (int64, error) {
+	query := config.DB.Model(&models.Solve{}).Where("challenge_id = ?", challengeID)
+	if FirstBloodPerBracket() {
+		var team models.Team
+		if err := config.DB.Select("id", "bracket_id").First(&team, teamID).Error; err != nil {
+			return 0, err
+		}
+		if team.BracketID != nil {
+			query = query.Where("team_id IN (SELECT id FROM teams WHERE bracket_id = ?)", *team.BracketID)
+		} else {
+			query = query.Where("team_id IN (SELECT id FROM teams WHERE bracket_id IS NULL)")
+		}
+	}
+	var position int64
+	err := query.Count(&position).Error
+	return position, err
+}
+
+// ScoreSolves returns what each solve is currently worth, keyed by (challenge, team).
+// A non-zero before scores them as they stood at that time, ignoring later solves.
+func ScoreSolves(solves []models.Solve, before time.Time) (map[[2]uint]int, error) {
+	points := make(map[[2]uint]int, len(solves))
+	challengeIDs := make([]uint, 0)
+	seen := make(map[uint]bool)
+	for _, solve := range solves {
+		if !seen[solve.ChallengeID] {
+			seen[solve.ChallengeID] = true
+			challengeIDs = append(challengeIDs, solve.ChallengeID)
+		}
+	}
+	if len(challengeIDs) == 0 {
+		return points, nil
+	}
+
+	var list []models.Challenge
+	if err := config.DB.Preload("DecayFormula").Where("id IN ?", challengeIDs).Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch challenges: %w", err)
+	}
+	challenges := make(map[uint]*models.Challenge, len(list))
+	for i := range list {
+		challenges[list[i].ID] = &list[i]
+	}
+
+	// Solve positions are needed for first blood bonuses and solve-based decay
+	query := config.DB.Select("team_id", "challenge_id").Where("challenge_id IN ?", challengeIDs)
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+	var ordered []models.Solve
+	if err := query.Order("challenge_id ASC, created_at ASC").Find(&ordered).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch solve positions: %w", err)
+	}
+	// First blood ranks are the solve positions, unless they are counted inside each bracket
+	var brackets map[uint]uint
+	if FirstBloodPerBracket() {
+		var err error
+		if brackets, err = TeamBrackets(); err != nil {
+			return nil, err
+		}
+	}
+	positions := make(map[[2]uint]int, len(ordered))
+	bloodPositions := make(map[[2]uint]int, len(ordered))
+	solveCounts := make(map[uint]int)
+	bracketCounts := make(map[[2]uint]int)
+	for _, solve := range ordered {
+		key := [2]uint{solve.ChallengeID, solve.TeamID}
+		positions[key] = solveCounts[solve.ChallengeID]
+		solveCounts[solve.ChallengeID]++
+		if brackets != nil {
+			bracketKey := [2]uint{solve.ChallengeID, brackets[solve.TeamID]}
+			bloodPositions[key] = bracketCounts[bracketKey]
+			bracketCounts[bracketKey]++
+		} else {
+			bloodPositions[key] = positions[key]
+		}
+	}
+
+	ds := NewDecay()
+	now := time.Now()
+	if !before.IsZero() {
+		now = before
+	}
+	currentValues := make(map[uint]int, len(challenges))
+	for id, challenge := range challenges {
+		if decay, ok := loadDecayFormula(challenge); ok {
+			currentValues[id] = ds.calculateDecay(challenge, decay, solveCounts[id], now)
+		} else {
+			currentValues[id] = challenge.Points
+		}
+	}
+
+	for _, solve := range solves {
+		challenge, ok := challenges[solve.ChallengeID]
+		if !ok {
+			continue
+		}
+		key := [2]uint{solve.ChallengeID, solve.TeamID}
+		points[key] = solvePoints(ds, challenge, currentValues[challenge.ID], positions[key], bloodPositions[key], solve.CreatedAt)
+	}
+	return points, nil
+}
+
+// TeamSolvePoints returns what each solve of a team is worth, keyed by challenge.
+// A non-zero before only counts the solves made before that time.
+func TeamSolvePoints(teamID uint, before time.Time) (map[uint]int, error) {
+	query := config.DB.Where("team_id = ?", teamID)
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+	var solves []models.Solve
+	if err := query.Find(&solves).Error; err != nil {
+		return nil, err
+	}
+	points, err := ScoreSolves(solves, before)
+	if err != nil {
+		return nil, err
+	}
+	byChallenge := make(map[uint]int, len(points))
+	for key, value := range points {
+		byChallenge[key[0]] = value
+	}
+	return byChallenge, nil
+}
+
+// computeTeamScores computes the scoreboard entries of the given teams with a fixed number of queries.
+// A non-zero before computes them as they stood at that time.
+func computeTeamScores(teamIDs []uint, before time.Time) (map[uint]*models.ScoreboardEntry, error) {
+	entries := make(map[uint]*models.ScoreboardEntry, len(teamIDs))
+	for _, teamID := range teamIDs {
+		entries[teamID] = &models.ScoreboardEntry{TeamID: teamID}
+	}
+	if len(teamIDs) == 0 {
+		return entries, nil
+	}
+
+	solveQuery := config.DB.Where("team_id IN ?", teamIDs)
+	hintQuery := config.DB.Model(&models.HintPurchase{}).Where("team_id IN ?", teamIDs)
+	awardQuery := config.DB.Model(&models.Award{}).Where("team_id IN ?", teamIDs)
+	if !before.IsZero() {
+		solveQuery = solveQuery.Where("created_at < ?", before)
+		hintQuery = hintQuery.Where("created_at < ?", before)
+		awardQuery = awardQuery.Where("awarded_at < ?", before)
+	}
+
+	var solves []models.Solve
+	if err := solveQuery.Find(&solves).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch solves: %w", err)
+	}
+
+	points, err := ScoreSolves(solves, before)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, solve := range solves {
+		value, ok := points[[2]uint{solve.ChallengeID, solve.TeamID}]
+		if !ok {
+			continue
+		}
+		entry := entries[solve.TeamID]
+		entry.SolvePoints += value
+		entry.SolveCount++
+		if entry.LastSolveAt == nil || solve.CreatedAt.After(*entry.LastSolveAt) {
+			solvedAt := solve.CreatedAt
+			entry.LastSolveAt = &solvedAt
+		}
+	}
+
+	var hintCosts []struct {
+		TeamID uint
+		Total  int
+	}
+	if err := hintQuery.Select("team_id, COALESCE(SUM(cost), 0) AS total").
+		Group("team_id").Scan(&hintCosts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch hint purchases: %w", err)
+	}
+	for _, cost := range hintCosts {
+		if entry, ok := entries[cost.TeamID]; ok {
+			entry.HintsCost = cost.Total
+		}
+	}
+
+	var awardTotals []struct {
+		TeamID uint
+		Total  int
+	}
+	if err := awardQuery.Select("team_id, COALESCE(SUM(value), 0) AS total").
+		Group("team_id").Scan(&awardTotals).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch awards: %w", err)
+	}
+	for _, award := range awardTotals {
+		if entry, ok := entries[award.TeamID]; ok {
+			entry.AwardPoints = award.Total
+		}
+	}
+
+	for _, entry := range entries {
+		entry.TotalScore = entry.SolvePoints + entry.AwardPoints - entry.HintsCost
+	}
+	return entries, nil
+}
+
+// saveScoreboardEntries upserts computed entries
+func saveScoreboardEntries(entries map[uint]*models.ScoreboardEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	list := make([]models.ScoreboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, *entry)
+	}
+	defer scoreboardVersion.Add(1)
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}},
+		UpdateAll: true,
+	}).Create(&list).Error
+}
+
+// RefreshTeamScores recomputes the scoreboard entries of some teams
+func RefreshTeamScores(teamIDs ...uint) error {
+	scoreboardMu.Lock()
+	defer scoreboardMu.Unlock()
+
+	entries, err := computeTeamScores(teamIDs, time.Time{})
+	if err != nil {
+		return err
+	}
+	return saveScoreboardEntries(entries)
+}
+
+// RefreshChallengeScores recomputes the teams that solved a challenge, as its value changed for all of them
+func RefreshChallengeScores(challengeIDs ...uint) error {
+	if len(challengeIDs) == 0 {
+		return nil
+	}
+	var teamIDs []uint
+	if err := config.DB.Model(&models.Solve{}).Where("challenge_id IN ?", challengeIDs).
+		Distinct().Pluck("team_id", &teamIDs).Error; err != nil {
+		return err
+	}
+	return RefreshTeamScores(teamIDs...)
+}
+
+// RefreshDecayFormulaScores recomputes the teams that solved a challenge using a decay formula
+func RefreshDecayFormulaScores(decayFormulaID uint) error {
+	var challengeIDs []uint
+	if err := config.DB.Model(&models.Challenge{}).Where("decay_formula_id = ?", decayFormulaID).
+		Pluck("id", &challengeIDs).Error; err != nil {
+		return err
+	}
+	return RefreshChallengeScores(challengeIDs...)
+}
+
+// RebuildScoreboard recomputes every entry from scratch and returns how many were out of date
+func RebuildScoreboard() (int, error) {
+	scoreboardMu.Lock()
+	defer scoreboardMu.Unlock()
+
+	var teamIDs []uint
+	if err := config.DB.Model(&models.Team{}).Pluck("id", &teamIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch teams: %w", err)
+	}
+
+	entries, err := computeTeamScores(teamIDs, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+
+	var existing []models.ScoreboardEntry
+	if err := config.DB.Find(&existing).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch scoreboard: %w", err)
+	}
+	stale := 0
+	current := make(map[uint]models.ScoreboardEntry, len(existing))
+	for _, entry := range existing {
+		current[entry.TeamID] = entry
+		if _, ok := entries[entry.TeamID]; !ok {
+			stale++
+		}
+	}
+	for teamID, entry := range entries {
+		old, ok := current[teamID]
+		if !ok || old.SolvePoints != entry.SolvePoints || old.AwardPoints != entry.AwardPoints || old.HintsCost != entry.HintsCost || old.SolveCount != entry.SolveCount {
+			stale++
+			log.Printf("Scoreboard: team %d out of date (score %d -> %d)", teamID, old.TotalScore, entry.TotalScore)
+		}
+	}
+
+	if len(teamIDs) > 0 {
+		if err := config.DB.Where("team_id NOT IN ?", teamIDs).Delete(&models.ScoreboardEntry{}).Error; err != nil {
+			return stale, err
+		}
+	} else if err := config.DB.Where("1 = 1").Delete(&models.ScoreboardEntry{}).Error; err != nil {
+		return stale, err
+	}
+	return stale, saveScoreboardEntries(entries)
+}
+
+// RemoveTeamScore drops the scoreboard entry of a deleted team
+func RemoveTeamScore(teamID uint) error {
+	defer scoreboardVersion.Add(1)
+	return config.DB.Where("team_id = ?", teamID).Delete(&models.ScoreboardEntry{}).Error
+}
+
+// GetScoreboard returns the entries of existing teams, best score first, earliest last solve on ties
+func GetScoreboard() ([]models.ScoreboardEntry, error) {
+	var entries []models.ScoreboardEntry
+	err := config.DB.Joins("JOIN teams ON teams.id = scoreboard_entries.team_id AND teams.deleted_at IS NULL").
+		Order("scoreboard_entries.total_score DESC, scoreboard_entries.last_solve_at ASC NULLS LAST, scoreboard_entries.team_id ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// GetTeamScoreEntry returns the scoreboard entry of a team, computing it when missing
+func GetTeamScoreEntry(teamID uint) (models.ScoreboardEntry, error) {
+	var entry models.ScoreboardEntry
+	if err := config.DB.Where("team_id = ?", teamID).Limit(1).Find(&entry).Error; err != nil {
+		return entry, err
+	}
+	if entry.TeamID != 0 {
+		return entry, nil
+	}
+	if err := RefreshTeamScores(teamID); err != nil {
+		return entry, err
+	}
+	err := config.DB.Where("team_id = ?", teamID).First(&entry).Error
+	return entry, err
+}
+
+// frozenEntries returns the scoreboard at the freeze time, computed once per score change
+func frozenEntries(before time.Time) ([]models.ScoreboardEntry, error) {
+	frozenScoreboard.Lock()
+	defer frozenScoreboard.Unlock()
+
+	// Read before computing: a change made meanwhile leaves the cache stale for the next call
+	version := scoreboardVersion.Load()
+	if frozenScoreboard.valid && frozenScoreboard.version == version && frozenScoreboard.before.Equal(before) {
+		return frozenScoreboard.entries, nil
+	}
+
+	var teamIDs []uint
+	if err := config.DB.Model(&models.Team{}).Pluck("id", &teamIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch teams: %w", err)
+	}
+	computed, err := computeTeamScores(teamIDs, before)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]models.ScoreboardEntry, 0, len(computed))
+	for _, entry := range computed {
+		entries = append(entries, *entry)
+	}
+	sortScoreboardEntries(entries)
+
+	frozenScoreboard.valid = true
+	frozenScoreboard.before = before
+	frozenScoreboard.version = version
+	frozenScoreboard.entries = entries
+	return entries, nil
+}
+
+// sortScoreboardEntries orders entries like GetScoreboard
+func sortScoreboardEntries(entries []models.ScoreboardEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.TotalScore != b.TotalScore {
+			return a.TotalScore > b.TotalScore
+		}
+		if (a.LastSolveAt == nil) != (b.LastSolveAt == nil) {
+			return a.LastSolveAt != nil
+		}
+		if a.LastSolveAt != nil && !a.LastSolveAt.Equal(*b.LastSolveAt) {
+			return a.LastSolveAt.Before(*b.LastSolveAt)
+		}
+		return a.TeamID < b.TeamID
+	})
+}
+
+// GetFrozenScoreboard returns the scoreboard as it stood at the freeze time, ordered like GetScoreboard.
+// The entry of liveTeamID, when set, is the live one so a team keeps seeing its own solves.
+func GetFrozenScoreboard(before time.Time, liveTeamID uint) ([]models.ScoreboardEntry, error) {
+	frozen, err := frozenEntries(before)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cached slice is shared between requests
+	entries := make([]models.ScoreboardEntry, len(frozen))
+	copy(entries, frozen)
+	if liveTeamID == 0 {
+		return entries, nil
+	}
+	for i := range entries {
+		if entries[i].TeamID != liveTeamID {
+			continue
+		}
+		live, err := GetTeamScoreEntry(liveTeamID)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = live
+		sortScoreboardEntries(entries)
+		break
+	}
+	return entries, nil
+}
@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+)
+
+// SessionLifetime is how long a login lasts before the user must log in again, refreshes do not extend it
+const SessionLifetime = 7 * 24 * time.Hour
+
+// refreshReuseGrace lets the previous refresh token through for a moment after a rotation, for tabs refreshing
+// at the same time with the same cookie. Those only get a new access token.
+const refreshReuseGrace = 10 * time.Second
+
+var (
+	// ErrInvalidRefreshToken is returned for malformed, expired or revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid_refresh_token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token comes back, the session is then revoked
+	ErrRefreshTokenReused = errors.New("refresh_token_reused")
+)
+
+type refreshClaims struct {
+	SessionID  uint `json:"sid"`
+	Generation int  `json:"gen"`
+	jwt.RegisteredClaims
+}
+
+// signRefreshToken returns the refresh token of the current generation of a session
+func signRefreshToken(session *models.Session) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	claims := refreshClaims{
+		SessionID:  session.ID,
+		Generation: session.Generation,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			Subject:   strconv.FormatUint(uint64(session.UserID), 10),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(RefreshSecret)
+}
+
+// parseRefreshToken checks the signature and expiry of a refresh token
+func parseRefreshToken(tokenStr string) (*refreshClaims, error) {
+	claims := &refreshClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		return RefreshSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid || claims.SessionID == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+	return claims, nil
+}
+
+// CreateSession records a new login of the user and returns its first refresh token
+func CreateSession(c *gin.Context, userID uint) (*models.Session, string, error) {
+	now := time.Now()
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	session := models.Session{
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  c.ClientIP(),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(SessionLifetime),
+	}
+
+	var token string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		var err error
+		if token, err = signRefreshToken(&session); err != nil {
+			return err
+		}
+		session.TokenHash = HashAPIToken(token)
+		return tx.Model(&session).Update("token_hash", session.TokenHash).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &session, token, nil
+}
+
+// RotateSession exchanges a refresh token for the next one of its session. An empty token is returned when the
+// refresh token was just rotated by a concurrent request, the caller keeps the cookie it already has then.
+// A token of an older generation means it was stolen or replayed, so the whole session is revoked.
+func RotateSession(c *gin.Context, tokenStr string) (*models.Session, string, error) {
+	claims, err := parseRefreshToken(tokenStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var session models.Session
+	if err := config.DB.First(&session, claims.SessionID).Error; err != nil {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) || claims.Subject != strconv.FormatUint(uint64(session.UserID), 10) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	switch {
+	case claims.Generation == session.Generation:
+		if !hmac.Equal([]byte(HashAPIToken(tokenStr)), []byte(session.TokenHash)) {
+			return nil, "", ErrInvalidRefreshToken
+		}
+		next := session
+		next.Generation++
+		token, err := signRefreshToken(&next)
+		if err != nil {
+			return nil, "", err
+		}
+		// The generation condition lets a single request rotate a given token
+		result := config.DB.Model(&models.Session{}).
+			Where("id = ? AND generation = ?", session.ID, session.Generation).
+			Updates(map[string]interface{}{
+				"generation":   next.Generation,
+				"token_hash":   HashAPIToken(token),
+				"rotated_at":   now,
+				"last_used_at": now,
+				"ip_address":   c.ClientIP(),
+			})
+		if result.Error != nil {
+			return nil, "", result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &session, "", nil
+		}
+		return &next, token, nil
+
+	case claims.Generation == session.Generation-1 && session.RotatedAt != nil && now.Sub(*session.RotatedAt) < refreshReuseGrace:
+		return &session, "", nil
+
+	default:
+		log.Printf("Refresh token reuse detected for session %d of user %d, revoking it", session.ID, session.UserID)
+		RevokeSession(session.UserID, session.ID)
+		return nil, "", ErrRefreshTokenReused
+	}
+}
+
+// RevokeRefreshTokenSession ends the session of a refresh token, whatever its generation, to log out
+func RevokeRefreshTokenSession(tokenStr string) {
+	claims, err := parseRefreshToken(tokenStr)
+	if err != nil {
+		return
+	}
+	if userID, err := strconv.ParseUint(claims.Subject, 10, 64); err == nil {
+		RevokeSession(uint(userID), claims.SessionID)
+	}
+}
+
+// SessionActive tells if a session can still be used
+func SessionActive(sessionID uint) bool {
+	var count int64
+	config.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, time.Now()).
+		Count(&count)
+	return count > 0
+}
+
+// ActiveSessions lists the sessions of a user that can still be used, the most recently used first
+func ActiveSessions(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := config.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeSession ends a session of a user, its refresh and access tokens stop working at once
+func RevokeSession(userID uint, sessionID uint) (bool, error) {
+	result := config.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeUserSessions ends all the sessions of a user but the one given, 0 to end them all
+func RevokeUserSessions(userID uint, exceptSessionID uint) (int64, error) {
+	query := config.DB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptSessionID != 0 {
+		query = query.Where("id <> ?", exceptSessionID)
+	}
+	result := query.Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// CleanupSessions deletes expired sessions and those revoked for more than a day
+func CleanupSessions() {
+	now := time.Now()
+	result := config.DB.
+		Where("expires_at < ? OR revoked_at < ?", now, now.Add(-24*time.Hour)).
+		Delete(&models.Session{})
+	if result.Error != nil {
+		log.Printf("Failed to clean up sessions: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("Deleted %d expired sessions", result.RowsAffected)
+	}
+}
+
+// StartSessionCleanup deletes the sessions that are no longer needed every hour
+func StartSessionCleanup() {
+	CleanupSessions()
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			CleanupSessions()
+		}
+	}()
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+// TOTP parameters, the defaults of RFC 6238 understood by every authenticator app
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// totpSkew is the number of periods accepted before and after the current one, for clock drift
+	totpSkew = 1
+)
+
+// Session keys of a login waiting for its second factor
+const (
+	twoFactorUserKey    = "2fa_user_id"
+	twoFactorExpiresKey = "2fa_expires"
+)
+
+// TwoFactorLoginTimeout is how long the second login step stays open after the password or single sign-on
+const TwoFactorLoginTimeout = 5 * time.Minute
+
+// RecoveryCodeCount is the number of recovery codes generated when enabling two-factor authentication
+const RecoveryCodeCount = 10
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a new random base32 secret of 160 bits
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPProvisioningURI returns the otpauth:// URI shown as a QR code to authenticator apps
+func TOTPProvisioningURI(secret string, account string) string {
+	issuer := config.GetConfigValue("SITE_NAME", "pwnthemall")
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", strconv.Itoa(totpDigits))
+	params.Set("period", strconv.Itoa(totpPeriod))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// totpCode computes the code of a counter as described in RFC 4226
+func totpCode(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// ValidateTOTP checks a code against the secret around the current time. It returns the counter of the
+// matching period, which must be greater than lastCounter so a code cannot be used twice.
+func ValidateTOTP(secret string, code string, lastCounter int64) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := time.Now().Unix() / totpPeriod
+	for counter := current - totpSkew; counter <= current+totpSkew; counter++ {
+		if counter <= lastCounter {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, counter)), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns new single use codes formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the keyed hash of a recovery code stored for a user. Dashes, spaces and case are ignored.
+func HashRecoveryCode(userID uint, code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	mac := hmac.New(sha256.New, AccessSecret)
+	mac.Write([]byte("recovery-code\x00" + strconv.FormatUint(uint64(userID), 10) + "\x00" + normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TwoFactorRequired tells if the user must use two-factor authentication, which REQUIRE_ADMIN_2FA enforces on admins
+func TwoFactorRequired(user *models.User) bool {
+	return user.Role == "admin" && config.GetConfigBool("REQUIRE_ADMIN_2FA", false)
+}
+
+// SetPendingTwoFactor remembers in the session a user who passed the first login step
+func SetPendingTwoFactor(c *gin.Context, userID uint) error {
+	session := sessions.Default(c)
+	session.Set(twoFactorUserKey, userID)
+	session.Set(twoFactorExpiresKey, time.Now().Add(TwoFactorLoginTimeout).Unix())
+	return session.Save()
+}
+
+// GetPendingTwoFactor returns the user waiting for the second login step, if it has not expired
+func GetPendingTwoFactor(c *gin.Context) (uint, bool) {
+	session := sessions.Default(c)
+	userID, ok := session.Get(twoFactorUserKey).(uint)
+	expires, _ := session.Get(twoFactorExpiresKey).(int64)
+	if !ok || time.Now().Unix() > expires {
+		return 0, false
+	}
+	return userID, true
+}
+
+// ClearPendingTwoFactor ends the second login step
+func ClearPendingTwoFactor(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Delete(twoFactorUserKey)
+	session.Delete(twoFactorExpiresKey)
+	session.Save()
+}
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/pwnthemall/pwnthemall/backend/config"
+	"github.com/pwnthemall/pwnthemall/backend/debug"
+	"github.com/pwnthemall/pwnthemall/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWorkerFull is returned when the selected worker reached its capacity before the instance was recorded
+var ErrWorkerFull = errors.New("worker_full")
+
+// DockerTarget is a docker daemon instances can run on; WorkerID is nil for the default host
+type DockerTarget struct {
+	WorkerID *uint
+	Name     string
+	Client   *client.Client
+}
+
+// DockerClientFor returns the docker client of a worker, or the default client when workerID is nil
+func DockerClientFor(workerID *uint) (*client.Client, error) {
+	if workerID == nil {
+		if err := EnsureDockerClientConnected(); err != nil {
+			return nil, err
+		}
+		return config.DockerClient, nil
+	}
+
+	var worker models.Worker
+	if err := config.DB.First(&worker, *workerID).Error; err != nil {
+		return nil, fmt.Errorf("worker %d not found: %w", *workerID, err)
+	}
+	return config.GetWorkerClient(&worker)
+}
+
+// HasWorkers tells if multi-worker scheduling is configured
+func HasWorkers() bool {
+	var count int64
+	config.DB.Model(&models.Worker{}).Count(&count)
+	return count > 0
+}
+
+// hasWorkerLabels tells if a worker carries every label required by a challenge
+func hasWorkerLabels(worker *models.Worker, required []string) bool {
+	for _, label := range required {
+		found := false
+		for _, have := range worker.Labels {
+			if have == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectWorker returns the least loaded healthy worker with free capacity and every required label.
+// It returns nil without error when no worker is configured, meaning the default docker host is used.
+func SelectWorker(requiredLabels []string) (*models.Worker, error) {
+	var workers []models.Worker
+	if err := config.DB.Find(&workers).Error; err != nil {
+		return nil, err
+	}
+	if len(workers) == 0 {
+		return nil, nil
+	}
+
+	type workerLoad struct {
+		WorkerID uint
+		Count    int
+	}
+	var loads []workerLoad
+	if err := config.DB.Model(&models.Instance{}).
+		Select("worker_id, COUNT(*) as count").
+		Where("worker_id IS NOT NULL").
+		Group("worker_id").
+		Scan(&loads).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int, len(loads))
+	for _, l := range loads {
+		counts[l.WorkerID] = l.Count
+	}
+
+	var best *models.Worker
+	bestLoad := math.MaxFloat64
+	for i := range workers {
+		worker := &workers[i]
+		if !worker.Enabled || !worker.Healthy || !hasWorkerLabels(worker, requiredLabels) {
+			continue
+		}
+
+		count := counts[worker.ID]
+		if worker.Capacity > 0 && count >= worker.Capacity {
+			continue
+		}
+
+		// Load is the share of used capacity; unlimited workers are ranked by instance count only
+		load := float64(count) / math.MaxInt32
+		if worker.Capacity > 0 {
+			load = float64(count) / float64(worker.Capacity)
+		}
+		if load < bestLoad {
+			best = worker
+			bestLoad = load
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no_worker_available")
+	}
+	debug.Log("Selected worker %s (%d instances, capacity %d)", best.Name, counts[best.ID], best.Capacity)
+	return best, nil
+}
+
+// CreateInstanceOnWorker records an instance, checking the capacity of its worker under a lock of the worker
+// row so concurrent starts cannot overfill it
+func CreateInstanceOnWorker(instance *models.Instance) error {
+	if instance.WorkerID == nil {
+		return config.DB.Create(instance).Error
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var worker models.Worker
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "capacity").First(&worker, *instance.WorkerID).Error; err != nil {
+			return err
+		}
+		if worker.Capacity > 0 {
+			var count int64
+			if err := tx.Model(&models.Instance{}).Where("worker_id = ?", worker.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if int(count) >= worker.Capacity {
+				return ErrWorkerFull
+			}
+		}
+		return tx.Create(instance).Error
+	})
+}
+
+// ListDockerTargets returns every reachable docker daemon: the workers if configured, the default host otherwise
+func ListDockerTargets() []DockerTarget {
+	var workers []models.Worker
+	config.DB.Where("enabled = ?", true).Find(&workers)
+
+	if len(workers) == 0 {
+		if err := EnsureDockerClientConnected(); err != nil {
+			return nil
+		}
+		return []DockerTarget{{Name: "default", Client: config.DockerClient}}
+	}
+
+	targets := make([]DockerTarget, 0, len(workers))
+	for i := range workers {
+		worker := workers[i]
+		cl, err := config.GetWorkerClient(&worker)
+		if err != nil {
+			debug.Log("Skipping worker %s: %v", worker.Name, err)
+			continue
+		}
+		id := worker.ID
+		targets = append(targets, DockerTarget{WorkerID: &id, Name: worker.Name, Client: cl})
+	}
+	return targets
+}
+
+// CheckWorkerHealth pings a worker and stores the result
+func CheckWorkerHealth(worker *models.Worker) error {
+	now := time.Now()
+	worker.LastCheckedAt = &now
+
+	cl, err := config.GetWorkerClient(worker)
+	if err == nil {
+		_, err = cl.Ping(context.Background())
+	}
+
+	worker.Healthy = err == nil
+	worker.LastError = ""
+	if err != nil {
+		worker.LastError = err.Error()
+	}
+
+	if saveErr := config.DB.Model(worker).Updates(map[string]interface{}{
+		"healthy":         worker.Healthy,
+		"last_error":      worker.LastError,
+		"last_checked_at": worker.LastCheckedAt,
+	}).Error; saveErr != nil {
+		log.Printf("Failed to save health of worker %s: %v", worker.Name, saveErr)
+	}
+	return err
+}
+
+// RefreshWorkersHealth checks every enabled worker
+func RefreshWorkersHealth() {
+	var workers []models.Worker
+	if err := config.DB.Where("enabled = ?", true).Find(&workers).Error; err != nil {
+		log.Printf("Failed to fetch workers: %v", err)
+		return
+	}
+
+	for i := range workers {
+		wasHealthy := workers[i].Healthy
+		if err := CheckWorkerHealth(&workers[i]); err != nil && wasHealthy {
+			log.Printf("Worker %s is unhealthy: %v", workers[i].Name, err)
+		} else if err == nil && !wasHealthy {
+			log.Printf("Worker %s is healthy", workers[i].Name)
+		}
+	}
+}
+
+// WorkerPublicIP returns the address replacing $ip in connection info, falling back to PTA_DOCKER_WORKER_IP
+func WorkerPublicIP(workerID *uint) string {
+	if workerID != nil {
+		var worker models.Worker
+		if err := config.DB.Select("id, public_ip").First(&worker, *workerID).Error; err == nil && worker.PublicIP != "" {
+			return worker.PublicIP
+		}
+	}
+	return os.Getenv("PTA_DOCKER_WORKER_IP")
+}
+
+// StopWorkerDockerInstance stops a docker instance on the host it runs on
+func StopWorkerDockerInstance(workerID *uint, containerName string) error {
+	cli, err := DockerClientFor(workerID)
+	if err != nil {
+		return err
+	}
+	return StopDockerInstance(cli, containerName)
+}
+
+// StopWorkerComposeInstance stops a compose instance on the host it runs on
+func StopWorkerComposeInstance(workerID *uint, projectName string) error {
+	cli, err := DockerClientFor(workerID)
+	if err != nil {
+		return err
+	}
+	return StopComposeInstance(cli, projectName)
+}
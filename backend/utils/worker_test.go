@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/pwnthemall/pwnthemall/backend/models"
+)
+
+func TestHasWorkerLabels(t *testing.T) {
+	worker := &models.Worker{Labels: []string{"gpu", "eu"}}
+
+	for _, tc := range []struct {
+		required []string
+		want     bool
+	}{
+		{nil, true},
+		{[]string{"gpu"}, true},
+		{[]string{"eu", "gpu"}, true},
+		{[]string{"gpu", "arm"}, false},
+	} {
+		if got := hasWorkerLabels(worker, tc.required); got != tc.want {
+			t.Errorf("hasWorkerLabels(%v) = %v, want %v", tc.required, got, tc.want)
+		}
+	}
+
+	if hasWorkerLabels(&models.Worker{}, []string{"gpu"}) {
+		t.Error("a worker without labels must not match a required label")
+	}
+}
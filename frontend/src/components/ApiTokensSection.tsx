@@ -0,0 +1,207 @@
+import React, { useEffect, useState } from "react";
+import axios from "@/lib/axios";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Badge } from "@/components/ui/badge";
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from "@/components/ui/select";
+import {
+  AlertDialog,
+  AlertDialogAction,
+  AlertDialogCancel,
+  AlertDialogContent,
+  AlertDialogDescription,
+  AlertDialogFooter,
+  AlertDialogHeader,
+  AlertDialogTitle,
+} from "@/components/ui/alert-dialog";
+import { Copy, Trash2 } from "lucide-react";
+import { toast } from "sonner";
+import { useLanguage } from "@/context/LanguageContext";
+import { ApiToken, ApiTokenScope, CreatedApiToken } from "@/models/ApiToken";
+
+const EXPIRY_DAYS = ["7", "30", "90", "365", "never"] as const;
+
+interface ApiTokensSectionProps {
+  isAdmin: boolean;
+}
+
+export function ApiTokensSection({ isAdmin }: ApiTokensSectionProps) {
+  const { t } = useLanguage();
+  const [tokens, setTokens] = useState<ApiToken[]>([]);
+  const [name, setName] = useState("");
+  const [scope, setScope] = useState<ApiTokenScope>("read");
+  const [expiry, setExpiry] = useState<string>("30");
+  const [creating, setCreating] = useState(false);
+  const [createdToken, setCreatedToken] = useState<string | null>(null);
+  const [revoking, setRevoking] = useState<ApiToken | null>(null);
+
+  const fetchTokens = async () => {
+    try {
+      const res = await axios.get<ApiToken[]>("/api/me/tokens");
+      setTokens(res.data || []);
+    } catch {
+      setTokens([]);
+    }
+  };
+
+  useEffect(() => {
+    fetchTokens();
+  }, []);
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setCreating(true);
+    try {
+      const expiresAt = expiry === "never"
+        ? undefined
+        : new Date(Date.now() + Number(expiry) * 24 * 3600 * 1000).toISOString();
+      const res = await axios.post<CreatedApiToken>("/api/me/tokens", { name, scope, expiresAt });
+      setCreatedToken(res.data.token);
+      setName("");
+      fetchTokens();
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "api_token_create_failed"), { className: "bg-red-600 text-white" });
+    } finally {
+      setCreating(false);
+    }
+  };
+
+  const handleRevoke = async () => {
+    if (!revoking) return;
+    try {
+      await axios.delete(`/api/me/tokens/${revoking.id}`);
+      toast.success(t("api_token_revoked"));
+      fetchTokens();
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "api_token_revoke_failed"), { className: "bg-red-600 text-white" });
+    } finally {
+      setRevoking(null);
+    }
+  };
+
+  const copyToken = async () => {
+    if (!createdToken) return;
+    await navigator.clipboard.writeText(createdToken);
+    toast.success(t("api_token_copied"));
+  };
+
+  return (
+    <div className="space-y-4">
+      <div>
+        <h3 className="text-lg font-semibold">{t("api_tokens")}</h3>
+        <p className="text-sm text-muted-foreground">{t("api_tokens_description")}</p>
+      </div>
+
+      <form className="grid gap-2 sm:grid-cols-[1fr_auto_auto_auto]" onSubmit={handleCreate}>
+        <Input
+          value={name}
+          onChange={(e) => setName(e.target.value)}
+          placeholder={t("api_token_name")}
+          maxLength={64}
+          required
+          disabled={creating}
+        />
+        <Select value={scope} onValueChange={(value) => setScope(value as ApiTokenScope)}>
+          <SelectTrigger className="w-[130px]">
+            <SelectValue />
+          </SelectTrigger>
+          <SelectContent>
+            <SelectItem value="read">{t("api_token_scope_read")}</SelectItem>
+            <SelectItem value="submit">{t("api_token_scope_submit")}</SelectItem>
+            {isAdmin && <SelectItem value="admin">{t("api_token_scope_admin")}</SelectItem>}
+          </SelectContent>
+        </Select>
+        <Select value={expiry} onValueChange={setExpiry}>
+          <SelectTrigger className="w-[130px]">
+            <SelectValue />
+          </SelectTrigger>
+          <SelectContent>
+            {EXPIRY_DAYS.map((days) => (
+              <SelectItem key={days} value={days}>
+                {days === "never" ? t("api_token_no_expiry") : t("api_token_expires_in_days", { days })}
+              </SelectItem>
+            ))}
+          </SelectContent>
+        </Select>
+        <Button type="submit" disabled={creating || !name.trim()}>
+          {t("create")}
+        </Button>
+      </form>
+
+      {tokens.length === 0 ? (
+        <p className="text-sm text-muted-foreground">{t("api_tokens_empty")}</p>
+      ) : (
+        <ul className="divide-y rounded-md border">
+          {tokens.map((token) => (
+            <li key={token.id} className="flex items-center gap-3 p-3">
+              <div className="min-w-0 flex-1">
+                <div className="flex items-center gap-2">
+                  <span className="truncate font-medium">{token.name}</span>
+                  <Badge variant="secondary">{t(`api_token_scope_${token.scope}`)}</Badge>
+                </div>
+                <div className="text-xs text-muted-foreground">
+                  <code>{token.prefix}…</code>
+                  {" · "}
+                  {token.expiresAt
+                    ? t("api_token_expires_on", { date: new Date(token.expiresAt).toLocaleDateString() })
+                    : t("api_token_no_expiry")}
+                  {" · "}
+                  {token.lastUsedAt
+                    ? t("api_token_last_used", { date: new Date(token.lastUsedAt).toLocaleString() })
+                    : t("api_token_never_used")}
+                </div>
+              </div>
+              <Button variant="ghost" size="icon" onClick={() => setRevoking(token)} title={t("api_token_revoke")}>
+                <Trash2 className="h-4 w-4" />
+              </Button>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      <AlertDialog open={createdToken !== null} onOpenChange={(open) => !open && setCreatedToken(null)}>
+        <AlertDialogContent>
+          <AlertDialogHeader>
+            <AlertDialogTitle>{t("api_token_created")}</AlertDialogTitle>
+            <AlertDialogDescription>{t("api_token_created_description")}</AlertDialogDescription>
+          </AlertDialogHeader>
+          <div className="flex items-center gap-2">
+            <Input readOnly value={createdToken || ""} className="font-mono text-xs" />
+            <Button type="button" variant="outline" size="icon" onClick={copyToken}>
+              <Copy className="h-4 w-4" />
+            </Button>
+          </div>
+          <AlertDialogFooter>
+            <AlertDialogAction>{t("close")}</AlertDialogAction>
+          </AlertDialogFooter>
+        </AlertDialogContent>
+      </AlertDialog>
+
+      <AlertDialog open={revoking !== null} onOpenChange={(open) => !open && setRevoking(null)}>
+        <AlertDialogContent>
+          <AlertDialogHeader>
+            <AlertDialogTitle>{t("api_token_revoke")}</AlertDialogTitle>
+            <AlertDialogDescription>
+              {t("api_token_revoke_confirm", { name: revoking?.name || "" })}
+            </AlertDialogDescription>
+          </AlertDialogHeader>
+          <AlertDialogFooter>
+            <AlertDialogCancel>{t("cancel")}</AlertDialogCancel>
+            <AlertDialogAction
+              onClick={handleRevoke}
+              className="bg-destructive text-destructive-foreground hover:bg-destructive/90"
+            >
+              {t("api_token_revoke")}
+            </AlertDialogAction>
+          </AlertDialogFooter>
+        </AlertDialogContent>
+      </AlertDialog>
+    </div>
+  );
+}
@@ -21,12 +21,16 @@ interface LoginContentProps {
     }
     onChange: (e: React.ChangeEvent<HTMLInputElement>) => void
     onSubmit: (e: React.FormEvent) => void
+    localLoginEnabled: boolean
+    ssoProvider?: string
 }
 
 export default function LoginContent({
     form,
     onChange,
     onSubmit,
+    localLoginEnabled,
+    ssoProvider,
 }: LoginContentProps) {
     const { t } = useLanguage();
 
@@ -39,50 +43,62 @@ export default function LoginContent({
                         <CardTitle className="text-xl">{t('welcome_back')}</CardTitle>
                         <CardDescription>{t('enter_credentials')}</CardDescription>
                     </CardHeader>
-                    <CardContent>
-                        <form onSubmit={onSubmit} className="grid gap-4">
-                            <div className="grid gap-2">
-                                <Label htmlFor="username">{t('username_or_email')}</Label>
-                                <Input
-                                    id="username"
-                                    name="username"
-                                    value={form.username}
-                                    onChange={onChange}
-                                    placeholder="you@example.com"
-                                    required
-                                />
+                    <CardContent className="grid gap-4">
+                        {ssoProvider && (
+                            <Button asChild variant="outline" className="w-full">
+                                <a href="/api/oidc/login">{t('sign_in_with', { provider: ssoProvider })}</a>
+                            </Button>
+                        )}
+                        {ssoProvider && localLoginEnabled && (
+                            <div className="relative text-center text-sm after:absolute after:inset-0 after:top-1/2 after:z-0 after:flex after:items-center after:border-t after:border-border">
+                                <span className="relative z-10 bg-card px-2 text-muted-foreground">{t('or_continue_with')}</span>
                             </div>
-                            <div className="grid gap-2">
-                                <div className="flex items-center">
-                                    <Label htmlFor="password" >{t('password')}</Label>
-                                    <button
-                                        type="button"
-                                        tabIndex={-1}
-                                        className="ml-auto text-sm underline-offset-4 hover:underline bg-transparent border-0 cursor-pointer p-0"
-                                    >
-                                        {t('forgot_password')}
-                                    </button>
+                        )}
+                        {localLoginEnabled && (
+                            <form onSubmit={onSubmit} className="grid gap-4">
+                                <div className="grid gap-2">
+                                    <Label htmlFor="username">{t('username_or_email')}</Label>
+                                    <Input
+                                        id="username"
+                                        name="username"
+                                        value={form.username}
+                                        onChange={onChange}
+                                        placeholder="you@example.com"
+                                        required
+                                    />
                                 </div>
-                                <Input
-                                    id="password"
-                                    name="password"
-                                    type="password"
-                                    placeholder="***********"
-                                    value={form.password}
-                                    onChange={onChange}
-                                    required
-                                />
-                            </div>
-                            <Button type="submit" className="w-full">
-                                {t('login')}
-                            </Button>
-                            <p className="text-center text-sm text-muted-foreground">
-                                {t('dont_have_account')}{" "}
-                                <Link href="/register" className="underline underline-offset-4">
-                                    {t('sign_up')}
-                                </Link>
-                            </p>
-                        </form>
+                                <div className="grid gap-2">
+                                    <div className="flex items-center">
+                                        <Label htmlFor="password" >{t('password')}</Label>
+                                        <Link
+                                            href="/forgot-password"
+                                            tabIndex={-1}
+                                            className="ml-auto text-sm underline-offset-4 hover:underline"
+                                        >
+                                            {t('forgot_password')}
+                                        </Link>
+                                    </div>
+                                    <Input
+                                        id="password"
+                                        name="password"
+                                        type="password"
+                                        placeholder="***********"
+                                        value={form.password}
+                                        onChange={onChange}
+                                        required
+                                    />
+                                </div>
+                                <Button type="submit" className="w-full">
+                                    {t('login')}
+                                </Button>
+                                <p className="text-center text-sm text-muted-foreground">
+                                    {t('dont_have_account')}{" "}
+                                    <Link href="/register" className="underline underline-offset-4">
+                                        {t('sign_up')}
+                                    </Link>
+                                </p>
+                            </form>
+                        )}
                     </CardContent>
                 </Card>
             </div>
@@ -6,14 +6,20 @@ import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from '@
 import { Badge } from '@/components/ui/badge';
 import { Input } from '@/components/ui/input';
 import { Button } from '@/components/ui/button';
-import { Trophy, Medal, Award, Users, User, Search, ChevronLeft, ChevronRight, TrendingUp } from 'lucide-react';
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
+import { Trophy, Medal, Award, Users, User, Search, ChevronLeft, ChevronRight, TrendingUp, Snowflake } from 'lucide-react';
 import { useLanguage } from '@/context/LanguageContext';
 import { useUser } from '@/context/UserContext';
 import { IndividualLeaderboardEntry, TeamLeaderboardEntry } from '@/models/Leaderboard';
+import { Bracket } from '@/models/Bracket';
 import { cn } from '@/lib/utils';
 import { XAxis, YAxis, CartesianGrid, Tooltip, ResponsiveContainer, Area, AreaChart } from 'recharts';
 
-export default function ScoreboardContent() {
+interface ScoreboardContentProps {
+  frozen?: boolean;
+}
+
+export default function ScoreboardContent({ frozen = false }: ScoreboardContentProps) {
   const { t } = useLanguage();
   const { user } = useUser();
   
@@ -28,8 +34,19 @@ export default function ScoreboardContent() {
   const [chartLoading, setChartLoading] = useState(true);
   const [hiddenEntities, setHiddenEntities] = useState<Set<string>>(new Set());
   const [hoveredEntity, setHoveredEntity] = useState<string | null>(null);
+  const [brackets, setBrackets] = useState<Bracket[]>([]);
+  const [bracket, setBracket] = useState('all');
   const itemsPerPage = 25;
 
+  // Rankings of a single bracket use ?bracket=<id>
+  const bracketQuery = bracket === 'all' ? '' : `?bracket=${bracket}`;
+
+  useEffect(() => {
+    axios.get<Bracket[]>('/api/brackets')
+      .then((res) => setBrackets(res.data || []))
+      .catch(() => setBrackets([]));
+  }, []);
+
   // Toggle entity visibility in chart
   const toggleEntityVisibility = (entityName: string) => {
     setHiddenEntities(prev => {
@@ -49,21 +66,24 @@ export default function ScoreboardContent() {
     setHoveredEntity(null);
   }, [activeTab]);
 
+  // Refetch when the scoreboard gets frozen or revealed, or the bracket changes
   useEffect(() => {
     fetchLeaderboards();
-  }, []);
+    setIndividualPage(1);
+    setTeamPage(1);
+  }, [frozen, bracket]);
 
   // Fetch timeline data when active tab changes
   useEffect(() => {
     fetchTimelineData(activeTab);
-  }, [activeTab]);
+  }, [activeTab, frozen, bracket]);
 
   const fetchTimelineData = async (tab: string) => {
     setChartLoading(true);
     try {
       // Fetch appropriate timeline based on active tab
       const endpoint = tab === 'individual' ? '/api/users/timeline' : '/api/teams/timeline';
-      const response = await axios.get(endpoint);
+      const response = await axios.get(endpoint + bracketQuery);
       const data = response.data;
       // Format: { teams/users: [...], timeline: [...] }
       if (data && (data.teams || data.users) && data.timeline) {
@@ -84,8 +104,8 @@ export default function ScoreboardContent() {
     try {
       // Fetch both leaderboards in parallel
       const [teamsResponse, individualResponse] = await Promise.all([
-        axios.get('/api/teams/leaderboard'),
-        axios.get('/api/users/leaderboard')
+        axios.get('/api/teams/leaderboard' + bracketQuery),
+        axios.get('/api/users/leaderboard' + bracketQuery)
       ]);
       
       const teamsData = teamsResponse.data || [];
@@ -245,6 +265,13 @@ export default function ScoreboardContent() {
           </p>
         </div>
 
+        {frozen && user?.role !== 'admin' && (
+          <div className="flex items-center gap-2 rounded-md border border-cyan-200 dark:border-cyan-800 bg-cyan-50 dark:bg-cyan-950 p-3 text-sm text-cyan-800 dark:text-cyan-200">
+            <Snowflake className="h-4 w-4 shrink-0" />
+            {t('scoreboard_frozen_desc')}
+          </div>
+        )}
+
         {/* Timeline Chart - CTFd/TryHackMe Style */}
         <Card>
           <CardHeader>
@@ -379,14 +406,29 @@ export default function ScoreboardContent() {
                   </TabsTrigger>
                 </TabsList>
 
-                <div className="relative w-full sm:w-64">
-                  <Search className="absolute left-2 top-2.5 h-4 w-4 text-muted-foreground" />
-                  <Input
-                    placeholder={t('scoreboard.search') || 'Search...'}
-                    value={searchTerm}
-                    onChange={(e) => setSearchTerm(e.target.value)}
-                    className="pl-8"
-                  />
+                <div className="flex w-full sm:w-auto gap-2">
+                  {brackets.length > 0 && (
+                    <Select value={bracket} onValueChange={setBracket}>
+                      <SelectTrigger className="w-full sm:w-44">
+                        <SelectValue />
+                      </SelectTrigger>
+                      <SelectContent>
+                        <SelectItem value="all">{t('all_brackets') || 'All brackets'}</SelectItem>
+                        {brackets.map((b) => (
+                          <SelectItem key={b.id} value={String(b.id)}>{b.name}</SelectItem>
+                        ))}
+                      </SelectContent>
+                    </Select>
+                  )}
+                  <div className="relative w-full sm:w-64">
+                    <Search className="absolute left-2 top-2.5 h-4 w-4 text-muted-foreground" />
+                    <Input
+                      placeholder={t('scoreboard.search') || 'Search...'}
+                      value={searchTerm}
+                      onChange={(e) => setSearchTerm(e.target.value)}
+                      className="pl-8"
+                    />
+                  </div>
                 </div>
               </div>
 
@@ -0,0 +1,72 @@
+import React from "react";
+import { Button } from "@/components/ui/button";
+import { Badge } from "@/components/ui/badge";
+import { Trash2 } from "lucide-react";
+import { useLanguage } from "@/context/LanguageContext";
+import { Session } from "@/models/Session";
+
+// describeUserAgent shortens a user agent to its browser and platform
+function describeUserAgent(userAgent: string): string {
+  const browser = /Edg\//.test(userAgent) ? "Edge"
+    : /Firefox\//.test(userAgent) ? "Firefox"
+    : /Chrome\//.test(userAgent) ? "Chrome"
+    : /Safari\//.test(userAgent) ? "Safari"
+    : "";
+  const platform = /Android/.test(userAgent) ? "Android"
+    : /iPhone|iPad/.test(userAgent) ? "iOS"
+    : /Windows/.test(userAgent) ? "Windows"
+    : /Mac OS X/.test(userAgent) ? "macOS"
+    : /Linux/.test(userAgent) ? "Linux"
+    : "";
+  if (browser && platform) return `${browser} · ${platform}`;
+  return browser || platform || userAgent;
+}
+
+interface SessionListProps {
+  sessions: Session[];
+  onRevoke: (session: Session) => void;
+  disabled?: boolean;
+}
+
+export function SessionList({ sessions, onRevoke, disabled }: SessionListProps) {
+  const { t } = useLanguage();
+
+  if (sessions.length === 0) {
+    return <p className="text-sm text-muted-foreground">{t("sessions_empty")}</p>;
+  }
+
+  return (
+    <ul className="divide-y rounded-md border">
+      {sessions.map((session) => (
+        <li key={session.id} className="flex items-center gap-3 p-3">
+          <div className="min-w-0 flex-1">
+            <div className="flex items-center gap-2">
+              <span className="truncate font-medium" title={session.userAgent}>
+                {session.userAgent ? describeUserAgent(session.userAgent) : t("session_unknown_device")}
+              </span>
+              {session.current && <Badge variant="secondary">{t("session_current")}</Badge>}
+            </div>
+            <div className="text-xs text-muted-foreground">
+              {session.ipAddress}
+              {" · "}
+              {t("session_last_used", { date: new Date(session.lastUsedAt).toLocaleString() })}
+              {" · "}
+              {t("session_expires_on", { date: new Date(session.expiresAt).toLocaleDateString() })}
+            </div>
+          </div>
+          {!session.current && (
+            <Button
+              variant="ghost"
+              size="icon"
+              onClick={() => onRevoke(session)}
+              title={t("session_revoke")}
+              disabled={disabled}
+            >
+              <Trash2 className="h-4 w-4" />
+            </Button>
+          )}
+        </li>
+      ))}
+    </ul>
+  );
+}
@@ -0,0 +1,94 @@
+import React, { useEffect, useState } from "react";
+import axios from "@/lib/axios";
+import { Button } from "@/components/ui/button";
+import {
+  AlertDialog,
+  AlertDialogAction,
+  AlertDialogCancel,
+  AlertDialogContent,
+  AlertDialogDescription,
+  AlertDialogFooter,
+  AlertDialogHeader,
+  AlertDialogTitle,
+} from "@/components/ui/alert-dialog";
+import { toast } from "sonner";
+import { useLanguage } from "@/context/LanguageContext";
+import { Session } from "@/models/Session";
+import { SessionList } from "@/components/SessionList";
+
+export function SessionsSection() {
+  const { t } = useLanguage();
+  const [sessions, setSessions] = useState<Session[]>([]);
+  const [confirmLogoutAll, setConfirmLogoutAll] = useState(false);
+  const [submitting, setSubmitting] = useState(false);
+
+  const fetchSessions = async () => {
+    try {
+      const res = await axios.get<Session[]>("/api/me/sessions");
+      setSessions(res.data || []);
+    } catch {
+      setSessions([]);
+    }
+  };
+
+  useEffect(() => {
+    fetchSessions();
+  }, []);
+
+  const handleRevoke = async (session: Session) => {
+    setSubmitting(true);
+    try {
+      await axios.delete(`/api/me/sessions/${session.id}`);
+      toast.success(t("session_revoked_success"));
+      fetchSessions();
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "failed_to_revoke_session"), { className: "bg-red-600 text-white" });
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  const handleLogoutAll = async () => {
+    setSubmitting(true);
+    try {
+      await axios.post("/api/logout/all");
+      window.location.href = "/login";
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "failed_to_revoke_sessions"), { className: "bg-red-600 text-white" });
+      setSubmitting(false);
+    }
+  };
+
+  return (
+    <div className="space-y-4">
+      <div>
+        <h3 className="text-lg font-semibold">{t("sessions_title")}</h3>
+        <p className="text-sm text-muted-foreground">{t("sessions_description")}</p>
+      </div>
+
+      <SessionList sessions={sessions} onRevoke={handleRevoke} disabled={submitting} />
+
+      <Button type="button" variant="destructive" onClick={() => setConfirmLogoutAll(true)} disabled={submitting}>
+        {t("logout_everywhere")}
+      </Button>
+
+      <AlertDialog open={confirmLogoutAll} onOpenChange={setConfirmLogoutAll}>
+        <AlertDialogContent>
+          <AlertDialogHeader>
+            <AlertDialogTitle>{t("logout_everywhere")}</AlertDialogTitle>
+            <AlertDialogDescription>{t("logout_everywhere_confirm")}</AlertDialogDescription>
+          </AlertDialogHeader>
+          <AlertDialogFooter>
+            <AlertDialogCancel>{t("cancel")}</AlertDialogCancel>
+            <AlertDialogAction
+              onClick={handleLogoutAll}
+              className="bg-destructive text-destructive-foreground hover:bg-destructive/90"
+            >
+              {t("logout_everywhere")}
+            </AlertDialogAction>
+          </AlertDialogFooter>
+        </AlertDialogContent>
+      </AlertDialog>
+    </div>
+  );
+}
@@ -0,0 +1,115 @@
+import React from "react";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Copy, Download } from "lucide-react";
+import { toast } from "sonner";
+import { useLanguage } from "@/context/LanguageContext";
+import { TwoFactorSetup } from "@/models/TwoFactor";
+
+interface TwoFactorCodeInputProps {
+  value: string;
+  onChange: (value: string) => void;
+  disabled?: boolean;
+  allowRecoveryCode?: boolean;
+}
+
+export function TwoFactorCodeInput({ value, onChange, disabled, allowRecoveryCode }: TwoFactorCodeInputProps) {
+  const { t } = useLanguage();
+  return (
+    <div className="grid gap-2">
+      <Label htmlFor="two-factor-code">{allowRecoveryCode ? t("two_factor_code_or_recovery") : t("two_factor_code")}</Label>
+      <Input
+        id="two-factor-code"
+        name="code"
+        value={value}
+        onChange={(e) => onChange(e.target.value)}
+        placeholder={allowRecoveryCode ? "123456 / xxxxx-xxxxx" : "123456"}
+        inputMode={allowRecoveryCode ? "text" : "numeric"}
+        autoComplete="one-time-code"
+        maxLength={32}
+        autoFocus
+        required
+        disabled={disabled}
+      />
+    </div>
+  );
+}
+
+interface TwoFactorSetupFormProps {
+  setup: TwoFactorSetup;
+  code: string;
+  onCodeChange: (value: string) => void;
+  onSubmit: (e: React.FormEvent) => void;
+  submitting: boolean;
+}
+
+export function TwoFactorSetupForm({ setup, code, onCodeChange, onSubmit, submitting }: TwoFactorSetupFormProps) {
+  const { t } = useLanguage();
+  return (
+    <form className="grid gap-4" onSubmit={onSubmit}>
+      <p className="text-sm text-muted-foreground">{t("two_factor_scan")}</p>
+      {setup.qrCode && (
+        <img
+          src={`data:image/svg+xml;utf8,${encodeURIComponent(setup.qrCode)}`}
+          alt={t("two_factor_qr_code")}
+          className="mx-auto h-48 w-48 rounded-md border bg-white"
+        />
+      )}
+      <div className="text-sm">
+        <p className="text-muted-foreground">{t("two_factor_manual_entry")}</p>
+        <code className="mt-1 block break-all rounded-md bg-muted p-2 font-mono">{setup.secret}</code>
+        <a href={setup.uri} className="mt-1 inline-block underline underline-offset-4">
+          {t("two_factor_open_app")}
+        </a>
+      </div>
+      <TwoFactorCodeInput value={code} onChange={onCodeChange} disabled={submitting} />
+      <Button type="submit" disabled={submitting || !code.trim()}>
+        {t("two_factor_enable")}
+      </Button>
+    </form>
+  );
+}
+
+interface RecoveryCodesListProps {
+  codes: string[];
+}
+
+export function RecoveryCodesList({ codes }: RecoveryCodesListProps) {
+  const { t } = useLanguage();
+
+  const copyCodes = async () => {
+    await navigator.clipboard.writeText(codes.join("\n"));
+    toast.success(t("recovery_codes_copied"));
+  };
+
+  const downloadCodes = () => {
+    const url = URL.createObjectURL(new Blob([codes.join("\n") + "\n"], { type: "text/plain" }));
+    const link = document.createElement("a");
+    link.href = url;
+    link.download = "recovery-codes.txt";
+    link.click();
+    URL.revokeObjectURL(url);
+  };
+
+  return (
+    <div className="grid gap-3">
+      <p className="text-sm text-muted-foreground">{t("recovery_codes_description")}</p>
+      <ul className="grid grid-cols-2 gap-2 rounded-md border p-3 font-mono text-sm">
+        {codes.map((code) => (
+          <li key={code}>{code}</li>
+        ))}
+      </ul>
+      <div className="flex gap-2">
+        <Button type="button" variant="outline" size="sm" onClick={copyCodes}>
+          <Copy className="mr-1 h-4 w-4" />
+          {t("recovery_codes_copy")}
+        </Button>
+        <Button type="button" variant="outline" size="sm" onClick={downloadCodes}>
+          <Download className="mr-1 h-4 w-4" />
+          {t("download")}
+        </Button>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,120 @@
+import React, { useEffect, useState } from "react";
+import axios from "@/lib/axios";
+import {
+  Card,
+  CardContent,
+  CardDescription,
+  CardHeader,
+  CardTitle,
+} from "@/components/ui/card";
+import { Button } from "@/components/ui/button";
+import { toast } from "sonner";
+import { useLanguage } from "@/context/LanguageContext";
+import { TwoFactorSetup } from "@/models/TwoFactor";
+import { RecoveryCodesList, TwoFactorCodeInput, TwoFactorSetupForm } from "@/components/TwoFactorEnrolment";
+
+interface TwoFactorLoginStepProps {
+  // The account must enrol first, when two-factor authentication is required for its role
+  setup: boolean;
+  onSuccess: () => void;
+  onCancel: () => void;
+}
+
+export default function TwoFactorLoginStep({ setup, onSuccess, onCancel }: TwoFactorLoginStepProps) {
+  const { t } = useLanguage();
+  const [code, setCode] = useState("");
+  const [submitting, setSubmitting] = useState(false);
+  const [enrolment, setEnrolment] = useState<TwoFactorSetup | null>(null);
+  const [recoveryCodes, setRecoveryCodes] = useState<string[] | null>(null);
+
+  const showError = (err: any, fallback: string) => {
+    const errorKey = err?.response?.data?.error || fallback;
+    toast.error(t(errorKey), { className: "bg-red-600 text-white" });
+    if (errorKey === "2fa_login_expired" || errorKey === "banned") {
+      onCancel();
+    }
+  };
+
+  useEffect(() => {
+    if (!setup) return;
+    axios.post<TwoFactorSetup>("/api/login/2fa/setup")
+      .then((res) => setEnrolment(res.data))
+      .catch((err) => showError(err, "failed_to_setup_2fa"));
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [setup]);
+
+  const handleVerify = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSubmitting(true);
+    try {
+      await axios.post("/api/login/2fa", { code });
+      onSuccess();
+    } catch (err: any) {
+      setCode("");
+      showError(err, "invalid_2fa_code");
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  const handleEnable = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSubmitting(true);
+    try {
+      const res = await axios.post<{ recoveryCodes: string[] }>("/api/login/2fa/enable", { code });
+      setRecoveryCodes(res.data.recoveryCodes);
+    } catch (err: any) {
+      setCode("");
+      showError(err, "invalid_2fa_code");
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  return (
+    <div className="bg-muted flex min-h-screen flex-col items-center justify-center px-4 py-8">
+      <div className="w-full max-w-sm">
+        <Card>
+          <CardHeader className="text-center">
+            <CardTitle className="text-xl">{setup ? t("two_factor_setup_title") : t("two_factor_title")}</CardTitle>
+            <CardDescription>
+              {setup ? t("two_factor_setup_required") : t("two_factor_login_description")}
+            </CardDescription>
+          </CardHeader>
+          <CardContent className="grid gap-4">
+            {recoveryCodes ? (
+              <>
+                <RecoveryCodesList codes={recoveryCodes} />
+                <Button type="button" onClick={onSuccess}>
+                  {t("recovery_codes_saved")}
+                </Button>
+              </>
+            ) : setup ? (
+              enrolment && (
+                <TwoFactorSetupForm
+                  setup={enrolment}
+                  code={code}
+                  onCodeChange={setCode}
+                  onSubmit={handleEnable}
+                  submitting={submitting}
+                />
+              )
+            ) : (
+              <form className="grid gap-4" onSubmit={handleVerify}>
+                <TwoFactorCodeInput value={code} onChange={setCode} disabled={submitting} allowRecoveryCode />
+                <Button type="submit" className="w-full" disabled={submitting || !code.trim()}>
+                  {t("two_factor_verify")}
+                </Button>
+              </form>
+            )}
+            {!recoveryCodes && (
+              <Button type="button" variant="ghost" onClick={onCancel}>
+                {t("back_to_login")}
+              </Button>
+            )}
+          </CardContent>
+        </Card>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,190 @@
+import React, { useEffect, useState } from "react";
+import axios from "@/lib/axios";
+import { Button } from "@/components/ui/button";
+import { Badge } from "@/components/ui/badge";
+import {
+  AlertDialog,
+  AlertDialogCancel,
+  AlertDialogContent,
+  AlertDialogDescription,
+  AlertDialogFooter,
+  AlertDialogHeader,
+  AlertDialogTitle,
+} from "@/components/ui/alert-dialog";
+import { toast } from "sonner";
+import { useLanguage } from "@/context/LanguageContext";
+import { TwoFactorSetup, TwoFactorStatus } from "@/models/TwoFactor";
+import { RecoveryCodesList, TwoFactorCodeInput, TwoFactorSetupForm } from "@/components/TwoFactorEnrolment";
+
+// Actions asking for a current code before running
+type ConfirmAction = "disable" | "recovery_codes";
+
+export function TwoFactorSection() {
+  const { t } = useLanguage();
+  const [status, setStatus] = useState<TwoFactorStatus | null>(null);
+  const [enrolment, setEnrolment] = useState<TwoFactorSetup | null>(null);
+  const [recoveryCodes, setRecoveryCodes] = useState<string[] | null>(null);
+  const [confirming, setConfirming] = useState<ConfirmAction | null>(null);
+  const [code, setCode] = useState("");
+  const [submitting, setSubmitting] = useState(false);
+
+  const fetchStatus = async () => {
+    try {
+      const res = await axios.get<TwoFactorStatus>("/api/me/2fa");
+      setStatus(res.data);
+    } catch {
+      setStatus(null);
+    }
+  };
+
+  useEffect(() => {
+    fetchStatus();
+  }, []);
+
+  const showError = (err: any, fallback: string) => {
+    toast.error(t(err?.response?.data?.error || fallback), { className: "bg-red-600 text-white" });
+  };
+
+  const handleSetup = async () => {
+    setSubmitting(true);
+    try {
+      const res = await axios.post<TwoFactorSetup>("/api/me/2fa/setup");
+      setEnrolment(res.data);
+      setCode("");
+    } catch (err: any) {
+      showError(err, "failed_to_setup_2fa");
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  const handleEnable = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSubmitting(true);
+    try {
+      const res = await axios.post<{ recoveryCodes: string[] }>("/api/me/2fa/enable", { code });
+      setEnrolment(null);
+      setRecoveryCodes(res.data.recoveryCodes);
+      toast.success(t("two_factor_enabled"));
+      fetchStatus();
+    } catch (err: any) {
+      showError(err, "invalid_2fa_code");
+    } finally {
+      setCode("");
+      setSubmitting(false);
+    }
+  };
+
+  const handleConfirm = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!confirming) return;
+    setSubmitting(true);
+    try {
+      if (confirming === "disable") {
+        await axios.post("/api/me/2fa/disable", { code });
+        setRecoveryCodes(null);
+        toast.success(t("two_factor_disabled"));
+      } else {
+        const res = await axios.post<{ recoveryCodes: string[] }>("/api/me/2fa/recovery-codes", { code });
+        setRecoveryCodes(res.data.recoveryCodes);
+      }
+      setConfirming(null);
+      fetchStatus();
+    } catch (err: any) {
+      showError(err, "invalid_2fa_code");
+    } finally {
+      setCode("");
+      setSubmitting(false);
+    }
+  };
+
+  if (!status) return null;
+
+  return (
+    <div className="space-y-4 max-w-md">
+      <div>
+        <h3 className="flex items-center gap-2 text-lg font-semibold">
+          {t("two_factor_title")}
+          <Badge variant={status.enabled ? "default" : "outline"}>
+            {status.enabled ? t("two_factor_on") : t("two_factor_off")}
+          </Badge>
+        </h3>
+        <p className="text-sm text-muted-foreground">{t("two_factor_description")}</p>
+      </div>
+
+      {recoveryCodes && (
+        <>
+          <RecoveryCodesList codes={recoveryCodes} />
+          <Button type="button" variant="outline" onClick={() => setRecoveryCodes(null)}>
+            {t("recovery_codes_saved")}
+          </Button>
+        </>
+      )}
+
+      {!status.enabled && !enrolment && (
+        <Button type="button" onClick={handleSetup} disabled={submitting}>
+          {t("two_factor_enable")}
+        </Button>
+      )}
+
+      {!status.enabled && enrolment && (
+        <TwoFactorSetupForm
+          setup={enrolment}
+          code={code}
+          onCodeChange={setCode}
+          onSubmit={handleEnable}
+          submitting={submitting}
+        />
+      )}
+
+      {status.enabled && !recoveryCodes && (
+        <>
+          <p className="text-sm text-muted-foreground">
+            {t("recovery_codes_left", { count: status.recoveryCodesLeft })}
+          </p>
+          <div className="flex flex-wrap gap-2">
+            <Button type="button" variant="outline" onClick={() => setConfirming("recovery_codes")}>
+              {t("recovery_codes_regenerate")}
+            </Button>
+            <Button
+              type="button"
+              variant="destructive"
+              onClick={() => setConfirming("disable")}
+              disabled={status.required}
+              title={status.required ? t("2fa_required") : undefined}
+            >
+              {t("two_factor_disable")}
+            </Button>
+          </div>
+          {status.required && <p className="text-sm text-muted-foreground">{t("2fa_required")}</p>}
+        </>
+      )}
+
+      <AlertDialog open={!!confirming} onOpenChange={(open) => !open && setConfirming(null)}>
+        <AlertDialogContent>
+          <form className="grid gap-4" onSubmit={handleConfirm}>
+            <AlertDialogHeader>
+              <AlertDialogTitle>
+                {confirming === "disable" ? t("two_factor_disable") : t("recovery_codes_regenerate")}
+              </AlertDialogTitle>
+              <AlertDialogDescription>
+                {confirming === "disable" ? t("two_factor_disable_confirm") : t("recovery_codes_regenerate_confirm")}
+              </AlertDialogDescription>
+            </AlertDialogHeader>
+            <TwoFactorCodeInput value={code} onChange={setCode} disabled={submitting} allowRecoveryCode />
+            <AlertDialogFooter>
+              <AlertDialogCancel type="button">{t("cancel")}</AlertDialogCancel>
+              <Button
+                type="submit"
+                variant={confirming === "disable" ? "destructive" : "default"}
+                disabled={submitting || !code.trim()}
+              >
+                {t("confirm")}
+              </Button>
+            </AlertDialogFooter>
+          </form>
+        </AlertDialogContent>
+      </AlertDialog>
+    </div>
+  );
+}
@@ -1,13 +1,26 @@
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { Badge } from "@/components/ui/badge";
-import { Clock, Calendar, PlayCircle, StopCircle } from "lucide-react";
+import { Button } from "@/components/ui/button";
+import { Clock, Calendar, PlayCircle, StopCircle, Snowflake } from "lucide-react";
 import { useCTFStatus } from "@/hooks/use-ctf-status";
 import { useLanguage } from "@/context/LanguageContext";
+import axios from "@/lib/axios";
+import { toast } from "sonner";
 
 export default function CTFStatusOverview() {
-  const { ctfStatus, loading } = useCTFStatus();
+  const { ctfStatus, loading, refreshStatus } = useCTFStatus();
   const { t } = useLanguage();
 
+  const revealScoreboard = async () => {
+    try {
+      await axios.post("/api/admin/scoreboard/reveal");
+      toast.success(t("scoreboard_revealed") || "Scoreboard revealed");
+      refreshStatus();
+    } catch (err: any) {
+      toast.error(t(err.response?.data?.error) || "Failed to reveal scoreboard");
+    }
+  };
+
   const getStatusColor = (status: string) => {
     switch (status) {
       case "not_started":
@@ -139,6 +152,18 @@ export default function CTFStatusOverview() {
           {getStatusDescription(ctfStatus.status)}
         </p>
 
+        {ctfStatus.scoreboard_frozen && (
+          <div className="flex items-center justify-between gap-2 pt-1 border-t">
+            <span className="flex items-center gap-1 text-xs text-cyan-700 dark:text-cyan-300">
+              <Snowflake className="h-3 w-3" />
+              {t("scoreboard_frozen") || "Scoreboard frozen"}
+            </span>
+            <Button size="sm" variant="outline" className="h-6 text-xs" onClick={revealScoreboard}>
+              {t("reveal_scoreboard") || "Reveal scoreboard"}
+            </Button>
+          </div>
+        )}
+
         {ctfStatus.status !== "no_timing" && (
           <div className="text-xs text-muted-foreground pt-1 border-t">
             <p>
@@ -31,6 +31,7 @@ interface DecayFormula {
   type: string
   step: number
   minPoints: number
+  interval?: number
 }
 
 interface Hint {
@@ -167,7 +168,9 @@ export default function ChallengeAdminForm({ challenge, onClose }: ChallengeAdmi
       ).map((formula: any) => ({
         id: formula.id,
         name: formula.name,
+        type: formula.type,
         step: formula.step || 10,
+        interval: formula.interval || 0,
         minPoints: formula.minPoints || 10,
         maxDecay: formula.maxDecay || 90
       }))
@@ -661,7 +664,9 @@ export default function ChallengeAdminForm({ challenge, onClose }: ChallengeAdmi
                       <SelectItem key={formula.id} value={formula.id.toString()}>
                         {formula.type === 'fixed' 
                           ? formula.name 
-                          : `${formula.name} - Step: ${formula.step}, Min: ${formula.minPoints}`
+                          : formula.type === 'time'
+                            ? `${formula.name} - Step: ${formula.step}/${formula.interval}min, Min: ${formula.minPoints}`
+                            : `${formula.name} - Step: ${formula.step}, Min: ${formula.minPoints}`
                         }
                       </SelectItem>
                     ))}
@@ -7,8 +7,10 @@ import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/com
 import { Badge } from "@/components/ui/badge"
 import { Input } from "@/components/ui/input"
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select"
-import { Edit, Plus, Search, ArrowUpDown } from "lucide-react"
+import { Edit, Plus, Search, ArrowUpDown, Trash2 } from "lucide-react"
 import ChallengeAdminForm from "./ChallengeAdminForm"
+import axios from "@/lib/axios"
+import { toast } from "sonner"
 import {
   Dialog,
   DialogContent,
@@ -16,6 +18,16 @@ import {
   DialogHeader,
   DialogTitle,
 } from "@/components/ui/dialog"
+import {
+  AlertDialog,
+  AlertDialogAction,
+  AlertDialogCancel,
+  AlertDialogContent,
+  AlertDialogDescription,
+  AlertDialogFooter,
+  AlertDialogHeader,
+  AlertDialogTitle,
+} from "@/components/ui/alert-dialog"
 
 interface ChallengesContentProps {
   challenges: Challenge[]
@@ -26,6 +38,7 @@ export default function ChallengesContent({ challenges, onRefresh }: ChallengesC
   const { t } = useLanguage()
   const [selectedChallenge, setSelectedChallenge] = useState<Challenge | null>(null)
   const [isDialogOpen, setIsDialogOpen] = useState(false)
+  const [purging, setPurging] = useState<Challenge | null>(null)
   const [searchTerm, setSearchTerm] = useState("")
   const [sortBy, setSortBy] = useState("name")
   const [sortOrder, setSortOrder] = useState<"asc" | "desc">("asc")
@@ -64,7 +77,8 @@ export default function ChallengesContent({ challenges, onRefresh }: ChallengesC
       // Status filter
       const matchesStatus = filterStatus === "all" || 
         (filterStatus === "visible" && !challenge.hidden) ||
-        (filterStatus === "hidden" && challenge.hidden)
+        (filterStatus === "hidden" && challenge.hidden) ||
+        (filterStatus === "archived" && !!challenge.archivedAt)
 
       return matchesSearch && matchesCategory && matchesDifficulty && matchesStatus
     })
@@ -168,6 +182,19 @@ export default function ChallengesContent({ challenges, onRefresh }: ChallengesC
     onRefresh()
   }
 
+  const handlePurge = async () => {
+    if (!purging) return
+    try {
+      await axios.delete(`/api/admin/challenges/${purging.id}`)
+      toast.success(t('admin_challenges.challenge_purged'))
+      onRefresh()
+    } catch (err: any) {
+      toast.error(t(err.response?.data?.error) || t('admin_challenges.challenge_purge_failed'))
+    } finally {
+      setPurging(null)
+    }
+  }
+
   const getDifficultyColor = (difficulty: string) => {
     switch (difficulty?.toLowerCase()) {
       case "easy":
@@ -256,6 +283,7 @@ export default function ChallengesContent({ challenges, onRefresh }: ChallengesC
                     <SelectItem value="all">{t('admin_challenges.all_status')}</SelectItem>
                     <SelectItem value="visible">{t('admin_challenges.visible')}</SelectItem>
                     <SelectItem value="hidden">{t('admin_challenges.hidden')}</SelectItem>
+                    <SelectItem value="archived">{t('admin_challenges.archived')}</SelectItem>
                   </SelectContent>
                 </Select>
 
@@ -398,7 +426,11 @@ export default function ChallengesContent({ challenges, onRefresh }: ChallengesC
                         )}
                       </td>
                       <td className="w-[100px] px-3 py-2 align-middle">
-                        {challenge.id >= 0 ? (
+                        {challenge.id >= 0 && challenge.archivedAt ? (
+                          <Badge className="bg-orange-100 text-orange-800">
+                            {t('admin_challenges.archived')}
+                          </Badge>
+                        ) : challenge.id >= 0 ? (
                           <Badge className={getStatusColor(challenge.hidden ?? false)}>
                             {challenge.hidden ? t('admin_challenges.hidden') : t('admin_challenges.visible')}
                           </Badge>
@@ -408,13 +440,25 @@ export default function ChallengesContent({ challenges, onRefresh }: ChallengesC
                       </td>
                       <td className="w-[100px] px-3 py-2 align-middle">
                         {challenge.id >= 0 ? (
-                          <Button
-                            variant="ghost"
-                            size="sm"
-                            onClick={() => handleEdit(challenge)}
-                          >
-                            <Edit className="h-4 w-4" />
-                          </Button>
+                          <div className="flex">
+                            <Button
+                              variant="ghost"
+                              size="sm"
+                              onClick={() => handleEdit(challenge)}
+                            >
+                              <Edit className="h-4 w-4" />
+                            </Button>
+                            {challenge.archivedAt && (
+                              <Button
+                                variant="ghost"
+                                size="sm"
+                                title={t('admin_challenges.purge_challenge')}
+                                onClick={() => setPurging(challenge)}
+                              >
+                                <Trash2 className="h-4 w-4 text-destructive" />
+                              </Button>
+                            )}
+                          </div>
                         ) : (
                           <div className="h-[32px]">&nbsp;</div>
                         )}
@@ -477,6 +521,25 @@ export default function ChallengesContent({ challenges, onRefresh }: ChallengesC
             )}
           </DialogContent>
         </Dialog>
+
+        <AlertDialog open={!!purging} onOpenChange={() => setPurging(null)}>
+          <AlertDialogContent>
+            <AlertDialogHeader>
+              <AlertDialogTitle>{t('admin_challenges.confirm_purge_challenge')}</AlertDialogTitle>
+              <AlertDialogDescription>
+                {t('admin_challenges.confirm_purge_challenge_description')}
+                <br />
+                <span className="font-semibold">{purging?.name}</span>
+              </AlertDialogDescription>
+            </AlertDialogHeader>
+            <AlertDialogFooter>
+              <AlertDialogCancel>{t('cancel')}</AlertDialogCancel>
+              <AlertDialogAction onClick={handlePurge} className="bg-destructive text-destructive-foreground hover:bg-destructive/90">
+                {t('admin_challenges.purge_challenge')}
+              </AlertDialogAction>
+            </AlertDialogFooter>
+          </AlertDialogContent>
+        </AlertDialog>
       </div>
     </>
   )
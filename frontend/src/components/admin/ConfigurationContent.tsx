@@ -25,6 +25,7 @@ import {
 } from "@/components/ui/alert-dialog";
 import ConfigurationForm from "./ConfigurationForm";
 import CTFStatusOverview from "./CTFStatusOverview";
+import DockerConfigForm from "./DockerConfigForm";
 import { Config, ConfigFormData } from "@/models/Config";
 import { useLanguage } from "@/context/LanguageContext";
 import { useSiteConfig } from "@/context/SiteConfigContext";
@@ -257,6 +258,10 @@ export default function ConfigurationContent({ configs, onRefresh }: Configurati
           <CTFStatusOverview />
         </div>
 
+        <div className="mb-6">
+          <DockerConfigForm />
+        </div>
+
         <div className="mb-4 flex items-center justify-between">
           <h1 className="text-3xl font-bold">{t("configuration")}</h1>
           <div className="flex items-center gap-2">
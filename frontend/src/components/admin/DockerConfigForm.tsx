@@ -0,0 +1,89 @@
+import { useEffect, useState } from "react";
+import axios from "@/lib/axios";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { DockerConfig } from "@/models/Config";
+import { useLanguage } from "@/context/LanguageContext";
+import { toast } from "sonner";
+
+type LifetimeField =
+  | "instanceTimeout"
+  | "instanceCooldownSeconds"
+  | "instanceExtensionMinutes"
+  | "instanceMaxExtensions"
+  | "instanceMaxLifetime";
+
+const fields: { key: LifetimeField; label: string; help: string }[] = [
+  { key: "instanceTimeout", label: "instance_timeout_minutes", help: "instance_timeout_help" },
+  { key: "instanceCooldownSeconds", label: "instance_cooldown_seconds", help: "instance_cooldown_help" },
+  { key: "instanceExtensionMinutes", label: "instance_extension_minutes", help: "instance_extension_minutes_help" },
+  { key: "instanceMaxExtensions", label: "instance_max_extensions", help: "instance_max_extensions_help" },
+  { key: "instanceMaxLifetime", label: "instance_max_lifetime_minutes", help: "instance_max_lifetime_help" },
+];
+
+export default function DockerConfigForm() {
+  const { t } = useLanguage();
+  const [dockerConfig, setDockerConfig] = useState<DockerConfig | null>(null);
+  const [saving, setSaving] = useState(false);
+
+  useEffect(() => {
+    axios
+      .get<DockerConfig>("/api/docker-config")
+      .then((res) => setDockerConfig(res.data))
+      .catch(() => setDockerConfig(null));
+  }, []);
+
+  if (!dockerConfig) return null;
+
+  const handleChange = (key: LifetimeField, value: string) => {
+    const parsed = Number.parseInt(value, 10);
+    setDockerConfig({ ...dockerConfig, [key]: Number.isNaN(parsed) ? 0 : Math.max(0, parsed) });
+  };
+
+  // The endpoint replaces the whole configuration, so the other docker settings are sent back untouched
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSaving(true);
+    try {
+      await axios.put("/api/docker-config", dockerConfig);
+      toast.success(t("docker_config_updated_success"));
+    } catch (err: any) {
+      toast.error(t(err.response?.data?.error) || t("docker_config_update_failed"));
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle>{t("instance_lifetime_settings")}</CardTitle>
+        <CardDescription>{t("instance_lifetime_settings_description")}</CardDescription>
+      </CardHeader>
+      <CardContent>
+        <form onSubmit={handleSubmit} className="space-y-4">
+          <div className="grid gap-4 md:grid-cols-2 lg:grid-cols-3">
+            {fields.map(({ key, label, help }) => (
+              <div key={key} className="space-y-1">
+                <Label htmlFor={key}>{t(label)}</Label>
+                <Input
+                  id={key}
+                  type="number"
+                  min={0}
+                  value={dockerConfig[key]}
+                  onChange={(e) => handleChange(key, e.target.value)}
+                />
+                <p className="text-xs text-muted-foreground">{t(help)}</p>
+              </div>
+            ))}
+          </div>
+          <Button type="submit" size="sm" disabled={saving}>
+            {saving ? t("saving") : t("save")}
+          </Button>
+        </form>
+      </CardContent>
+    </Card>
+  );
+}
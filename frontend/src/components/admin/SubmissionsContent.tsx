@@ -6,6 +6,8 @@ import { Button } from "@/components/ui/button"
 import { Input } from "@/components/ui/input"
 import { Badge } from "@/components/ui/badge"
 import { X, ChevronsLeft, ChevronLeft, ChevronRight, ChevronsRight } from "lucide-react"
+import SuspiciousActivityList from "@/components/admin/SuspiciousActivityList"
+import { SuspiciousActivity } from "@/models/SuspiciousActivity"
 
 interface Submission {
   id: number
@@ -25,10 +27,13 @@ interface Submission {
 
 interface SubmissionsContentProps {
   readonly submissions: Submission[]
+  readonly suspiciousActivities: SuspiciousActivity[]
+  readonly showDismissed: boolean
+  readonly onShowDismissedChange: (show: boolean) => void
   readonly onRefresh: () => void
 }
 
-export default function SubmissionsContent({ submissions, onRefresh }: SubmissionsContentProps) {
+export default function SubmissionsContent({ submissions, suspiciousActivities, showDismissed, onShowDismissedChange, onRefresh }: SubmissionsContentProps) {
   const { t } = useLanguage()
   const { getSiteName } = useSiteConfig()
   const [userFilter, setUserFilter] = useState("")
@@ -98,6 +103,13 @@ export default function SubmissionsContent({ submissions, onRefresh }: Submissio
           </div>
         </div>
 
+        <SuspiciousActivityList
+          activities={suspiciousActivities}
+          showDismissed={showDismissed}
+          onShowDismissedChange={onShowDismissedChange}
+          onRefresh={onRefresh}
+        />
+
         {/* Filters */}
         <div className="mb-4 flex flex-wrap gap-2 items-end bg-card p-4 rounded-lg border">
           <div className="flex-1 min-w-[200px]">
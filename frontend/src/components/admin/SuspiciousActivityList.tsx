@@ -0,0 +1,98 @@
+import { useState } from "react"
+import axios from "@/lib/axios"
+import { toast } from "sonner"
+import { ShieldAlert, Check } from "lucide-react"
+import { useLanguage } from "@/context/LanguageContext"
+import { Button } from "@/components/ui/button"
+import { Badge } from "@/components/ui/badge"
+import { Label } from "@/components/ui/label"
+import { Switch } from "@/components/ui/switch"
+import { SuspiciousActivity } from "@/models/SuspiciousActivity"
+
+interface SuspiciousActivityListProps {
+  readonly activities: SuspiciousActivity[]
+  readonly showDismissed: boolean
+  readonly onShowDismissedChange: (show: boolean) => void
+  readonly onRefresh: () => void
+}
+
+export default function SuspiciousActivityList({ activities, showDismissed, onShowDismissedChange, onRefresh }: SuspiciousActivityListProps) {
+  const { t } = useLanguage()
+  const [dismissing, setDismissing] = useState<number | null>(null)
+
+  const handleDismiss = async (activity: SuspiciousActivity) => {
+    setDismissing(activity.id)
+    try {
+      await axios.put(`/api/admin/submissions/suspicious/${activity.id}/dismiss`)
+      toast.success(t("suspicious_activity_dismissed_success"))
+      onRefresh()
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "failed_to_update_suspicious_activity"), { className: "bg-red-600 text-white" })
+    } finally {
+      setDismissing(null)
+    }
+  }
+
+  const pendingCount = activities.filter((a) => a.status === "pending").length
+
+  return (
+    <div className="mb-4 bg-card p-4 rounded-lg border">
+      <div className="mb-3 flex items-center justify-between gap-2">
+        <div className="flex items-center gap-2">
+          <ShieldAlert className={pendingCount > 0 ? "h-5 w-5 text-destructive" : "h-5 w-5 text-muted-foreground"} />
+          <h2 className="text-lg font-semibold">{t("suspicious_activity_title")}</h2>
+          {pendingCount > 0 && <Badge variant="destructive">{pendingCount}</Badge>}
+        </div>
+        <div className="flex items-center gap-2">
+          <Label htmlFor="show-dismissed-alerts" className="text-sm">{t("suspicious_activity_show_dismissed")}</Label>
+          <Switch
+            id="show-dismissed-alerts"
+            checked={showDismissed}
+            onCheckedChange={(checked: boolean) => onShowDismissedChange(checked)}
+          />
+        </div>
+      </div>
+      <p className="mb-3 text-sm text-muted-foreground">{t("suspicious_activity_description")}</p>
+
+      {activities.length === 0 ? (
+        <p className="text-sm text-muted-foreground">{t("suspicious_activity_empty")}</p>
+      ) : (
+        <ul className="max-h-[300px] overflow-y-auto divide-y rounded-md border bg-background">
+          {activities.map((activity) => (
+            <li key={activity.id} className="flex items-center gap-3 p-3">
+              <div className="min-w-0 flex-1">
+                <div className="flex flex-wrap items-center gap-2">
+                  <span className="font-medium">
+                    {t("suspicious_activity_flag_of", {
+                      team: activity.team?.name || "-",
+                      source: activity.sourceTeam?.name || "-",
+                      challenge: activity.challenge || "-",
+                    })}
+                  </span>
+                  {activity.autoBanned && <Badge variant="destructive">{t("suspicious_activity_auto_banned")}</Badge>}
+                  {activity.status === "dismissed" && <Badge variant="secondary">{t("suspicious_activity_dismissed")}</Badge>}
+                </div>
+                <div className="text-xs text-muted-foreground">
+                  {activity.user?.username || "-"}
+                  {" · "}
+                  {new Date(activity.createdAt).toLocaleString()}
+                </div>
+              </div>
+              {activity.status === "pending" && (
+                <Button
+                  variant="outline"
+                  size="sm"
+                  onClick={() => handleDismiss(activity)}
+                  disabled={dismissing === activity.id}
+                >
+                  <Check className="mr-1 h-4 w-4" />
+                  {t("suspicious_activity_dismiss")}
+                </Button>
+              )}
+            </li>
+          ))}
+        </ul>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,98 @@
+import { useEffect, useState } from "react"
+import axios from "@/lib/axios"
+import { Button } from "@/components/ui/button"
+import {
+  AlertDialog,
+  AlertDialogCancel,
+  AlertDialogContent,
+  AlertDialogDescription,
+  AlertDialogFooter,
+  AlertDialogHeader,
+  AlertDialogTitle,
+} from "@/components/ui/alert-dialog"
+import { toast } from "sonner"
+import { useLanguage } from "@/context/LanguageContext"
+import { User } from "@/models/User"
+import { Session } from "@/models/Session"
+import { SessionList } from "@/components/SessionList"
+
+interface UserSessionsDialogProps {
+  user: User | null
+  onClose: () => void
+}
+
+export default function UserSessionsDialog({ user, onClose }: UserSessionsDialogProps) {
+  const { t } = useLanguage()
+  const [sessions, setSessions] = useState<Session[]>([])
+  const [submitting, setSubmitting] = useState(false)
+
+  const fetchSessions = async (userId: number) => {
+    try {
+      const res = await axios.get<Session[]>(`/api/users/${userId}/sessions`)
+      setSessions(res.data || [])
+    } catch (err: any) {
+      setSessions([])
+      toast.error(t(err?.response?.data?.error || "failed_to_fetch_sessions"), { className: "bg-red-600 text-white" })
+    }
+  }
+
+  useEffect(() => {
+    if (user) fetchSessions(user.id)
+    else setSessions([])
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [user])
+
+  const handleRevoke = async (session: Session) => {
+    if (!user) return
+    setSubmitting(true)
+    try {
+      await axios.delete(`/api/users/${user.id}/sessions/${session.id}`)
+      toast.success(t("session_revoked_success"))
+      fetchSessions(user.id)
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "failed_to_revoke_session"), { className: "bg-red-600 text-white" })
+    } finally {
+      setSubmitting(false)
+    }
+  }
+
+  const handleRevokeAll = async () => {
+    if (!user) return
+    setSubmitting(true)
+    try {
+      await axios.delete(`/api/users/${user.id}/sessions`)
+      toast.success(t("sessions_revoked_success"))
+      fetchSessions(user.id)
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "failed_to_revoke_sessions"), { className: "bg-red-600 text-white" })
+    } finally {
+      setSubmitting(false)
+    }
+  }
+
+  return (
+    <AlertDialog open={!!user} onOpenChange={(o) => !o && onClose()}>
+      <AlertDialogContent className="sm:max-w-2xl">
+        <AlertDialogHeader>
+          <AlertDialogTitle>{t("sessions_title")}</AlertDialogTitle>
+          <AlertDialogDescription>
+            {t("user_sessions_description", { username: user?.username || "" })}
+          </AlertDialogDescription>
+        </AlertDialogHeader>
+        <div className="max-h-[50vh] overflow-y-auto">
+          <SessionList sessions={sessions} onRevoke={handleRevoke} disabled={submitting} />
+        </div>
+        <AlertDialogFooter>
+          <AlertDialogCancel>{t("close")}</AlertDialogCancel>
+          <Button
+            variant="destructive"
+            onClick={handleRevokeAll}
+            disabled={submitting || sessions.length === 0}
+          >
+            {t("sessions_revoke_all")}
+          </Button>
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  )
+}
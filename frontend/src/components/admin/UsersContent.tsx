@@ -26,6 +26,7 @@ import {
   AlertDialogTitle,
 } from "@/components/ui/alert-dialog"
 import UserForm from "./UserForm"
+import UserSessionsDialog from "./UserSessionsDialog"
 import { User, UserFormData } from "@/models/User"
 import { useLanguage } from "@/context/LanguageContext"
 import { useSiteConfig } from "@/context/SiteConfigContext"
@@ -43,6 +44,8 @@ export default function UsersContent({ users, onRefresh }: UsersContentProps) {
   const [creating, setCreating] = useState(false)
   const [deleting, setDeleting] = useState<User | null>(null)
   const [tempBanning, setTempBanning] = useState<User | null>(null)
+  const [resettingTwoFactor, setResettingTwoFactor] = useState<User | null>(null)
+  const [viewingSessions, setViewingSessions] = useState<User | null>(null)
   const [confirmMassDelete, setConfirmMassDelete] = useState(false)
   const [confirmMassBan, setConfirmMassBan] = useState(false)
   const [rowSelection, setRowSelection] = useState<RowSelectionState>({})
@@ -313,9 +316,9 @@ export default function UsersContent({ users, onRefresh }: UsersContentProps) {
       id: "actions",
       header: t("actions"),
       cell: ({ row }) => {
-        if (row.original.id < 0) return <div className="w-[240px] h-[52px]">&nbsp;</div>
+        if (row.original.id < 0) return <div className="w-[400px] h-[52px]">&nbsp;</div>
         return (
-          <div className="flex gap-1 w-[240px] min-h-[52px] items-center">
+          <div className="flex gap-1 w-[400px] min-h-[52px] items-center">
             <Button
               variant="outline"
               size="sm"
@@ -330,6 +333,22 @@ export default function UsersContent({ users, onRefresh }: UsersContentProps) {
             >
               {row.original.banned ? t("unban") : t("temp_ban")}
             </Button>
+            <Button
+              variant="outline"
+              size="sm"
+              onClick={() => setViewingSessions(row.original)}
+            >
+              {t("sessions")}
+            </Button>
+            {row.original.totpEnabled && (
+              <Button
+                variant="outline"
+                size="sm"
+                onClick={() => setResettingTwoFactor(row.original)}
+              >
+                {t("reset_2fa")}
+              </Button>
+            )}
             <Button
               variant="destructive"
               size="sm"
@@ -454,6 +473,19 @@ export default function UsersContent({ users, onRefresh }: UsersContentProps) {
     }
   }
 
+  const doResetTwoFactor = async () => {
+    if (!resettingTwoFactor) return
+    try {
+      await axios.delete(`/api/users/${resettingTwoFactor.id}/2fa`)
+      toast.success(t("2fa_reset"))
+      onRefresh()
+    } catch (err: any) {
+      toast.error(t(err?.response?.data?.error || "failed_to_reset_2fa"), { className: "bg-red-600 text-white" })
+    } finally {
+      setResettingTwoFactor(null)
+    }
+  }
+
   return (
     <>
       <Head>
@@ -776,6 +808,27 @@ export default function UsersContent({ users, onRefresh }: UsersContentProps) {
         </AlertDialogContent>
       </AlertDialog>
 
+      {/* Reset 2FA Dialog */}
+      <AlertDialog open={!!resettingTwoFactor} onOpenChange={(o) => !o && setResettingTwoFactor(null)}>
+        <AlertDialogContent>
+          <AlertDialogHeader>
+            <AlertDialogTitle>{t("reset_2fa")}</AlertDialogTitle>
+            <AlertDialogDescription>
+              {t("reset_2fa_confirm", { username: resettingTwoFactor?.username || "" })}
+            </AlertDialogDescription>
+          </AlertDialogHeader>
+          <AlertDialogFooter>
+            <AlertDialogCancel>{t("cancel")}</AlertDialogCancel>
+            <AlertDialogAction onClick={doResetTwoFactor}>
+              {t("reset_2fa")}
+            </AlertDialogAction>
+          </AlertDialogFooter>
+        </AlertDialogContent>
+      </AlertDialog>
+
+      {/* Sessions Dialog */}
+      <UserSessionsDialog user={viewingSessions} onClose={() => setViewingSessions(null)} />
+
       {/* Confirm Mass Delete */}
       <AlertDialog open={confirmMassDelete} onOpenChange={setConfirmMassDelete}>
         <AlertDialogContent>
@@ -159,7 +159,8 @@ export function AppSidebar(props: React.ComponentProps<typeof Sidebar>) {
         isActive: router.pathname === "/login",
       });
       // Only show register link if registration is enabled
-      const registrationEnabled = siteConfig.REGISTRATION_ENABLED !== "false" && siteConfig.REGISTRATION_ENABLED !== "0";
+      const registrationEnabled = siteConfig.REGISTRATION_ENABLED !== "false" && siteConfig.REGISTRATION_ENABLED !== "0" &&
+        siteConfig.LOCAL_LOGIN_ENABLED !== "false" && siteConfig.LOCAL_LOGIN_ENABLED !== "0";
       if (registrationEnabled) {
       items.push({
         title: t('register'),
@@ -170,7 +171,7 @@ export function AppSidebar(props: React.ComponentProps<typeof Sidebar>) {
       }
     }
     return items;
-  }, [authChecked, loggedIn, router.pathname, userData.role, categories, loading, reorderCategories, t, siteConfig.REGISTRATION_ENABLED, ctfLoading, ctfStatus.status]);
+  }, [authChecked, loggedIn, router.pathname, userData.role, categories, loading, reorderCategories, t, siteConfig.REGISTRATION_ENABLED, siteConfig.LOCAL_LOGIN_ENABLED, ctfLoading, ctfStatus.status]);
 
   return (
     <Sidebar
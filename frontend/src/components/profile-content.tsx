@@ -23,6 +23,9 @@ import { useLanguage } from "@/context/LanguageContext";
 import { Team } from "@/models/Team";
 import { User } from "@/models/User";
 import { TeamManagementSection } from "@/components/TeamManagementSection";
+import { ApiTokensSection } from "@/components/ApiTokensSection";
+import { TwoFactorSection } from "@/components/TwoFactorSection";
+import { SessionsSection } from "@/components/SessionsSection";
 import { toast } from "sonner";
 
 const TABS = ["Account", "Security", "Appearance", "Team"] as const;
@@ -332,6 +335,7 @@ function ProfileContentInner() {
           </form>
         )}
         {activeTab === "Security" && (
+          <>
           <form className="space-y-4 max-w-md" onSubmit={handlePasswordChange}>
             <div>
               <label className="block text-sm font-medium mb-1" htmlFor="current">{t('current_password')}</label>
@@ -369,6 +373,13 @@ function ProfileContentInner() {
               </AlertDialogContent>
             </AlertDialog>
           </form>
+          <Separator className="my-6" />
+          <TwoFactorSection />
+          <Separator className="my-6" />
+          <SessionsSection />
+          <Separator className="my-6" />
+          <ApiTokensSection isAdmin={currentUser?.role === "admin"} />
+          </>
         )}
         {activeTab === "Appearance" && (
           <>
@@ -33,7 +33,7 @@ import type { User } from "@/models/User";
 import ReactMarkdown from "react-markdown";
 import remarkGfm from "remark-gfm";
 import { useChallengeInstances } from "@/hooks/use-challenge-instances";
-import { buildSubmitPayload, formatDate, GeoCoords } from "./category-helpers";
+import { buildSubmitPayload, describePrerequisite, formatDate, GeoCoords } from "./category-helpers";
 import { ChallengeFiles } from "@/components/ChallengeFiles";
 
 interface CategoryContentProps {
@@ -57,9 +57,10 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
   const [instanceDetails, setInstanceDetails] = useState<{[key: number]: any}>({});
   const [connectionInfo, setConnectionInfo] = useState<{[key: number]: string[]}>({});
   const [instanceOwner, setInstanceOwner] = useState<{[key: number]: { userId: number; username?: string } }>({});
+  const [instanceExpiry, setInstanceExpiry] = useState<{[key: number]: { expiresAt: string; canExtend: boolean } }>({});
   const [currentUser, setCurrentUser] = useState<User | null>(null);
   const { getSiteName } = useSiteConfig();
-  const { loading: instanceLoading, startInstance, stopInstance, killInstance, getInstanceStatus: fetchInstanceStatus } = useInstances();
+  const { loading: instanceLoading, startInstance, stopInstance, killInstance, extendInstance, getInstanceStatus: fetchInstanceStatus } = useInstances();
   const { teamScore, loading: hintsLoading, purchaseHint, refreshTeamScore } = useHints();
 
   // Fetch instance status for all Docker challenges when challenges are loaded
@@ -92,6 +93,7 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
               ...prev,
               [challenge.id]: localStatus
             }));
+            storeInstanceExpiry(challenge.id, status);
 
             // Store connection info if available
             if (status.connection_info && status.connection_info.length > 0) {
@@ -161,6 +163,14 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
         });
       }
 
+      // Keep the expiry in sync when a teammate extends the instance
+      if (newStatus === 'running' && data.expiresAt) {
+        setInstanceExpiry(prev => ({
+          ...prev,
+          [challengeId]: { expiresAt: data.expiresAt, canExtend: prev[challengeId]?.canExtend ?? false }
+        }));
+      }
+
       // Track instance owner from event payload
       if (newStatus === 'running' && (typeof data.userId === 'number' || typeof data.userId === 'string')) {
         const ownerId = Number(data.userId);
@@ -223,7 +233,7 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
       await handlePostSubmitInstanceCleanup(selectedChallenge.id);
     } catch (err: any) {
       const errorKey = err.response?.data?.error || err.response?.data?.result;
-      toast.error(t(errorKey) || 'Try again');
+      toast.error(t(errorKey, { seconds: err.response?.data?.retryAfter }) || 'Try again');
       if (onChallengeUpdate) onChallengeUpdate();
     } finally {
       setLoading(false);
@@ -299,6 +309,7 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
           localStatus = 'stopped';
         }
         setInstanceStatus(prev => ({ ...prev, [challengeId]: localStatus }));
+        storeInstanceExpiry(challengeId, status);
 
         // Store connection info if available
         if (status.connection_info && status.connection_info.length > 0) {
@@ -357,8 +368,31 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
     }
   };
 
+  const storeInstanceExpiry = (challengeId: number, status: any) => {
+    if (status?.status === 'running' && status.expires_at) {
+      setInstanceExpiry(prev => ({
+        ...prev,
+        [challengeId]: { expiresAt: status.expires_at, canExtend: !!status.can_extend }
+      }));
+    }
+  };
+
+  const handleExtendInstance = async (challengeId: number) => {
+    try {
+      await extendInstance(challengeId.toString());
+    } catch (error) {
+      // The hook already reported the error, the status below tells whether another extension is possible
+    }
+    try {
+      const status = await fetchInstanceStatus(challengeId.toString());
+      storeInstanceExpiry(challengeId, status);
+    } catch (error) {
+      debugError(`Failed to refresh status for challenge ${challengeId}:`, error);
+    }
+  };
+
   const isDockerChallenge = (challenge: Challenge) => {
-    if ((challenge.type?.name?.toLowerCase() === 'docker') || (challenge.type?.name?.toLowerCase() === 'compose')) {
+    if ((challenge.type?.name?.toLowerCase() === 'docker') || (challenge.type?.name?.toLowerCase() === 'compose') || (challenge.type?.name?.toLowerCase() === 'vm')) {
       return true
     }
   };
@@ -497,6 +531,18 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
                     </Badge>
                   )}
                 </div>
+
+                {/* Missing prerequisites */}
+                {challenge.locked && challenge.missingPrerequisites && challenge.missingPrerequisites.length > 0 && (
+                  <div className="mt-3 text-xs text-muted-foreground text-left">
+                    <span className="font-medium">{t('unlock_requires')}</span>
+                    <ul className="list-disc list-inside">
+                      {challenge.missingPrerequisites.map((prerequisite, index) => (
+                        <li key={index}>{describePrerequisite(prerequisite, t)}</li>
+                      ))}
+                    </ul>
+                  </div>
+                )}
               </div>
             </Card>
           ))}
@@ -993,6 +1039,11 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
                                       {t('instance_started_by_user', { username: instanceOwner[selectedChallenge.id]?.username || t('a_teammate') })}
                                     </div>
                                   )}
+                                  {getLocalInstanceStatus(selectedChallenge.id) === 'running' && instanceExpiry[selectedChallenge.id] && (
+                                    <div className="text-sm text-muted-foreground">
+                                      {t('instance_expires_at', { time: new Date(instanceExpiry[selectedChallenge.id].expiresAt).toLocaleString() })}
+                                    </div>
+                                  )}
                                   {getLocalInstanceStatus(selectedChallenge.id) === 'running' && 
                                    connectionInfo[selectedChallenge.id] && 
                                    connectionInfo[selectedChallenge.id].length > 0 && (
@@ -1045,6 +1096,18 @@ const CategoryContent = ({ cat, challenges = [], onChallengeUpdate, ctfStatus, c
                                     )}
                                   </Button>
                                 )}
+
+                                {getLocalInstanceStatus(selectedChallenge.id) === 'running' &&
+                                 instanceExpiry[selectedChallenge.id]?.canExtend && (
+                                  <Button
+                                    onClick={() => handleExtendInstance(selectedChallenge.id)}
+                                    disabled={instanceLoading}
+                                    variant="outline"
+                                  >
+                                    <Clock className="w-4 h-4 mr-2" />
+                                    {t('extend_instance')}
+                                  </Button>
+                                )}
                               </div>
                             </div>
                           </div>
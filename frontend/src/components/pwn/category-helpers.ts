@@ -1,4 +1,4 @@
-import { Challenge } from "@/models/Challenge";
+import { Challenge, Prerequisite } from "@/models/Challenge";
 
 export interface GeoCoords {
   lat: number;
@@ -53,3 +53,25 @@ export function formatDate(dateString: string): string {
     return 'Invalid date';
   }
 }
+
+export function describePrerequisite(
+  prerequisite: Prerequisite,
+  t: (key: string, vars?: Record<string, string | number>) => string
+): string {
+  const describeAll = (items: Prerequisite[]) =>
+    items.map((item) => describePrerequisite(item, t)).join(', ');
+
+  if (prerequisite.challenge) {
+    return t('prerequisite_challenge', { challenge: prerequisite.challenge });
+  }
+  if (prerequisite.all) {
+    return t('prerequisite_all', { items: describeAll(prerequisite.all) });
+  }
+  if (prerequisite.any) {
+    return t('prerequisite_any', { count: prerequisite.count || 1, items: describeAll(prerequisite.any) });
+  }
+  if (prerequisite.category) {
+    return t('prerequisite_category', { count: prerequisite.count || 1, category: prerequisite.category });
+  }
+  return t('prerequisite_min_score', { score: prerequisite.minScore || 0 });
+}
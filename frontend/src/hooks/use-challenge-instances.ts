@@ -35,7 +35,7 @@ export function useChallengeInstances(
 
   const isDockerChallenge = (challenge: Challenge) => {
     const typeName = challenge.type?.name?.toLowerCase();
-    return typeName === 'docker' || typeName === 'compose';
+    return typeName === 'docker' || typeName === 'compose' || typeName === 'vm';
   };
 
   const getLocalInstanceStatus = (challengeId: number): InstanceStatus => {
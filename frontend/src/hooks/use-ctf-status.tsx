@@ -9,6 +9,7 @@ export interface CTFStatus {
   status: 'not_started' | 'active' | 'ended' | 'no_timing';
   is_active: boolean;
   is_started: boolean;
+  scoreboard_frozen?: boolean;
 }
 
 let activeToastId: string | number | undefined;
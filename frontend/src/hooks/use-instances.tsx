@@ -118,6 +118,26 @@ export const useInstances = () => {
     }
   }
 
+  const extendInstance = async (challengeId: string) => {
+    setLoading(true)
+    setError(null)
+    
+    try {
+      debugLog(`Extending instance for challenge ID: ${challengeId}`)
+      const response = await axios.post(`/api/challenges/${challengeId}/extend`)
+      toast.success(t('instance_extended_success'))
+      return response.data
+    } catch (error: any) {
+      debugError('Failed to extend instance:', error)
+      const errorCode = error.response?.data?.error || 'instance_extend_failed'
+      toast.error(t(errorCode))
+      setError(errorCode)
+      throw error
+    } finally {
+      setLoading(false)
+    }
+  }
+
   const getInstanceStatus = async (challengeId: string) => {
     setLoading(true)
     setError(null)
@@ -144,6 +164,7 @@ export const useInstances = () => {
     startInstance,
     stopInstance,
     killInstance,
+    extendInstance,
     getInstanceStatus,
     error,
   }
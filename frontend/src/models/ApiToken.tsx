@@ -0,0 +1,16 @@
+export type ApiTokenScope = "read" | "submit" | "admin";
+
+export interface ApiToken {
+  id: number;
+  userId: number;
+  name: string;
+  prefix: string;
+  scope: ApiTokenScope;
+  expiresAt?: string;
+  lastUsedAt?: string;
+  createdAt: string;
+}
+
+export interface CreatedApiToken extends ApiToken {
+  token: string;
+}
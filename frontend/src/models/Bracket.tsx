@@ -0,0 +1,5 @@
+export interface Bracket {
+  id: number
+  name: string
+  description?: string
+}
@@ -4,6 +4,15 @@ import { ChallengeType } from "./ChallengeType"
 import { User } from "./User"
 import { Team } from "./Team"
 
+export interface Prerequisite {
+  challenge?: string
+  all?: Prerequisite[]
+  any?: Prerequisite[]
+  category?: string
+  count?: number
+  minScore?: number
+}
+
 export interface Challenge {
   id: number
   slug: string
@@ -37,6 +46,7 @@ export interface Challenge {
     type: string
     step: number
     minPoints: number
+    interval?: number
   }
   decayFormulaId?: number
   hints?: {
@@ -51,11 +61,15 @@ export interface Challenge {
   teamFailedAttempts?: number
   dependsOn?: string
   locked?: boolean
+  missingPrerequisites?: Prerequisite[]
   coverImg?: string
   emoji?: string
   coverPositionX?: number  // 0-100, supports decimals
   coverPositionY?: number  // 0-100, supports decimals
   coverZoom?: number       // 100-200, default 100 (no zoom)
+  releaseAt?: string
+  hideAt?: string
+  archivedAt?: string
 }
 
 export interface FirstBlood {
@@ -10,4 +10,18 @@ export interface ConfigFormData {
   value: string;
   public: boolean;
   syncWithEnv: boolean;
-} 
\ No newline at end of file
+} 
+export interface DockerConfig {
+  id: number;
+  host: string;
+  imagePrefix: string;
+  instancesByTeam: number;
+  instancesByUser: number;
+  maxMemByInstance: number;
+  maxCpuByInstance: number;
+  instanceTimeout: number;
+  instanceCooldownSeconds: number;
+  instanceExtensionMinutes: number;
+  instanceMaxExtensions: number;
+  instanceMaxLifetime: number;
+}
@@ -0,0 +1,10 @@
+export interface Session {
+  id: number;
+  userId: number;
+  userAgent: string;
+  ipAddress: string;
+  lastUsedAt: string;
+  expiresAt: string;
+  createdAt: string;
+  current: boolean;
+}
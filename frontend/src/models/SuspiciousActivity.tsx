@@ -0,0 +1,23 @@
+export interface SuspiciousActivity {
+  id: number;
+  type: string;
+  status: "pending" | "dismissed";
+  user: {
+    id: number;
+    username: string;
+    role: string;
+  };
+  team: {
+    id: number;
+    name: string;
+  };
+  sourceTeam: {
+    id: number;
+    name: string;
+  };
+  challengeId: number;
+  challenge: string;
+  autoBanned: boolean;
+  reviewedAt?: string;
+  createdAt: string;
+}
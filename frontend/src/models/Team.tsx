@@ -1,9 +1,12 @@
 import { User } from './User';
+import { Bracket } from './Bracket';
 
 export interface Team {
   id: number;
   name: string;
   creatorId: number;
+  bracketId?: number | null;
+  bracket?: Bracket;
   users: User[];
   createdAt?: string;
   updatedAt?: string;
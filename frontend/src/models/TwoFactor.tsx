@@ -0,0 +1,11 @@
+export interface TwoFactorSetup {
+  secret: string;
+  uri: string;
+  qrCode: string;
+}
+
+export interface TwoFactorStatus {
+  enabled: boolean;
+  required: boolean;
+  recoveryCodesLeft: number;
+}
@@ -4,6 +4,7 @@ export interface User {
   email: string;
   role: string;
   banned: boolean;
+  totpEnabled?: boolean;
   teamId?: number;
   team?: {
     id: number;
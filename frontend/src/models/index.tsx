@@ -1,5 +1,6 @@
 // Centralized model exports
 export * from './Badge';
+export * from './Bracket';
 export * from './Challenge';
 export * from './ChallengeCategory';
 export * from './ChallengeDifficulty';
@@ -8,5 +9,6 @@ export * from './Config';
 export * from './Instance';
 export * from './NavItem';
 export * from './Notification';
+export * from './SuspiciousActivity';
 export * from './Team';
 export * from './User';
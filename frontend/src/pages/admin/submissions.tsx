@@ -2,6 +2,7 @@ import { useEffect, useState } from "react"
 import axios from "@/lib/axios"
 import { useAdminAuth } from "@/hooks/use-admin-auth"
 import SubmissionsContent from "@/components/admin/SubmissionsContent"
+import { SuspiciousActivity } from "@/models/SuspiciousActivity"
 
 type Submission = {
   id: number
@@ -22,6 +23,8 @@ type Submission = {
 export default function SubmissionsPage() {
   const { loading, isAdmin } = useAdminAuth()
   const [submissions, setSubmissions] = useState<Submission[]>([])
+  const [suspiciousActivities, setSuspiciousActivities] = useState<SuspiciousActivity[]>([])
+  const [showDismissed, setShowDismissed] = useState(false)
 
   const fetchSubmissions = () => {
     axios
@@ -30,16 +33,38 @@ export default function SubmissionsPage() {
       .catch(() => setSubmissions([]))
   }
 
+  const fetchSuspiciousActivities = () => {
+    axios
+      .get<SuspiciousActivity[]>('/api/admin/submissions/suspicious', {
+        params: showDismissed ? {} : { status: 'pending' },
+      })
+      .then((res) => setSuspiciousActivities(res.data || []))
+      .catch(() => setSuspiciousActivities([]))
+  }
+
+  const refresh = () => {
+    fetchSubmissions()
+    fetchSuspiciousActivities()
+  }
+
   useEffect(() => {
     if (isAdmin) fetchSubmissions()
   }, [isAdmin])
 
+  useEffect(() => {
+    if (isAdmin) fetchSuspiciousActivities()
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [isAdmin, showDismissed])
+
   if (loading || !isAdmin) return null
 
   return (
     <SubmissionsContent
       submissions={submissions}
-      onRefresh={fetchSubmissions}
+      suspiciousActivities={suspiciousActivities}
+      showDismissed={showDismissed}
+      onShowDismissedChange={setShowDismissed}
+      onRefresh={refresh}
     />
   )
 }
@@ -0,0 +1,83 @@
+import { useState } from "react";
+import Head from "next/head";
+import Link from "next/link";
+import axios from "@/lib/axios";
+import { toast } from "sonner";
+import { useLanguage } from "@/context/LanguageContext";
+import { useSiteConfig } from "@/context/SiteConfigContext";
+import {
+  Card,
+  CardContent,
+  CardDescription,
+  CardHeader,
+  CardTitle,
+} from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Button } from "@/components/ui/button";
+
+const ForgotPasswordPage = () => {
+  const { t } = useLanguage();
+  const { getSiteName } = useSiteConfig();
+  const [email, setEmail] = useState("");
+  const [loading, setLoading] = useState(false);
+  const [sent, setSent] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    try {
+      await axios.post("/api/password/forgot", { email });
+      setSent(true);
+    } catch (error: any) {
+      toast.error(t(error?.response?.data?.error || "forgot_password_failed"), { className: "bg-red-600 text-white" });
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <>
+      <Head>
+        <title>{getSiteName()}</title>
+      </Head>
+      <div className="bg-muted flex min-h-screen flex-col items-center justify-center px-4 py-8">
+        <div className="w-full max-w-sm">
+          <Card>
+            <CardHeader className="text-center">
+              <CardTitle className="text-xl">{t("forgot_password_title")}</CardTitle>
+              <CardDescription>{sent ? t("password_reset_sent") : t("forgot_password_description")}</CardDescription>
+            </CardHeader>
+            <CardContent className="grid gap-4">
+              {!sent && (
+                <form onSubmit={handleSubmit} className="grid gap-4">
+                  <div className="grid gap-2">
+                    <Label htmlFor="email">{t("email")}</Label>
+                    <Input
+                      id="email"
+                      name="email"
+                      type="email"
+                      value={email}
+                      onChange={(e) => setEmail(e.target.value)}
+                      placeholder="you@example.com"
+                      required
+                      disabled={loading}
+                    />
+                  </div>
+                  <Button type="submit" className="w-full" disabled={loading}>
+                    {t("send_reset_link")}
+                  </Button>
+                </form>
+              )}
+              <Link href="/login" className="text-center text-sm underline underline-offset-4">
+                {t("back_to_login")}
+              </Link>
+            </CardContent>
+          </Card>
+        </div>
+      </div>
+    </>
+  );
+};
+
+export default ForgotPasswordPage;
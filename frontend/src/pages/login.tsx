@@ -3,6 +3,7 @@ import { useRouter } from "next/router";
 import { useAuth } from "@/context/AuthContext";
 import { useSiteConfig } from "@/context/SiteConfigContext";
 import LoginContent from "@/components/LoginContent";
+import TwoFactorLoginStep from "@/components/TwoFactorLoginStep";
 import { useLanguage } from "@/context/LanguageContext";
 import { toast } from "sonner";
 import axios from "@/lib/axios";
@@ -13,9 +14,14 @@ const LoginPage = () => {
   const router = useRouter();
   const { login } = useAuth();
   const { t, language } = useLanguage();
-  const { getSiteName } = useSiteConfig();
+  const { siteConfig, getSiteName } = useSiteConfig();
+  // /login?local=1 keeps the password form for admins when only single sign-on is allowed
+  const localLoginEnabled = (siteConfig.LOCAL_LOGIN_ENABLED !== "false" && siteConfig.LOCAL_LOGIN_ENABLED !== "0") || router.query.local === "1";
+  const ssoEnabled = siteConfig.OIDC_ENABLED === "true" || siteConfig.OIDC_ENABLED === "1";
 
   const [form, setForm] = useState({ username: "", password: "" });
+  // Set when the password or single sign-on passed and a second factor is expected
+  const [twoFactor, setTwoFactor] = useState<{ setup: boolean } | null>(null);
 
   useEffect(() => {
     if (router.query.success === "register") {
@@ -35,36 +41,100 @@ const LoginPage = () => {
       const query = new URLSearchParams(rest as Record<string, string>).toString();
       router.replace(`/login${query ? `?${query}` : ""}`, undefined, { shallow: true });
     }
+
+    // Show single sign-on errors sent back by the callback
+    if (typeof router.query.error === "string") {
+      toast.error(t(router.query.error), { className: "bg-red-600 text-white" });
+      const { error, ...rest } = router.query;
+      const query = new URLSearchParams(rest as Record<string, string>).toString();
+      router.replace(`/login${query ? `?${query}` : ""}`, undefined, { shallow: true });
+    }
+
+    // Single sign-on users with two-factor authentication come back here for the second step
+    if (router.query["2fa"] === "required") {
+      axios.get<{ twoFactorSetup: boolean }>("/api/login/2fa")
+        .then((res) => setTwoFactor({ setup: res.data.twoFactorSetup }))
+        .catch((err) => toast.error(t(err?.response?.data?.error || "2fa_login_expired"), { className: "bg-red-600 text-white" }));
+      const { "2fa": _, ...rest } = router.query;
+      const query = new URLSearchParams(rest as Record<string, string>).toString();
+      router.replace(`/login${query ? `?${query}` : ""}`, undefined, { shallow: true });
+    }
   }, [router.query, t, router, language]);
 
   const onChange = (e: React.ChangeEvent<HTMLInputElement>) => {
     setForm({ ...form, [e.target.name]: e.target.value });
   };
 
+  const completeLogin = () => {
+    login();
+    if (typeof window !== "undefined") {
+      window.dispatchEvent(new CustomEvent("auth:refresh"));
+    }
+    localStorage.setItem("showToast", JSON.stringify({ type: "success", key: "login_success", lang: language }));
+    router.push("/pwn");
+  };
+
   const handleLogin = async (e: React.FormEvent) => {
     e.preventDefault();
     try {
       // Send "username" in payload (can be username or email)
       const res = await axios.post("/api/login", form);
-      login();
-      if (typeof window !== "undefined") {
-        window.dispatchEvent(new CustomEvent("auth:refresh"));
+      if (res.data?.twoFactorRequired) {
+        setTwoFactor({ setup: !!res.data.twoFactorSetup });
+        return;
       }
-      localStorage.setItem("showToast", JSON.stringify({ type: "success", key: "login_success", lang: language }));
-      router.push("/pwn");
+      completeLogin();
     } catch (error: any) {
       const errorKey = error?.response?.data?.error || "Error during login";
       // Show error toast immediately, don't store in localStorage since user stays on login page
+      if (errorKey === "email_not_verified" && form.username.includes("@")) {
+        toast.error(t(errorKey), {
+          className: "bg-red-600 text-white",
+          action: {
+            label: t("resend_verification"),
+            onClick: () => {
+              axios.post("/api/verify-email/resend", { email: form.username })
+                .then(() => toast.success(t("verification_email_sent")))
+                .catch((err) => toast.error(t(err?.response?.data?.error || "mail_disabled"), { className: "bg-red-600 text-white" }));
+            },
+          },
+        });
+        return;
+      }
       toast.error(t(errorKey), { className: "bg-red-600 text-white" });
     }
   };
 
+  if (twoFactor) {
+    return (
+      <>
+        <Head>
+          <title>{getSiteName()}</title>
+        </Head>
+        <TwoFactorLoginStep
+          setup={twoFactor.setup}
+          onSuccess={completeLogin}
+          onCancel={() => {
+            setTwoFactor(null);
+            setForm({ username: form.username, password: "" });
+          }}
+        />
+      </>
+    );
+  }
+
   return (
     <>
       <Head>
         <title>{getSiteName()}</title>
       </Head>
-    <LoginContent form={form} onChange={onChange} onSubmit={handleLogin} />
+    <LoginContent
+      form={form}
+      onChange={onChange}
+      onSubmit={handleLogin}
+      localLoginEnabled={localLoginEnabled}
+      ssoProvider={ssoEnabled ? siteConfig.OIDC_PROVIDER_NAME || "SSO" : undefined}
+    />
     </>
   );
 };
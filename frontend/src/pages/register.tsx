@@ -24,9 +24,11 @@ const RegisterPage = () => {
     if (!configLoading) {
       // Default to disabled if config is not available (security first)
       const isEnabled = siteConfig.REGISTRATION_ENABLED === "true" || siteConfig.REGISTRATION_ENABLED === "1";
-      setRegistrationEnabled(isEnabled);
+      // Accounts are created on first single sign-on when password login is disabled
+      const localLoginEnabled = siteConfig.LOCAL_LOGIN_ENABLED !== "false" && siteConfig.LOCAL_LOGIN_ENABLED !== "0";
+      setRegistrationEnabled(isEnabled && localLoginEnabled);
     }
-  }, [siteConfig.REGISTRATION_ENABLED, configLoading]);
+  }, [siteConfig.REGISTRATION_ENABLED, siteConfig.LOCAL_LOGIN_ENABLED, configLoading]);
 
   const onChange = (e: React.ChangeEvent<HTMLInputElement>) => {
     setForm({ ...form, [e.target.name]: e.target.value });
@@ -37,8 +39,8 @@ const RegisterPage = () => {
     setLoading(true);
 
     try {
-      await axios.post(`/api/register`, form);
-      toast.success(t("registration_successful"), {
+      const res = await axios.post(`/api/register`, form);
+      toast.success(t(res.data?.emailVerificationSent ? "registration_successful_verify" : "registration_successful"), {
         icon: <CheckCircle className="w-4 h-4" />,
         className: "success-toast",
       });
@@ -0,0 +1,107 @@
+import { useState } from "react";
+import Head from "next/head";
+import Link from "next/link";
+import { useRouter } from "next/router";
+import axios from "@/lib/axios";
+import { toast } from "sonner";
+import { useLanguage } from "@/context/LanguageContext";
+import { useSiteConfig } from "@/context/SiteConfigContext";
+import {
+  Card,
+  CardContent,
+  CardDescription,
+  CardHeader,
+  CardTitle,
+} from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Button } from "@/components/ui/button";
+
+const ResetPasswordPage = () => {
+  const router = useRouter();
+  const { t } = useLanguage();
+  const { getSiteName } = useSiteConfig();
+  const [password, setPassword] = useState("");
+  const [confirm, setConfirm] = useState("");
+  const [loading, setLoading] = useState(false);
+
+  const token = typeof router.query.token === "string" ? router.query.token : "";
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (password !== confirm) {
+      toast.error(t("passwords_do_not_match"), { className: "bg-red-600 text-white" });
+      return;
+    }
+    setLoading(true);
+    try {
+      await axios.post("/api/password/reset", { token, password });
+      toast.success(t("password_reset"));
+      router.push("/login");
+    } catch (error: any) {
+      toast.error(t(error?.response?.data?.error || "invalid_or_expired_token"), { className: "bg-red-600 text-white" });
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <>
+      <Head>
+        <title>{getSiteName()}</title>
+      </Head>
+      <div className="bg-muted flex min-h-screen flex-col items-center justify-center px-4 py-8">
+        <div className="w-full max-w-sm">
+          <Card>
+            <CardHeader className="text-center">
+              <CardTitle className="text-xl">{t("reset_password_title")}</CardTitle>
+              <CardDescription>{token ? t("reset_password_description") : t("invalid_or_expired_token")}</CardDescription>
+            </CardHeader>
+            <CardContent className="grid gap-4">
+              {token && (
+                <form onSubmit={handleSubmit} className="grid gap-4">
+                  <div className="grid gap-2">
+                    <Label htmlFor="password">{t("new_password")}</Label>
+                    <Input
+                      id="password"
+                      type="password"
+                      value={password}
+                      onChange={(e) => setPassword(e.target.value)}
+                      minLength={8}
+                      maxLength={72}
+                      autoComplete="new-password"
+                      required
+                      disabled={loading}
+                    />
+                  </div>
+                  <div className="grid gap-2">
+                    <Label htmlFor="confirm">{t("confirm_password")}</Label>
+                    <Input
+                      id="confirm"
+                      type="password"
+                      value={confirm}
+                      onChange={(e) => setConfirm(e.target.value)}
+                      minLength={8}
+                      maxLength={72}
+                      autoComplete="new-password"
+                      required
+                      disabled={loading}
+                    />
+                  </div>
+                  <Button type="submit" className="w-full" disabled={loading}>
+                    {t("reset_password_title")}
+                  </Button>
+                </form>
+              )}
+              <Link href={token ? "/login" : "/forgot-password"} className="text-center text-sm underline underline-offset-4">
+                {token ? t("back_to_login") : t("send_reset_link")}
+              </Link>
+            </CardContent>
+          </Card>
+        </div>
+      </div>
+    </>
+  );
+};
+
+export default ResetPasswordPage;
@@ -97,7 +97,7 @@ export default function ScoreboardPage() {
       <Head>
         <title>{`${getSiteName()} - Scoreboard`}</title>
       </Head>
-      <ScoreboardContent />
+      <ScoreboardContent frozen={ctfStatus?.scoreboard_frozen ?? false} />
     </>
   );
 }
@@ -5,6 +5,8 @@ import { useSiteConfig } from "@/context/SiteConfigContext";
 import { Card, CardContent, CardTitle } from "@/components/ui/card";
 import { Input } from "@/components/ui/input";
 import { Button } from "@/components/ui/button";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+import { Bracket } from "@/models/Bracket";
 import axios from "@/lib/axios";
 import { useLanguage } from "@/context/LanguageContext";
 import { toast } from "sonner";
@@ -15,6 +17,8 @@ export default function TeamPage() {
   const { getSiteName } = useSiteConfig();
   const [createName, setCreateName] = useState("");
   const [createPassword, setCreatePassword] = useState("");
+  const [createBracket, setCreateBracket] = useState("");
+  const [brackets, setBrackets] = useState<Bracket[]>([]);
   const [joinName, setJoinName] = useState("");
   const [joinPassword, setJoinPassword] = useState("");
   const [loading, setLoading] = useState(false);
@@ -39,6 +43,10 @@ export default function TeamPage() {
             router.replace("/");
           } else {
             setHasTeam(false);
+            axios
+              .get<Bracket[]>("/api/brackets")
+              .then((res) => setBrackets(res.data || []))
+              .catch(() => setBrackets([]));
           }
           setTeamChecked(true);
         })
@@ -76,6 +84,7 @@ export default function TeamPage() {
       const res = await axios.post("/api/teams", {
         name: createName,
         password: createPassword,
+        bracketId: createBracket ? Number(createBracket) : null,
       });
 
       if (!res || !res.data) throw new Error(t("invalid_server_response"));
@@ -86,7 +95,7 @@ export default function TeamPage() {
       router.push("/");
     } catch (err: any) {
       const errorMessage = err.response?.data?.error 
-        ? t(err.response.data.error) 
+        ? t(err.response.data.error, { seconds: err.response.data.retryAfter }) 
         : err.message || t("team_creation_failed");
       toast.error(errorMessage, { className: "bg-red-600 text-white" });
     } finally {
@@ -111,7 +120,7 @@ export default function TeamPage() {
       router.push("/");
     } catch (err: any) {
       const errorMessage = err.response?.data?.error 
-        ? t(err.response.data.error) 
+        ? t(err.response.data.error, { seconds: err.response.data.retryAfter }) 
         : err.message || t("team_join_failed");
       toast.error(errorMessage, { className: "bg-red-600 text-white" });
     } finally {
@@ -146,6 +155,20 @@ export default function TeamPage() {
                 onChange={(e) => setCreatePassword(e.target.value)}
                 maxLength={72}
               />
+              {brackets.length > 0 && (
+                <Select value={createBracket} onValueChange={setCreateBracket}>
+                  <SelectTrigger>
+                    <SelectValue placeholder={t("select_bracket") || "Bracket"} />
+                  </SelectTrigger>
+                  <SelectContent>
+                    {brackets.map((bracket) => (
+                      <SelectItem key={bracket.id} value={String(bracket.id)}>
+                        {bracket.name}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              )}
               <Button type="submit" disabled={loading} className="w-full">
                 {loading ? t("creating") : t("create")}
               </Button>
@@ -0,0 +1,69 @@
+import { useEffect, useRef, useState } from "react";
+import Head from "next/head";
+import Link from "next/link";
+import { useRouter } from "next/router";
+import axios from "@/lib/axios";
+import { useLanguage } from "@/context/LanguageContext";
+import { useSiteConfig } from "@/context/SiteConfigContext";
+import {
+  Card,
+  CardContent,
+  CardDescription,
+  CardHeader,
+  CardTitle,
+} from "@/components/ui/card";
+
+type Status = "verifying" | "verified" | "failed";
+
+const VerifyEmailPage = () => {
+  const router = useRouter();
+  const { t } = useLanguage();
+  const { getSiteName } = useSiteConfig();
+  const [status, setStatus] = useState<Status>("verifying");
+  const submitted = useRef(false);
+
+  useEffect(() => {
+    if (!router.isReady || submitted.current) return;
+    submitted.current = true;
+
+    const token = typeof router.query.token === "string" ? router.query.token : "";
+    if (!token) {
+      setStatus("failed");
+      return;
+    }
+    axios.post("/api/verify-email", { token })
+      .then(() => setStatus("verified"))
+      .catch(() => setStatus("failed"));
+  }, [router.isReady, router.query.token]);
+
+  return (
+    <>
+      <Head>
+        <title>{getSiteName()}</title>
+      </Head>
+      <div className="bg-muted flex min-h-screen flex-col items-center justify-center px-4 py-8">
+        <div className="w-full max-w-sm">
+          <Card>
+            <CardHeader className="text-center">
+              <CardTitle className="text-xl">{t("verify_email_title")}</CardTitle>
+              <CardDescription>
+                {status === "verifying" && t("verifying_email")}
+                {status === "verified" && t("email_verified")}
+                {status === "failed" && t("invalid_or_expired_token")}
+              </CardDescription>
+            </CardHeader>
+            <CardContent className="grid gap-4">
+              {status !== "verifying" && (
+                <Link href="/login" className="text-center text-sm underline underline-offset-4">
+                  {t("back_to_login")}
+                </Link>
+              )}
+            </CardContent>
+          </Card>
+        </div>
+      </div>
+    </>
+  );
+};
+
+export default VerifyEmailPage;
@@ -50,6 +50,15 @@ else
 fi
 echo ""
 
+echo "[+] Ensuring default storage pool..."
+if ! virsh -c qemu:///system pool-info default >/dev/null 2>&1; then
+    virsh -c qemu:///system pool-define-as default dir --target /var/lib/libvirt/images
+    virsh -c qemu:///system pool-build default
+    virsh -c qemu:///system pool-autostart default
+fi
+virsh -c qemu:///system pool-start default >/dev/null 2>&1 || true
+echo ""
+
 echo "[+] Libvirt Worker Information:"
 echo "  User: libvirt-user"
 echo "  Groups: $(groups libvirt-user)"
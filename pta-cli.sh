@@ -571,6 +571,95 @@ function db_clean_demo() {
     echo "[✓] Demo data cleanup complete"
 }
 
+function db_rebuild_scoreboard() {
+    local env="dev"
+
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
+            -e|--env)
+                env="$2"
+                shift 2
+                ;;
+            *)
+                echo "[✗] Unknown option: $1"
+                usage
+                ;;
+        esac
+    done
+
+    local compose_file="docker-compose.${env}.yml"
+    if [[ ! -f "$compose_file" ]]; then
+        echo "[✗] Compose file not found: $compose_file"
+        exit 1
+    fi
+
+    echo "[+] Rebuilding scoreboard using $compose_file"
+    echo ""
+
+    if [[ "$env" == "dev" ]]; then
+        docker compose -f "$compose_file" exec -T backend \
+            go run . --rebuild-scoreboard
+    else
+        docker compose -f "$compose_file" exec -T backend \
+            /app/pwnthemall --rebuild-scoreboard
+    fi
+
+    echo ""
+    echo "[✓] Scoreboard rebuild complete"
+}
+
+# Challenge archive functions
+function challenges_archive() {
+    local action="$1"
+    shift
+    local env="dev"
+    local file=""
+
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
+            -e|--env)
+                env="$2"
+                shift 2
+                ;;
+            *)
+                file="$1"
+                shift
+                ;;
+        esac
+    done
+    [ -z "$file" ] && usage
+
+    local compose_file="docker-compose.${env}.yml"
+    if [[ ! -f "$compose_file" ]]; then
+        echo "[✗] Compose file not found: $compose_file"
+        exit 1
+    fi
+
+    local binary=(/app/pwnthemall)
+    if [[ "$env" == "dev" ]]; then
+        binary=(go run .)
+    fi
+    local remote="/tmp/pwnthemall-challenges.tar.gz"
+
+    if [[ "$action" == "export" ]]; then
+        echo "[+] Exporting challenges to $file using $compose_file"
+        docker compose -f "$compose_file" exec -T backend "${binary[@]}" --export-challenges="$remote"
+        docker compose -f "$compose_file" cp "backend:$remote" "$file"
+        docker compose -f "$compose_file" exec -T backend rm -f "$remote"
+        echo "[✓] Challenges exported to $file"
+    else
+        if [[ ! -f "$file" ]]; then
+            echo "[✗] Archive not found: $file"
+            exit 1
+        fi
+        echo "[+] Importing challenges from $file using $compose_file"
+        docker compose -f "$compose_file" cp "$file" "backend:$remote"
+        docker compose -f "$compose_file" exec -T backend "${binary[@]}" --import-challenges="$remote"
+        docker compose -f "$compose_file" exec -T backend rm -f "$remote"
+        echo "[✓] Challenges imported from $file"
+    fi
+}
+
 function usage() {
     cat <<EOF
 
@@ -580,6 +669,9 @@ Usage:
   $0 compose down [--env dev|prod|demo]
   $0 db seed-demo [--env dev|prod|demo] [--teams N] [--time-range H]
   $0 db clean-demo [--env dev|prod|demo]
+  $0 db rebuild-scoreboard [--env dev|prod|demo]
+  $0 challenges export [--env dev|prod|demo] <archive.tar.gz>
+  $0 challenges import [--env dev|prod|demo] <archive.tar.gz>
   $0 plugins build
   $0 plugins clean
   $0 plugins list
@@ -594,6 +686,9 @@ Commands:
   compose down     Stop the application stack
   db seed-demo     Seed database with demo teams, users, and solves
   db clean-demo    Remove all demo data from the database
+  db rebuild-scoreboard  Recompute team scores and report out of date ones
+  challenges export  Export challenges, hints, decay formulas and badges to an archive
+  challenges import  Import (or update) challenges from an archive
   plugins build    Compile all plugins to binaries
   plugins clean    Remove compiled plugins and Docker image
   plugins list     List available plugins
@@ -613,6 +708,7 @@ Examples:
   $0 minio sync --env prod ./minio/challenges
   $0 db seed-demo --env dev --teams 30 --time-range 20
   $0 db clean-demo --env dev
+  $0 challenges export --env prod ./ctf-2025.tar.gz
 
 EOF
     exit 1
@@ -720,16 +816,32 @@ case "${1:-}" in
                 shift
                 db_clean_demo "$@"
                 ;;
+            rebuild-scoreboard)
+                shift
+                db_rebuild_scoreboard "$@"
+                ;;
             *)
                 echo "[✗] Unknown db command: ${1:-}"
                 echo ""
                 echo "Available commands:"
                 echo "  seed-demo   - Seed demo teams, users, and solves"
                 echo "  clean-demo  - Remove all demo data"
+                echo "  rebuild-scoreboard - Recompute team scores"
                 exit 1
                 ;;
         esac
         ;;
+    challenges)
+        shift
+        case "${1:-}" in
+            export|import)
+                challenges_archive "$@"
+                ;;
+            *)
+                usage
+                ;;
+        esac
+        ;;
     help|-h|--help)
         usage
         ;;
@@ -0,0 +1,70 @@
+import { test, expect, APIRequestContext } from '@playwright/test';
+
+test.use({
+  ignoreHTTPSErrors: true,
+});
+
+// Mailpit from docker-compose.dev.yml catches the emails sent by the backend
+const MAILPIT_URL = process.env.MAILPIT_URL || 'http://localhost:8025';
+
+// Waits for the last email sent to an address and returns its text body
+async function waitForMail(request: APIRequestContext, to: string): Promise<string> {
+  for (let attempt = 0; attempt < 20; attempt++) {
+    const search = await request.get(`${MAILPIT_URL}/api/v1/search`, { params: { query: `to:"${to}"` } });
+    const { messages } = await search.json();
+    if (messages && messages.length > 0) {
+      const message = await request.get(`${MAILPIT_URL}/api/v1/message/${messages[0].ID}`);
+      return (await message.json()).Text;
+    }
+    await new Promise((resolve) => setTimeout(resolve, 500));
+  }
+  throw new Error(`No email received for ${to}`);
+}
+
+test('reset a forgotten password from the emailed link', async ({ page, request }) => {
+  const uid = Date.now();
+  const username = `reset${uid}`;
+  const email = `reset${uid}@pwnthemall.com`;
+  const newPassword = 'NewPassword456';
+
+  // Register
+  await page.goto('https://pwnthemall.local/');
+  await page.getByRole('button', { name: 'Accept' }).click();
+  await page.getByRole('link', { name: 'Register' }).click();
+  await page.getByRole('textbox', { name: 'Username' }).fill(username);
+  await page.getByRole('textbox', { name: 'Email' }).fill(email);
+  await page.getByRole('textbox', { name: 'Password' }).fill('TestPassword123');
+  await page.getByRole('button', { name: 'Register' }).click();
+  await expect(page.getByText(/registration successful/i)).toBeVisible();
+
+  // Ask for a reset link
+  await page.goto('https://pwnthemall.local/login');
+  await page.getByRole('link', { name: /forgot password/i }).click();
+  await page.getByRole('textbox', { name: /email/i }).fill(email);
+  await page.getByRole('button', { name: /send reset link/i }).click();
+  await expect(page.getByText(/a reset link is on its way/i)).toBeVisible();
+
+  // Open the link of the email and choose a new password
+  const body = await waitForMail(request, email);
+  const link = body.match(/https?:\/\/\S+\/reset-password\?token=\S+/)?.[0];
+  expect(link).toBeTruthy();
+  await page.goto(link!);
+  await page.getByLabel('New password').fill(newPassword);
+  await page.getByLabel('Confirm password').fill(newPassword);
+  await page.getByRole('button', { name: /reset password/i }).click();
+  await expect(page.getByText(/your password has been reset/i)).toBeVisible();
+
+  // The link only works once
+  await page.goto(link!);
+  await page.getByLabel('New password').fill('AnotherPassword789');
+  await page.getByLabel('Confirm password').fill('AnotherPassword789');
+  await page.getByRole('button', { name: /reset password/i }).click();
+  await expect(page.getByText(/invalid or has expired/i)).toBeVisible();
+
+  // Login with the new password
+  await page.goto('https://pwnthemall.local/login');
+  await page.getByRole('textbox', { name: /email/i }).fill(email);
+  await page.getByRole('textbox', { name: /password/i }).fill(newPassword);
+  await page.getByRole('button', { name: /login/i }).click();
+  await expect(page.locator('[id="__next"]')).toContainText(username);
+});
@@ -0,0 +1,57 @@
+import { test, expect, request, APIRequestContext } from '@playwright/test';
+
+const baseURL = 'https://pwnthemall.local';
+
+async function loggedInContext(email: string, password: string): Promise<APIRequestContext> {
+  const context = await request.newContext({ baseURL, ignoreHTTPSErrors: true });
+  const res = await context.post('/api/login', { data: { username: email, password } });
+  expect(res.ok()).toBeTruthy();
+  return context;
+}
+
+test('refresh tokens rotate, reuse revokes the session and users can log out everywhere', async () => {
+  const uid = Date.now();
+  const email = `session${uid}@pwnthemall.com`;
+  const password = 'TestPassword123';
+
+  const anonymous = await request.newContext({ baseURL, ignoreHTTPSErrors: true });
+  const registerRes = await anonymous.post('/api/register', {
+    data: { username: `session${uid}`, email, password },
+  });
+  expect(registerRes.ok()).toBeTruthy();
+
+  const laptop = await loggedInContext(email, password);
+  const phone = await loggedInContext(email, password);
+
+  // Both logins are listed, the laptop one flagged as current
+  const sessionsRes = await laptop.get('/api/me/sessions');
+  expect(sessionsRes.ok()).toBeTruthy();
+  const sessions = await sessionsRes.json();
+  expect(sessions).toHaveLength(2);
+  expect(sessions.filter((s: { current: boolean }) => s.current)).toHaveLength(1);
+
+  // A copy of the laptop cookies taken before two rotations
+  const stolen = await request.newContext({
+    baseURL,
+    ignoreHTTPSErrors: true,
+    storageState: await laptop.storageState(),
+  });
+  expect((await laptop.post('/api/refresh')).ok()).toBeTruthy();
+  expect((await laptop.post('/api/refresh')).ok()).toBeTruthy();
+
+  // Replaying the old refresh token kills the session for everyone holding it
+  const replayRes = await stolen.post('/api/refresh');
+  expect(replayRes.status()).toBe(401);
+  expect((await replayRes.json()).error).toBe('refresh_token_reused');
+  expect((await laptop.get('/api/me')).status()).toBe(401);
+  expect((await laptop.post('/api/refresh')).status()).toBe(401);
+
+  // The phone is unaffected until it logs out everywhere
+  expect((await phone.get('/api/me')).ok()).toBeTruthy();
+  expect((await phone.post('/api/logout/all')).ok()).toBeTruthy();
+  expect((await phone.get('/api/me')).status()).toBe(401);
+
+  for (const context of [anonymous, laptop, phone, stolen]) {
+    await context.dispose();
+  }
+});
@@ -0,0 +1,80 @@
+import { test, expect, Page } from '@playwright/test';
+import { createHmac } from 'crypto';
+
+test.use({
+  ignoreHTTPSErrors: true,
+});
+
+// Computes the TOTP code of a base32 secret, offset by a number of 30 second periods (RFC 6238)
+function totp(secret: string, periodOffset = 0): string {
+  const alphabet = 'ABCDEFGHIJKLMNOPQRSTUVWXYZ234567';
+  let bits = '';
+  for (const char of secret.replace(/=+$/, '').toUpperCase()) {
+    bits += alphabet.indexOf(char).toString(2).padStart(5, '0');
+  }
+  const key = Buffer.from(bits.match(/.{8}/g)!.map((byte) => parseInt(byte, 2)));
+  const counter = Buffer.alloc(8);
+  counter.writeBigUInt64BE(BigInt(Math.floor(Date.now() / 1000 / 30) + periodOffset));
+  const hash = createHmac('sha1', key).update(counter).digest();
+  const offset = hash[hash.length - 1] & 0x0f;
+  const value = (hash.readUInt32BE(offset) & 0x7fffffff) % 1000000;
+  return value.toString().padStart(6, '0');
+}
+
+async function login(page: Page, email: string, password: string) {
+  await page.goto('https://pwnthemall.local/login');
+  await page.getByRole('textbox', { name: /email/i }).fill(email);
+  await page.getByRole('textbox', { name: /password/i }).fill(password);
+  await page.getByRole('button', { name: /^login$/i }).click();
+}
+
+test('enable two-factor authentication and log in with a recovery code', async ({ page }) => {
+  const uid = Date.now();
+  const username = `totp${uid}`;
+  const email = `totp${uid}@pwnthemall.com`;
+  const password = 'TestPassword123';
+
+  // Register and log in
+  await page.goto('https://pwnthemall.local/');
+  await page.getByRole('button', { name: 'Accept' }).click();
+  await page.getByRole('link', { name: 'Register' }).click();
+  await page.getByRole('textbox', { name: 'Username' }).fill(username);
+  await page.getByRole('textbox', { name: 'Email' }).fill(email);
+  await page.getByRole('textbox', { name: 'Password' }).fill(password);
+  await page.getByRole('button', { name: 'Register' }).click();
+  await expect(page.getByText(/registration successful/i)).toBeVisible();
+  await login(page, email, password);
+  await expect(page.locator('[id="__next"]')).toContainText(username);
+
+  // Enrol from the security tab of the profile
+  await page.goto('https://pwnthemall.local/profile');
+  await page.getByRole('button', { name: /security/i }).click();
+  await page.getByRole('button', { name: /enable 2fa/i }).click();
+  await expect(page.getByRole('img', { name: /qr code/i })).toBeVisible();
+  const secret = (await page.locator('code').first().textContent())!.trim();
+  await page.getByLabel('Authentication code').fill(totp(secret));
+  await page.getByRole('button', { name: /enable 2fa/i }).click();
+  await expect(page.getByText('Two-factor authentication enabled')).toBeVisible();
+  const recoveryCodes = await page.locator('ul.font-mono li').allTextContents();
+  expect(recoveryCodes).toHaveLength(10);
+
+  // The password alone no longer logs in
+  await page.context().clearCookies();
+  await login(page, email, password);
+  await expect(page.getByText(/enter the code from your authenticator app/i)).toBeVisible();
+  await page.getByLabel(/authentication code or recovery code/i).fill(recoveryCodes[0]);
+  await page.getByRole('button', { name: /verify/i }).click();
+  await expect(page.locator('[id="__next"]')).toContainText(username);
+
+  // A recovery code only works once
+  await page.context().clearCookies();
+  await login(page, email, password);
+  await page.getByLabel(/authentication code or recovery code/i).fill(recoveryCodes[0]);
+  await page.getByRole('button', { name: /verify/i }).click();
+  await expect(page.getByText(/invalid or already used code/i)).toBeVisible();
+
+  // The next code of the app does
+  await page.getByLabel(/authentication code or recovery code/i).fill(totp(secret, 1));
+  await page.getByRole('button', { name: /verify/i }).click();
+  await expect(page.locator('[id="__next"]')).toContainText(username);
+});